@@ -0,0 +1,19 @@
+package main
+
+import (
+	"log"
+
+	"tarkib.uz/config"
+	"tarkib.uz/internal/app"
+)
+
+func main() {
+	// Configuration
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	// Run
+	app.RunWorker(cfg)
+}
@@ -9,13 +9,21 @@ import (
 type (
 	// Config -.
 	Config struct {
-		App    `yaml:"app"`
-		HTTP   `yaml:"http"`
-		Log    `yaml:"logger"`
-		PG     `yaml:"postgres"`
-		SMS    `yaml:"eskiz"`
-		Redis  `yaml:"redis"`
-		Casbin `yaml:"casbin"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		Log       `yaml:"logger"`
+		PG        `yaml:"postgres"`
+		SMS       `yaml:"eskiz"`
+		Redis     `yaml:"redis"`
+		Casbin    `yaml:"casbin"`
+		JWT       `yaml:"jwt"`
+		RMQ       `yaml:"rabbitmq"`
+		Outbox    `yaml:"outbox"`
+		RateLimit `yaml:"rate_limit"`
+		Challenge `yaml:"challenge"`
+		Audit     `yaml:"audit"`
+		Recipe    `yaml:"recipes"`
+		Pantry    `yaml:"pantry"`
 	}
 
 	// App -.
@@ -55,9 +63,110 @@ type (
 	Casbin struct {
 		ConfigFilePath     string `env-required:"true" yaml:"config_file_path"`
 		CSVFilePath        string `env-required:"true" yaml:"csv_file_path"`
-		SigningKey         string `env-required:"true" yaml:"signing_key"`
 		AccessTokenTimeOut int    `env-required:"true" yaml:"access_token_timeout"`
 	}
+
+	// JWT signs access tokens with the key SigningKeyID. To rotate, add the
+	// new key, switch SigningKeyID, and drop the old key once the tokens it
+	// signed have expired.
+	JWT struct {
+		Issuer       string   `yaml:"issuer"         env-default:"tarkib.uz"`
+		Audience     []string `yaml:"audience"`
+		Leeway       int      `yaml:"leeway"         env-default:"30"`
+		SigningKeyID string   `env-required:"true" yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+		Keys         []JWTKey `yaml:"keys"`
+	}
+
+	// JWTKey holds the Secret for HS256 or a PEM KeyFile for RS256 and
+	// EdDSA. A public key file makes a verify-only key.
+	JWTKey struct {
+		ID        string `yaml:"id"`
+		Algorithm string `yaml:"algorithm"`
+		Secret    string `yaml:"secret"`
+		KeyFile   string `yaml:"key_file"`
+	}
+
+	// RMQ -.
+	RMQ struct {
+		URL         string `env-required:"true"                    env:"RMQ_URL"`
+		Exchange    string `env-required:"true" yaml:"jobs_exchange"`
+		Queue       string `env-required:"true" yaml:"jobs_queue"`
+		MaxRetries  int    `yaml:"max_retries"  env-default:"5"`
+		RetryDelay  int    `yaml:"retry_delay"  env-default:"30"`
+		Concurrency int    `yaml:"concurrency"  env-default:"4"`
+		DedupTTL    int    `yaml:"dedup_ttl"    env-default:"604800"`
+	}
+
+	// Outbox -.
+	Outbox struct {
+		BatchSize    int `yaml:"batch_size"    env-default:"100"`
+		Lease        int `yaml:"lease"         env-default:"30"`
+		PollInterval int `yaml:"poll_interval" env-default:"1"`
+	}
+
+	// RateLimit -.
+	RateLimit struct {
+		Enabled bool            `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"true"`
+		Rules   []RateLimitRule `yaml:"rules"`
+		Lockout LoginLockout    `yaml:"login_lockout"`
+	}
+
+	// RateLimitRule allows Limit requests per Window seconds to Route, counted
+	// per Key: "ip", "phone" (phone_number in the JSON body) or "user".
+	RateLimitRule struct {
+		Route  string `yaml:"route"`
+		Key    string `yaml:"key"`
+		Limit  int    `yaml:"limit"`
+		Window int    `yaml:"window"`
+	}
+
+	// LoginLockout locks an account for BaseDuration seconds after Threshold
+	// failed logins, doubling with every further failure up to MaxDuration.
+	LoginLockout struct {
+		Threshold    int `yaml:"threshold"     env-default:"5"`
+		BaseDuration int `yaml:"base_duration" env-default:"60"`
+		MaxDuration  int `yaml:"max_duration"  env-default:"3600"`
+		ResetAfter   int `yaml:"reset_after"   env-default:"86400"`
+	}
+
+	// Challenge asks for a solved proof-of-work or captcha before an SMS is
+	// sent once one IP used more than PhonesPerIP numbers within PhonesWindow
+	// seconds.
+	Challenge struct {
+		Difficulty   int     `yaml:"difficulty"    env-default:"20"`
+		TTL          int     `yaml:"ttl"           env-default:"300"`
+		PhonesPerIP  int     `yaml:"phones_per_ip" env-default:"3"`
+		PhonesWindow int     `yaml:"phones_window" env-default:"86400"`
+		Captcha      Captcha `yaml:"captcha"`
+	}
+
+	// Audit keeps log entries for Retention days, 0 keeps them forever.
+	// Expired entries are purged every PurgeInterval seconds.
+	Audit struct {
+		Retention     int `yaml:"retention"      env:"AUDIT_RETENTION" env-default:"365"`
+		PurgeInterval int `yaml:"purge_interval" env-default:"3600"`
+	}
+
+	// Recipe publishes scheduled drafts every ScheduleInterval seconds.
+	Recipe struct {
+		ScheduleInterval int `yaml:"schedule_interval" env-default:"30"`
+	}
+
+	// Pantry reminds users of items expiring within ExpiryNotice days,
+	// checking every ReminderInterval seconds.
+	Pantry struct {
+		ExpiryNotice     int `yaml:"expiry_notice"     env-default:"2"`
+		ReminderInterval int `yaml:"reminder_interval" env-default:"3600"`
+	}
+
+	// Captcha provider: "" disables it, "siteverify" posts to VerifyURL and
+	// "stub" accepts StubToken.
+	Captcha struct {
+		Provider  string `yaml:"provider"   env:"CAPTCHA_PROVIDER"`
+		VerifyURL string `yaml:"verify_url" env:"CAPTCHA_VERIFY_URL"`
+		Secret    string `yaml:"secret"     env:"CAPTCHA_SECRET"`
+		StubToken string `yaml:"stub_token"`
+	}
 )
 
 // NewConfig returns app config.
@@ -2,11 +2,14 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/k0kubun/pp"
 	"github.com/minio/minio-go/v7"
@@ -14,13 +17,19 @@ import (
 
 	"tarkib.uz/config"
 	v1 "tarkib.uz/internal/controller/http/v1"
+	"tarkib.uz/internal/entity"
 	"tarkib.uz/internal/usecase"
 	"tarkib.uz/internal/usecase/repo"
 	"tarkib.uz/internal/usecase/webapi"
 	"tarkib.uz/pkg/httpserver"
 	"tarkib.uz/pkg/logger"
 	"tarkib.uz/pkg/postgres"
+	rmqjobs "tarkib.uz/pkg/rabbitmq/rmq_jobs"
+	"tarkib.uz/pkg/rabbitmq/rmq_jobs/publisher"
+	rmqrpc "tarkib.uz/pkg/rabbitmq/rmq_rpc"
+	"tarkib.uz/pkg/ratelimit"
 	"tarkib.uz/pkg/redis"
+	tokens "tarkib.uz/pkg/token"
 )
 
 // Run creates objects via constructors.
@@ -40,29 +49,147 @@ func Run(cfg *config.Config) {
 		l.Fatal(fmt.Errorf("app - Run - redis.New: %w", err))
 	}
 
-	endpoint := os.Getenv("SERVER_IP")
-	accessKeyID := "nodirbek"
-	secretAccessKey := "nodirbek"
-	minioClient, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: false,
-	})
+	minioClient, err := newMinioClient()
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - minio.New"))
 	}
 
+	// RabbitMQ job publisher
+	jobPublisher, err := publisher.New(jobsConfig(cfg))
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - publisher.New: %w", err))
+	}
+
+	enforcer, err := casbin.NewEnforcer(cfg.Casbin.ConfigFilePath, cfg.Casbin.CSVFilePath)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - casbin.NewEnforcer: %w", err))
+	}
+
+	tokenManager, err := newTokenManager(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newTokenManager: %w", err))
+	}
+
 	// Use case
+	auditUseCase := usecase.NewAuditUseCase(
+		repo.NewAuditRepo(pg),
+		l,
+		time.Duration(cfg.Audit.Retention)*24*time.Hour,
+		time.Duration(cfg.Audit.PurgeInterval)*time.Second,
+	)
+	botGuardUseCase := usecase.NewBotGuardUseCase(cfg, RedisClient, newCaptchaVerifier(cfg))
+	sessionUseCase := usecase.NewSessionUseCase(cfg, RedisClient)
 	authUseCase := usecase.NewAuthUseCase(
 		repo.NewAuthRepo(pg),
-		webapi.NewAuthWebAPI(cfg),
+		jobPublisher,
+		botGuardUseCase,
+		sessionUseCase,
+		tokenManager,
+		auditUseCase,
 		cfg,
 		RedisClient,
 		minioClient,
 	)
+	adminRepo := repo.NewAdminRepo(pg)
+	adminUseCase := usecase.NewAdminUseCase(adminRepo, sessionUseCase, auditUseCase)
+	recipeRepo := repo.NewRecipeRepo(pg)
+	catalogRepo := repo.NewCatalogRepo(pg)
+	nutritionUseCase := usecase.NewNutritionUseCase(catalogRepo)
+	preferenceRepo := repo.NewPreferenceRepo(pg)
+	dietaryUseCase := usecase.NewDietaryUseCase(preferenceRepo)
+	recipeUseCase := usecase.NewRecipeUseCase(recipeRepo, catalogRepo, preferenceRepo)
+	realtimeUseCase := usecase.NewRealtimeUseCase(RedisClient, recipeUseCase)
+	notificationUseCase := usecase.NewNotificationUseCase(
+		repo.NewNotificationRepo(pg),
+		realtimeUseCase,
+		RedisClient,
+	)
+	recipeImportUseCase := usecase.NewRecipeImportUseCase(recipeUseCase, webapi.NewRecipePageWebAPI())
+	cookUseCase := usecase.NewCookUseCase(recipeRepo, repo.NewCookRepo(pg), realtimeUseCase, RedisClient)
+	shoppingUseCase := usecase.NewShoppingUseCase(repo.NewShoppingRepo(pg), recipeRepo, catalogRepo, realtimeUseCase)
+	mealPlanUseCase := usecase.NewMealPlanUseCase(repo.NewMealPlanRepo(pg), recipeRepo, shoppingUseCase)
+	pantryRepo := repo.NewPantryRepo(pg)
+	pantryUseCase := usecase.NewPantryUseCase(pantryRepo, recipeRepo, catalogRepo)
+	moderationUseCase := usecase.NewModerationUseCase(
+		repo.NewReportRepo(pg),
+		adminUseCase,
+		adminRepo,
+		notificationUseCase,
+		auditUseCase,
+		map[string]usecase.ModerationTarget{
+			entity.ReportTargetRecipe:  usecase.NewRecipeTarget(recipeRepo),
+			entity.ReportTargetProfile: usecase.NewProfileTarget(adminRepo),
+		},
+	)
+
+	// Outbox relay
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+
+	outboxRelay := usecase.NewOutboxRelay(
+		repo.NewOutboxRepo(pg),
+		jobPublisher,
+		l,
+		cfg.Outbox.BatchSize,
+		time.Duration(cfg.Outbox.Lease)*time.Second,
+		time.Duration(cfg.Outbox.PollInterval)*time.Second,
+	)
+	go outboxRelay.Run(relayCtx)
+
+	// Audit log retention
+	go auditUseCase.Run(relayCtx)
+
+	// Scheduled recipe publishing
+	recipeScheduler := usecase.NewRecipeScheduler(
+		recipeRepo,
+		l,
+		time.Duration(cfg.Recipe.ScheduleInterval)*time.Second,
+	)
+	go recipeScheduler.Run(relayCtx)
+
+	// Analysis of recipes saved before nutrition and dietary flags
+	go func() {
+		if err := recipeUseCase.AnalyzeMissing(relayCtx); err != nil {
+			l.Error(fmt.Errorf("app - Run - recipeUseCase.AnalyzeMissing: %w", err))
+		}
+	}()
+
+	// Pantry expiry reminders
+	pantryReminder := usecase.NewPantryReminder(
+		pantryRepo,
+		notificationUseCase,
+		l,
+		time.Duration(cfg.Pantry.ReminderInterval)*time.Second,
+		cfg.Pantry.ExpiryNotice,
+	)
+	go pantryReminder.Run(relayCtx)
 
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, l, authUseCase)
+	v1.NewRouter(handler, v1.Deps{
+		Logger:       l,
+		Config:       cfg,
+		Enforcer:     enforcer,
+		Limiter:      ratelimit.New(RedisClient),
+		Tokens:       tokenManager,
+		Jobs:         jobPublisher,
+		Auth:         authUseCase,
+		BotGuard:     botGuardUseCase,
+		Sessions:     sessionUseCase,
+		Admin:        adminUseCase,
+		Audit:        auditUseCase,
+		Moderation:   moderationUseCase,
+		Recipe:       recipeUseCase,
+		RecipeImport: recipeImportUseCase,
+		Cook:         cookUseCase,
+		Shopping:     shoppingUseCase,
+		MealPlan:     mealPlanUseCase,
+		Pantry:       pantryUseCase,
+		Nutrition:    nutritionUseCase,
+		Dietary:      dietaryUseCase,
+		Notification: notificationUseCase,
+		Realtime:     realtimeUseCase,
+	})
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
 	// Waiting signal
@@ -81,4 +208,80 @@ func Run(cfg *config.Config) {
 	if err != nil {
 		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
+
+	stopRelay()
+
+	err = jobPublisher.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - jobPublisher.Shutdown: %w", err))
+	}
+}
+
+func newMinioClient() (*minio.Client, error) {
+	endpoint := os.Getenv("SERVER_IP")
+	accessKeyID := "nodirbek"
+	secretAccessKey := "nodirbek"
+
+	return minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: false,
+	})
+}
+
+// newCaptchaVerifier returns nil when no captcha provider is configured;
+// proof-of-work is then the only accepted challenge.
+func newCaptchaVerifier(cfg *config.Config) usecase.ChallengeVerifier {
+	switch cfg.Challenge.Captcha.Provider {
+	case "siteverify":
+		return webapi.NewCaptchaWebAPI(cfg.Challenge.Captcha.VerifyURL, cfg.Challenge.Captcha.Secret)
+	case "stub":
+		return webapi.NewStubCaptcha(cfg.Challenge.Captcha.StubToken)
+	default:
+		return nil
+	}
+}
+
+// newTokenManager loads the configured signing keys. Key files hold PEM keys
+// for RS256 and EdDSA; HS256 keys take the secret inline.
+func newTokenManager(cfg *config.Config) (*tokens.Manager, error) {
+	keys := make([]tokens.Key, 0, len(cfg.JWT.Keys))
+
+	for _, k := range cfg.JWT.Keys {
+		material := []byte(k.Secret)
+
+		if k.KeyFile != "" {
+			var err error
+
+			material, err = os.ReadFile(k.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		key, err := tokens.NewKey(k.ID, k.Algorithm, material)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return tokens.New(keys, cfg.JWT.SigningKeyID,
+		tokens.Issuer(cfg.JWT.Issuer),
+		tokens.Audience(cfg.JWT.Audience...),
+		tokens.TTL(time.Duration(cfg.Casbin.AccessTokenTimeOut)*time.Second),
+		tokens.Leeway(time.Duration(cfg.JWT.Leeway)*time.Second),
+	)
+}
+
+func jobsConfig(cfg *config.Config) rmqjobs.Config {
+	return rmqjobs.Config{
+		Config: rmqrpc.Config{
+			URL: cfg.RMQ.URL,
+		},
+		Exchange:   cfg.RMQ.Exchange,
+		Queue:      cfg.RMQ.Queue,
+		RetryDelay: time.Duration(cfg.RMQ.RetryDelay) * time.Second,
+		MaxRetries: cfg.RMQ.MaxRetries,
+	}
 }
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"tarkib.uz/config"
+	amqpjobs "tarkib.uz/internal/controller/amqp_jobs"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/internal/usecase/webapi"
+	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/rabbitmq/rmq_jobs/worker"
+	"tarkib.uz/pkg/redis"
+)
+
+// RunWorker consumes background jobs until interrupted.
+func RunWorker(cfg *config.Config) {
+	l := logger.New(cfg.Log.Level)
+
+	minioClient, err := newMinioClient()
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - RunWorker - minio.New: %w", err))
+	}
+
+	RedisClient, err := redis.NewRedisDB(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - RunWorker - redis.New: %w", err))
+	}
+
+	// Use case
+	jobUseCase := usecase.NewJobUseCase(
+		webapi.NewAuthWebAPI(cfg),
+		minioClient,
+	)
+
+	// RabbitMQ worker
+	jobWorker, err := worker.New(
+		jobsConfig(cfg),
+		amqpjobs.NewRouter(jobUseCase),
+		l,
+		worker.Concurrency(cfg.RMQ.Concurrency),
+		worker.Dedup(redis.NewDoneStore(RedisClient, time.Duration(cfg.RMQ.DedupTTL)*time.Second)),
+	)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - RunWorker - worker.New: %w", err))
+	}
+
+	// Waiting signal
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case s := <-interrupt:
+		l.Info("app - RunWorker - signal: " + s.String())
+	case err = <-jobWorker.Notify():
+		l.Error(fmt.Errorf("app - RunWorker - jobWorker.Notify: %w", err))
+	}
+
+	// Shutdown
+	err = jobWorker.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - RunWorker - jobWorker.Shutdown: %w", err))
+	}
+}
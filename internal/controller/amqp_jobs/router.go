@@ -0,0 +1,61 @@
+// Package amqpjobs maps background job types to use case calls.
+package amqpjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/rabbitmq/rmq_jobs/worker"
+)
+
+// NewRouter -.
+func NewRouter(j usecase.Jobs) map[string]worker.Handler {
+	return map[string]worker.Handler{
+		entity.JobSendSMS: func(ctx context.Context, d *amqp.Delivery) error {
+			var job entity.SendSMSJob
+			if err := decode(d, &job); err != nil {
+				return err
+			}
+
+			return j.SendSMS(ctx, job)
+		},
+		entity.JobRenderAvatar: func(ctx context.Context, d *amqp.Delivery) error {
+			var job entity.RenderAvatarJob
+			if err := decode(d, &job); err != nil {
+				return err
+			}
+
+			return j.RenderAvatar(ctx, job)
+		},
+		entity.JobImageVariants: func(ctx context.Context, d *amqp.Delivery) error {
+			var job entity.ImageVariantsJob
+			if err := decode(d, &job); err != nil {
+				return err
+			}
+
+			return j.GenerateImageVariants(ctx, job)
+		},
+		entity.EventUserCreated: func(ctx context.Context, d *amqp.Delivery) error {
+			var event entity.UserCreatedEvent
+			if err := decode(d, &event); err != nil {
+				return err
+			}
+
+			return j.WelcomeUser(ctx, event)
+		},
+	}
+}
+
+// decode fails permanently: a malformed payload won't get better on retry.
+func decode(d *amqp.Delivery, v interface{}) error {
+	if err := json.Unmarshal(d.Body, v); err != nil {
+		return fmt.Errorf("amqp_jobs - decode %s: %w: %v", d.Type, worker.ErrPermanent, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AdminUser is a user as seen in the admin panel.
+type AdminUser struct {
+	ID          string     `json:"id"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	PhoneNumber string     `json:"phone_number"`
+	NickName    string     `json:"nickname"`
+	Avatar      string     `json:"avatar"`
+	Role        string     `json:"role"`
+	BanReason   string     `json:"ban_reason,omitempty"`
+	BannedAt    *time.Time `json:"banned_at,omitempty"`
+	BannedUntil *time.Time `json:"banned_until,omitempty"`
+}
+
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	// Until is empty for a permanent ban.
+	Until *time.Time `json:"until"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+// BannedResponse is returned by login for banned accounts.
+type BannedResponse struct {
+	Error  string     `json:"error"`
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"`
+}
@@ -3,9 +3,10 @@ package models
 import "tarkib.uz/internal/entity"
 
 type LoginRequest struct {
-	NickName    string `json:"nickname"`
-	PhoneNumber string `json:"phone_number"`
-	Password    string `json:"password"`
+	NickName    string `json:"nickname"     binding:"required_without=PhoneNumber,max=30"`
+	PhoneNumber string `json:"phone_number" binding:"required_without=NickName,omitempty,phone"`
+	Password    string `json:"password"     binding:"required,max=72"`
+	DeviceName  string `json:"device_name"  binding:"max=100"`
 }
 
 type LoginUser struct {
@@ -23,34 +24,45 @@ type LoginResponse struct {
 	User        LoginUser `json:"user"`
 }
 
+// ChallengeAnswer is required once the API answers 403 "challenge required":
+// either a solved GET /auth/challenge or a captcha token.
+type ChallengeAnswer struct {
+	ChallengeID  string `json:"challenge_id"`
+	Nonce        string `json:"nonce"`
+	CaptchaToken string `json:"captcha_token"`
+}
+
 type RegisterUser struct {
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	NickName    string `json:"nickname"`
-	PhoneNumber string `json:"phone_number"`
-	Password    string `json:"password"`
-	Avatar      string `json:"avatar"`
+	FirstName   string `json:"first_name"   binding:"required,max=50"`
+	LastName    string `json:"last_name"    binding:"max=50"`
+	NickName    string `json:"nickname"     binding:"required,min=3,max=30,nickname"`
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	Password    string `json:"password"     binding:"required,min=8,max=72,password"`
+	Avatar      string `json:"avatar"       binding:"omitempty,base64image"`
+	ChallengeAnswer
 }
 
 type VerifyUser struct {
-	PhoneNumber string `json:"phone_number"`
-	Code        string `json:"code"`
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	Code        string `json:"code"         binding:"required,len=6,numeric"`
+	DeviceName  string `json:"device_name"  binding:"max=100"`
 }
 
 type VerifyUserResponse struct {
 	User *entity.User `json:"user"`
 }
 
-type ForgotPasswordRequest struct {
-	PhoneNumber string `json:"phone_number"`
+type PasswordForgotRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	ChallengeAnswer
 }
 
-type ResetPasswordRequest struct {
-	PhoneNumber string `json:"phone_number"`
-	Code        string `json:"code"`
-	NewPassword string `json:"new_password"`
+type PasswordVerifyRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	Code        string `json:"code"         binding:"required,len=6,numeric"`
 }
 
-type ResetPasswordResponse struct {
-	Message string `json:"message"`
+type PasswordResetRequest struct {
+	ResetToken  string `json:"reset_token"  binding:"required,len=64,hexadecimal"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=72,password"`
 }
@@ -0,0 +1,16 @@
+package models
+
+// CookProgressRequest changes a cook session. Omitted fields are left as
+// they are. Steps are step numbers; ingredients are ingredient IDs.
+type CookProgressRequest struct {
+	CurrentStep *int     `json:"current_step" binding:"omitempty,min=0"`
+	StartTimers []int    `json:"start_timers" binding:"max=50"`
+	StopTimers  []int    `json:"stop_timers"  binding:"max=50"`
+	Check       []string `json:"check"        binding:"max=100,dive,max=36"`
+	Uncheck     []string `json:"uncheck"      binding:"max=100,dive,max=36"`
+}
+
+type CompleteCookRequest struct {
+	// CookedIt records the cook in the recipe's stats.
+	CookedIt bool `json:"cooked_it"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// MealPlanRequest creates a plan. Templates are a week long and take no
+// start date; other plans need one.
+type MealPlanRequest struct {
+	Name      string `json:"name"       binding:"max=100"`
+	Template  bool   `json:"template"`
+	StartDate string `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	Days      int    `json:"days"       binding:"min=0,max=28"`
+}
+
+// MealSlotRequest plans a recipe for a meal. Servings defaults to the
+// recipe's own.
+type MealSlotRequest struct {
+	RecipeID string `json:"recipe_id" binding:"required,uuid"`
+	Servings int    `json:"servings"  binding:"min=0,max=100"`
+}
+
+// CopyMealPlanRequest copies a plan to a start date, or to a template when
+// StartDate is empty.
+type CopyMealPlanRequest struct {
+	Name      string `json:"name"       binding:"max=100"`
+	StartDate string `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type MealPlanShoppingRequest struct {
+	From string `json:"from" binding:"required,datetime=2006-01-02"`
+	To   string `json:"to"   binding:"required,datetime=2006-01-02"`
+	Name string `json:"name" binding:"max=100"`
+}
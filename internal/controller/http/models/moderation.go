@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type ReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=recipe comment profile"`
+	TargetID   string `json:"target_id"   binding:"required,max=64"`
+	Reason     string `json:"reason"      binding:"required,max=100"`
+	Details    string `json:"details"     binding:"max=2000"`
+}
+
+// ModerationActionRequest closes a report. BanUntil is used by "ban" and
+// makes a permanent ban when empty.
+type ModerationActionRequest struct {
+	Action   string     `json:"action"    binding:"required,oneof=hide delete warn ban"`
+	Note     string     `json:"note"      binding:"max=500"`
+	BanUntil *time.Time `json:"ban_until"`
+}
+
+type DismissReportRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
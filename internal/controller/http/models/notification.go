@@ -0,0 +1,9 @@
+package models
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
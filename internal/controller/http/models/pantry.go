@@ -0,0 +1,10 @@
+package models
+
+// PantryItemRequest names the ingredient, links it to the catalog, or both.
+type PantryItemRequest struct {
+	Name      string  `json:"name"       binding:"required_without=CatalogID,max=100"`
+	CatalogID string  `json:"catalog_id" binding:"omitempty,uuid"`
+	Quantity  float64 `json:"quantity"   binding:"min=0"`
+	Unit      string  `json:"unit"       binding:"max=20"`
+	ExpiresOn string  `json:"expires_on" binding:"omitempty,datetime=2006-01-02"`
+}
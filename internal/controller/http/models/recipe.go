@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// IngredientRequest is a line of the ingredient list. ID is optional; set
+// it to refer to a new line from a step in the same request.
+type IngredientRequest struct {
+	ID        string  `json:"id"         binding:"max=36"`
+	Name      string  `json:"name"       binding:"required,max=100"`
+	CatalogID string  `json:"catalog_id" binding:"omitempty,uuid"`
+	Quantity  float64 `json:"quantity"   binding:"min=0"`
+	Unit      string  `json:"unit"       binding:"max=20"`
+	Note      string  `json:"note"       binding:"max=200"`
+}
+
+// SectionRequest is a block of the recipe body: text, an image or video
+// URL, or a step. Steps are numbered by their order.
+type SectionRequest struct {
+	Type    string       `json:"type"    binding:"required,oneof=text image video step"`
+	Content string       `json:"content" binding:"max=5000"`
+	URL     string       `json:"url"     binding:"omitempty,url,max=2000"`
+	Step    *StepRequest `json:"step"`
+}
+
+// StepRequest holds the details of a step. Durations are in seconds.
+type StepRequest struct {
+	Duration    int                    `json:"duration"    binding:"min=0,max=86400"`
+	Timer       *TimerRequest          `json:"timer"`
+	Temperature *TemperatureRequest    `json:"temperature"`
+	Media       *MediaRequest          `json:"media"`
+	Ingredients []IngredientRefRequest `json:"ingredients" binding:"max=50,dive"`
+}
+
+type TimerRequest struct {
+	Duration int    `json:"duration" binding:"required,min=1,max=86400"`
+	Label    string `json:"label"    binding:"max=50"`
+}
+
+type TemperatureRequest struct {
+	Value float64 `json:"value" binding:"min=0,max=600"`
+	Unit  string  `json:"unit"  binding:"required,oneof=C F"`
+}
+
+type MediaRequest struct {
+	Type string `json:"type" binding:"required,oneof=photo video"`
+	URL  string `json:"url"  binding:"required,url,max=2000"`
+}
+
+// IngredientRefRequest points at an ingredient line by its ID. Quantity and
+// Unit are set when the step uses only part of the line.
+type IngredientRefRequest struct {
+	IngredientID string  `json:"ingredient_id" binding:"required,max=36"`
+	Quantity     float64 `json:"quantity"      binding:"min=0"`
+	Unit         string  `json:"unit"          binding:"max=20"`
+}
+
+// RecipeRequest creates a draft. Every field may be left empty until the
+// recipe is published.
+type RecipeRequest struct {
+	Title       string              `json:"title"       binding:"max=200"`
+	Description string              `json:"description" binding:"max=5000"`
+	Servings    int                 `json:"servings"    binding:"min=0,max=100"`
+	Ingredients []IngredientRequest `json:"ingredients" binding:"max=100,dive"`
+	Sections    []SectionRequest    `json:"sections"    binding:"max=100,dive"`
+}
+
+// UpdateRecipeRequest saves a recipe, explicitly or by autosave. Version is
+// the version the client last loaded or saved.
+type UpdateRecipeRequest struct {
+	Version     int                 `json:"version"     binding:"required,min=1"`
+	Title       string              `json:"title"       binding:"max=200"`
+	Description string              `json:"description" binding:"max=5000"`
+	Servings    int                 `json:"servings"    binding:"min=0,max=100"`
+	Ingredients []IngredientRequest `json:"ingredients" binding:"max=100,dive"`
+	Sections    []SectionRequest    `json:"sections"    binding:"max=100,dive"`
+}
+
+// RecipeStatusRequest changes the state. PublishAt in the future schedules
+// publishing.
+type RecipeStatusRequest struct {
+	Status    string     `json:"status"     binding:"required,oneof=draft published unlisted archived"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type DietaryOverridesRequest struct {
+	Overrides []DietaryOverrideRequest `json:"overrides" binding:"max=20,dive"`
+}
+
+// DietaryOverrideRequest declares an allergen present or absent, or a diet
+// met or not. Present is false when omitted.
+type DietaryOverrideRequest struct {
+	Kind    string `json:"kind"    binding:"required,oneof=allergen diet"`
+	Value   string `json:"value"   binding:"required,max=20"`
+	Present bool   `json:"present"`
+	Reason  string `json:"reason"  binding:"required,min=3,max=300"`
+}
+
+type DietaryPreferencesRequest struct {
+	Allergens []string `json:"allergens" binding:"max=10,dive,oneof=nuts gluten dairy eggs sesame shellfish"`
+	Diets     []string `json:"diets"     binding:"max=5,dive,oneof=halal vegetarian vegan"`
+}
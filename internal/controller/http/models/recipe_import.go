@@ -0,0 +1,12 @@
+package models
+
+// ImportURLRequest points at a page with a schema.org Recipe on it.
+type ImportURLRequest struct {
+	URL string `json:"url" binding:"required,url,max=2000"`
+}
+
+// ImportDocumentRequest carries an HTML page or a Markdown file.
+type ImportDocumentRequest struct {
+	Format  string `json:"format"  binding:"required,oneof=html markdown"`
+	Content string `json:"content" binding:"required,max=2097152"`
+}
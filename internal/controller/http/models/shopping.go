@@ -0,0 +1,20 @@
+package models
+
+type ShoppingListRequest struct {
+	Name string `json:"name" binding:"max=100"`
+}
+
+// ShoppingRecipeRequest adds a recipe to a list. Servings defaults to the
+// recipe's own.
+type ShoppingRecipeRequest struct {
+	RecipeID string `json:"recipe_id" binding:"required,uuid"`
+	Servings int    `json:"servings"  binding:"min=0,max=100"`
+}
+
+type ShoppingItemRequest struct {
+	Checked bool `json:"checked"`
+}
+
+type JoinShoppingListRequest struct {
+	Token string `json:"token" binding:"required,max=64"`
+}
@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type adminRoutes struct {
+	a usecase.Admin
+	l logger.Interface
+}
+
+func newAdminRoutes(handler *gin.RouterGroup, a usecase.Admin, l logger.Interface) {
+	r := &adminRoutes{a, l}
+
+	h := handler.Group("/admin/users")
+	{
+		h.GET("", r.searchUsers)
+		h.POST("/:id/ban", r.ban)
+		h.DELETE("/:id/ban", r.unban)
+		h.PUT("/:id/role", r.setRole)
+		h.POST("/:id/logout", r.forceLogout)
+	}
+}
+
+// @Summary     Search users
+// @Description Finds users by part of the phone number or nickname prefix.
+// @ID          admin-users-search
+// @Tags        admin
+// @Produce     json
+// @Param       phone    query string false "Phone number digits"
+// @Param       nickname query string false "Nickname prefix"
+// @Param       limit    query int    false "Page size, 20 by default and at most 100"
+// @Param       offset   query int    false "Offset"
+// @Success     200 {array}  models.AdminUser
+// @Failure     500 {object} response
+// @Router      /admin/users [get]
+func (r *adminRoutes) searchUsers(c *gin.Context) {
+	users, err := r.a.SearchUsers(c.Request.Context(), entity.UserFilter{
+		PhoneNumber: c.Query("phone"),
+		NickName:    c.Query("nickname"),
+		Limit:       cast.ToUint64(c.Query("limit")),
+		Offset:      cast.ToUint64(c.Query("offset")),
+	})
+	if err != nil {
+		r.l.Error(err, "http - v1 - admin - searchUsers")
+		errorResponse(c, http.StatusInternalServerError, "admin service problems")
+
+		return
+	}
+
+	response := make([]models.AdminUser, 0, len(users))
+	for i := range users {
+		response = append(response, adminUser(&users[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary     Ban user
+// @Description Bans the user until the given time, or for good without one, and signs them out.
+// @ID          admin-users-ban
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                true "User ID"
+// @Param       request body models.BanUserRequest true "Ban"
+// @Success     200 {object} models.MessageResponse
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/users/{id}/ban [post]
+func (r *adminRoutes) ban(c *gin.Context) {
+	var request models.BanUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	err := r.a.BanUser(c.Request.Context(), currentUserID(c), c.Param("id"), entity.UserBan{
+		Reason: request.Reason,
+		Until:  request.Until,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "ban")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "User banned",
+	})
+}
+
+// @Summary     Unban user
+// @ID          admin-users-unban
+// @Tags        admin
+// @Produce     json
+// @Param       id path string true "User ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/users/{id}/ban [delete]
+func (r *adminRoutes) unban(c *gin.Context) {
+	if err := r.a.UnbanUser(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "unban")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "User unbanned",
+	})
+}
+
+// @Summary     Assign role
+// @Description Changes the user's role. The user is signed out and gets the role on next login.
+// @ID          admin-users-role
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                true "User ID"
+// @Param       request body models.SetRoleRequest true "Role"
+// @Success     200 {object} models.MessageResponse
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/users/{id}/role [put]
+func (r *adminRoutes) setRole(c *gin.Context) {
+	var request models.SetRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	if err := r.a.SetRole(c.Request.Context(), currentUserID(c), c.Param("id"), request.Role); err != nil {
+		r.errorResponse(c, err, "setRole")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Role updated",
+	})
+}
+
+// @Summary     Force logout
+// @Description Revokes every session of the user.
+// @ID          admin-users-logout
+// @Tags        admin
+// @Produce     json
+// @Param       id path string true "User ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/users/{id}/logout [post]
+func (r *adminRoutes) forceLogout(c *gin.Context) {
+	if err := r.a.ForceLogout(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "forceLogout")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "User signed out",
+	})
+}
+
+func (r *adminRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, entity.ErrInvalidRole),
+		errors.Is(err, entity.ErrInvalidBanExpiry),
+		errors.Is(err, entity.ErrSelfAction):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - admin - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "admin service problems")
+	}
+}
+
+func adminUser(u *entity.User) models.AdminUser {
+	user := models.AdminUser{
+		ID:          u.ID,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		PhoneNumber: u.PhoneNumber,
+		NickName:    u.NickName,
+		Avatar:      u.Avatar,
+		Role:        u.Role,
+	}
+
+	if u.Ban != nil {
+		user.BanReason = u.Ban.Reason
+		user.BannedAt = &u.Ban.BannedAt
+		user.BannedUntil = u.Ban.Until
+	}
+
+	return user
+}
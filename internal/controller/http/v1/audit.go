@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type auditRoutes struct {
+	a usecase.Audit
+	l logger.Interface
+}
+
+func newAuditRoutes(handler *gin.RouterGroup, a usecase.Audit, l logger.Interface) {
+	r := &auditRoutes{a, l}
+
+	h := handler.Group("/admin/audit")
+	{
+		h.GET("", r.list)
+	}
+}
+
+// @Summary     Audit log
+// @Description Security-sensitive and admin actions, newest first.
+// @ID          admin-audit-list
+// @Tags        admin
+// @Produce     json
+// @Param       action    query string false "Action, e.g. user.banned"
+// @Param       actor_id  query string false "Who performed the action"
+// @Param       target_id query string false "What the action was performed on"
+// @Param       from      query string false "RFC 3339 time, inclusive"
+// @Param       to        query string false "RFC 3339 time, exclusive"
+// @Param       limit     query int    false "Page size, 50 by default and at most 500"
+// @Param       offset    query int    false "Offset"
+// @Success     200 {object} entity.AuditList
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/audit [get]
+func (r *auditRoutes) list(c *gin.Context) {
+	filter := entity.AuditFilter{
+		Action:   c.Query("action"),
+		ActorID:  c.Query("actor_id"),
+		TargetID: c.Query("target_id"),
+		Limit:    cast.ToUint64(c.Query("limit")),
+		Offset:   cast.ToUint64(c.Query("offset")),
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			errorResponse(c, http.StatusBadRequest, "from must be an RFC 3339 time")
+			return
+		}
+	}
+
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			errorResponse(c, http.StatusBadRequest, "to must be an RFC 3339 time")
+			return
+		}
+	}
+
+	list, err := r.a.List(c.Request.Context(), filter)
+	if err != nil {
+		r.l.Error(err, "http - v1 - audit - list")
+		errorResponse(c, http.StatusInternalServerError, "admin service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
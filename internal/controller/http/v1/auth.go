@@ -1,11 +1,13 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/controller/middleware"
 	"tarkib.uz/internal/entity"
 	"tarkib.uz/internal/usecase"
 	"tarkib.uz/pkg/logger"
@@ -13,19 +15,71 @@ import (
 
 type authRoutes struct {
 	t usecase.Auth
+	g usecase.BotGuard
 	l logger.Interface
 }
 
-func newAuthRoutes(handler *gin.RouterGroup, t usecase.Auth, l logger.Interface) {
-	r := &authRoutes{t, l}
+func newAuthRoutes(handler *gin.RouterGroup, t usecase.Auth, g usecase.BotGuard, l logger.Interface) {
+	r := &authRoutes{t, g, l}
 
 	h := handler.Group("/auth")
 	{
+		h.GET("/challenge", r.challenge)
 		h.POST("/register", r.register)
 		h.POST("/verify", r.verify)
-		h.POST("/forgot", r.forgotPassword)
-		h.POST("/reset", r.resetPassword)
 		h.POST("/login", r.login)
+		h.POST("/password/forgot", r.forgotPassword)
+		h.POST("/password/verify", r.verifyPasswordReset)
+		h.POST("/password/reset", r.resetPassword)
+	}
+}
+
+// @Summary     Challenge
+// @Description Issues a proof-of-work challenge. Find a nonce so that sha256(seed + ":" + nonce)
+// @Description starts with difficulty zero bits and send challenge_id and nonce with the request
+// @Description that answered 403 "challenge required".
+// @ID          auth-challenge
+// @Tags  	    auth
+// @Produce     json
+// @Success     200 {object} entity.Challenge
+// @Failure     500 {object} response
+// @Router      /auth/challenge [get]
+func (r *authRoutes) challenge(c *gin.Context) {
+	challenge, err := r.g.IssueChallenge(c.Request.Context())
+	if err != nil {
+		r.l.Error(err, "http - v1 - challenge")
+		errorResponse(c, http.StatusInternalServerError, "auth service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// challengeSolution collects what the bot guard needs from the request.
+func challengeSolution(c *gin.Context, answer models.ChallengeAnswer) entity.ChallengeSolution {
+	return entity.ChallengeSolution{
+		ChallengeID:  answer.ChallengeID,
+		Nonce:        answer.Nonce,
+		CaptchaToken: answer.CaptchaToken,
+		ClientIP:     c.ClientIP(),
+	}
+}
+
+// _maxUserAgent bounds the user agent kept on a session.
+const _maxUserAgent = 256
+
+// clientInfo describes the device a login comes from.
+func clientInfo(c *gin.Context, deviceName string) entity.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > _maxUserAgent {
+		userAgent = userAgent[:_maxUserAgent]
+	}
+
+	return entity.ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  userAgent,
+		IP:         c.ClientIP(),
 	}
 }
 
@@ -38,18 +92,18 @@ func newAuthRoutes(handler *gin.RouterGroup, t usecase.Auth, l logger.Interface)
 // @Param       request body models.RegisterUser true "User credentials"
 // @Success     200 {object} models.RegisterUser
 // @Failure     400 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
 // @Router      /auth/register [post]
 func (r *authRoutes) register(c *gin.Context) {
 	var request models.RegisterUser
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - register")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		bindErrorResponse(c, err)
 
 		return
 	}
 
-
 	err := r.t.Register(
 		c.Request.Context(),
 		&entity.User{
@@ -60,12 +114,19 @@ func (r *authRoutes) register(c *gin.Context) {
 			Password:    request.Password,
 			Avatar:      request.Avatar,
 		},
+		challengeSolution(c, request.ChallengeAnswer),
 	)
 	if err != nil {
-		if err.Error() == "this nickname is already taken" {
+		if errors.Is(err, entity.ErrInvalidPhoneNumber) {
+			r.l.Error(err, "http - v1 - register")
+			errorResponse(c, http.StatusBadRequest, "Invalid phone number")
+		} else if errors.Is(err, entity.ErrChallengeRequired) {
+			r.l.Error(err, "http - v1 - register")
+			errorResponse(c, http.StatusForbidden, "challenge required")
+		} else if errors.Is(err, entity.ErrNicknameTaken) {
 			r.l.Error(err, "http - v1 - register")
 			errorResponse(c, http.StatusBadRequest, "Sorry, this nickname is already taken")
-		} else if err.Error() == "user with this phone number already registered" {
+		} else if errors.Is(err, entity.ErrPhoneTaken) {
 			r.l.Error(err, "http - v1 - register")
 			errorResponse(c, http.StatusBadRequest, "Sorry, user with this phone number is already registered")
 		} else {
@@ -89,13 +150,14 @@ func (r *authRoutes) register(c *gin.Context) {
 // @Param       request body models.VerifyUser true "One time code and phone number"
 // @Success     200 {object} models.VerifyUserResponse
 // @Failure     400 {object} response
+// @Failure     409 {object} response
 // @Failure     500 {object} response
 // @Router      /auth/verify [post]
 func (r *authRoutes) verify(c *gin.Context) {
 	var request models.VerifyUser
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - verify")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		bindErrorResponse(c, err)
 
 		return
 	}
@@ -103,105 +165,156 @@ func (r *authRoutes) verify(c *gin.Context) {
 	user, err := r.t.Verify(c.Request.Context(), entity.VerifyUser{
 		PhoneNumber: request.PhoneNumber,
 		Code:        request.Code,
+		Client:      clientInfo(c, request.DeviceName),
 	})
 	if err != nil {
-		if err.Error() == "verification code expired" {
-			r.l.Error("http - v1 - register")
+		switch {
+		case errors.Is(err, entity.ErrInvalidPhoneNumber):
+			r.l.Error(err, "http - v1 - verify")
+			errorResponse(c, http.StatusBadRequest, "Invalid phone number")
+		case errors.Is(err, entity.ErrRegistrationNotFound):
+			r.l.Error(err, "http - v1 - verify")
 			errorResponse(c, http.StatusBadRequest, "Verification code expired.")
-			return
-		} else if err.Error() == "redis: nil" {
-			r.l.Error("http - v1 - register")
-			errorResponse(c, http.StatusBadRequest, "You have entered wrong phone number.")
-			return
-		} else if err.Error() == "invalid verification code" {
-			r.l.Error("http - v1 - register")
+		case errors.Is(err, entity.ErrInvalidCode):
+			r.l.Error(err, "http - v1 - verify")
 			errorResponse(c, http.StatusBadRequest, "Invalid verification code.")
-			return
-		} else {
-			r.l.Error(err, "http - v1 - register")
+		case errors.Is(err, entity.ErrRegistrationInProgress):
+			r.l.Error(err, "http - v1 - verify")
+			errorResponse(c, http.StatusConflict, "Registration is already being verified.")
+		case errors.Is(err, entity.ErrNicknameTaken):
+			r.l.Error(err, "http - v1 - verify")
+			errorResponse(c, http.StatusConflict, "Sorry, this nickname is already taken")
+		case errors.Is(err, entity.ErrPhoneTaken):
+			r.l.Error(err, "http - v1 - verify")
+			errorResponse(c, http.StatusConflict, "Sorry, user with this phone number is already registered")
+		default:
+			r.l.Error(err, "http - v1 - verify")
 			errorResponse(c, http.StatusInternalServerError, "auth service problems")
-			return
 		}
+
+		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
-// @Summary     Forgot Password
-// @Description Initiates the password reset process by sending a reset code to the user's phone number.
-// @ID          forgot-password
+// @Summary     Forgot password
+// @Description Sends a reset code if the phone number is registered. The answer is the same either way.
+// @ID          password-forgot
 // @Tags        auth
 // @Accept      json
 // @Produce     json
-// @Param       request body models.ForgotPasswordRequest true "Phone number"
-// @Success     200 {object} response
+// @Param       request body models.PasswordForgotRequest true "Phone number"
+// @Success     200 {object} models.MessageResponse
 // @Failure     400 {object} response
+// @Failure     403 {object} response
 // @Failure     500 {object} response
-// @Router      /auth/forgot [post]
-// @Deprecated
+// @Router      /auth/password/forgot [post]
 func (r *authRoutes) forgotPassword(c *gin.Context) {
-	var request models.ForgotPasswordRequest
+	var request models.PasswordForgotRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - forgotPassword")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		bindErrorResponse(c, err)
+
 		return
 	}
 
-	err := r.t.ForgotPassword(c.Request.Context(), request.PhoneNumber)
+	err := r.t.RequestPasswordReset(c.Request.Context(), request.PhoneNumber, challengeSolution(c, request.ChallengeAnswer))
 	if err != nil {
-		if err.Error() == "this phone number not registered in tarkib.uz yet" {
+		switch {
+		case errors.Is(err, entity.ErrInvalidPhoneNumber):
 			r.l.Error(err, "http - v1 - forgotPassword")
-			errorResponse(c, http.StatusBadRequest, "This phone number is not registered in tarkib.uz yet")
-			return
-		} else {
+			errorResponse(c, http.StatusBadRequest, "Invalid phone number")
+		case errors.Is(err, entity.ErrChallengeRequired):
+			r.l.Error(err, "http - v1 - forgotPassword")
+			errorResponse(c, http.StatusForbidden, "challenge required")
+		default:
 			r.l.Error(err, "http - v1 - forgotPassword")
 			errorResponse(c, http.StatusInternalServerError, "auth service problems")
-			return
 		}
+
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Password reset code sent to your phone number.",
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "If this phone number is registered, a reset code has been sent to it.",
 	})
 }
 
-// @Summary     Reset Password
-// @Description Resets the user's password using the provided reset code and new password.
-// @ID          reset-password
+// @Summary     Verify password reset code
+// @Description Exchanges the SMS code for a short-lived reset token.
+// @ID          password-verify
 // @Tags        auth
 // @Accept      json
 // @Produce     json
-// @Param       request body models.ResetPasswordRequest true "Phone number, reset code, and new password"
-// @Success     200 {object} models.ResetPasswordResponse
+// @Param       request body models.PasswordVerifyRequest true "Phone number and reset code"
+// @Success     200 {object} entity.PasswordResetToken
 // @Failure     400 {object} response
 // @Failure     500 {object} response
-// @Router      /auth/reset [post]
-// @Deprecated
+// @Router      /auth/password/verify [post]
+func (r *authRoutes) verifyPasswordReset(c *gin.Context) {
+	var request models.PasswordVerifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.l.Error(err, "http - v1 - verifyPasswordReset")
+		bindErrorResponse(c, err)
+
+		return
+	}
+
+	token, err := r.t.VerifyPasswordReset(c.Request.Context(), request.PhoneNumber, request.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidPhoneNumber):
+			r.l.Error(err, "http - v1 - verifyPasswordReset")
+			errorResponse(c, http.StatusBadRequest, "Invalid phone number")
+		case errors.Is(err, entity.ErrInvalidResetCode):
+			r.l.Error(err, "http - v1 - verifyPasswordReset")
+			errorResponse(c, http.StatusBadRequest, "Invalid or expired code.")
+		default:
+			r.l.Error(err, "http - v1 - verifyPasswordReset")
+			errorResponse(c, http.StatusInternalServerError, "auth service problems")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// @Summary     Reset password
+// @Description Sets a new password with the reset token and signs the user out on every device.
+// @ID          password-reset
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       request body models.PasswordResetRequest true "Reset token and new password"
+// @Success     200 {object} models.MessageResponse
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /auth/password/reset [post]
 func (r *authRoutes) resetPassword(c *gin.Context) {
-	var request models.ResetPasswordRequest
+	var request models.PasswordResetRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - resetPassword")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		bindErrorResponse(c, err)
+
 		return
 	}
 
-	err := r.t.ResetPassword(
-		c.Request.Context(),
-		request.PhoneNumber,
-		request.Code,
-		request.NewPassword,
-	)
+	err := r.t.ResetPassword(c.Request.Context(), request.ResetToken, request.NewPassword)
 	if err != nil {
-		if err.Error() == "invalid reset code" {
+		if errors.Is(err, entity.ErrInvalidResetToken) {
+			r.l.Error(err, "http - v1 - resetPassword")
+			errorResponse(c, http.StatusBadRequest, "Reset token is invalid or expired.")
+		} else {
 			r.l.Error(err, "http - v1 - resetPassword")
-			errorResponse(c, http.StatusBadRequest, "You have entered wrong code.")
+			errorResponse(c, http.StatusInternalServerError, "auth service problems")
 		}
-		r.l.Error(err, "http - v1 - resetPassword")
-		errorResponse(c, http.StatusInternalServerError, "auth service problems")
+
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ResetPasswordResponse{
+	c.JSON(http.StatusOK, models.MessageResponse{
 		Message: "Password reset successfully.",
 	})
 }
@@ -216,13 +329,15 @@ func (r *authRoutes) resetPassword(c *gin.Context) {
 // @Success     200 {object} models.LoginResponse
 // @Failure     400 {object} response
 // @Failure     401 {object} response
+// @Failure     403 {object} models.BannedResponse
+// @Failure     429 {object} response
 // @Failure     500 {object} response
 // @Router      /auth/login [post]
 func (r *authRoutes) login(c *gin.Context) {
 	var request models.LoginRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		r.l.Error(err, "http - v1 - login")
-		errorResponse(c, http.StatusBadRequest, "invalid request body")
+		bindErrorResponse(c, err)
 		return
 	}
 
@@ -230,8 +345,28 @@ func (r *authRoutes) login(c *gin.Context) {
 		NickName:    request.NickName,
 		PhoneNumber: request.PhoneNumber,
 		Password:    request.Password,
+		Client:      clientInfo(c, request.DeviceName),
 	})
 	if err != nil {
+		var locked *entity.LockedError
+		if errors.As(err, &locked) {
+			r.l.Error(err, "http - v1 - login")
+			middleware.TooManyRequests(c, locked.RetryAfter)
+
+			return
+		}
+
+		var banned *entity.BannedError
+		if errors.As(err, &banned) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.BannedResponse{
+				Error:  "Account banned",
+				Reason: banned.Ban.Reason,
+				Until:  banned.Ban.Until,
+			})
+
+			return
+		}
+
 		switch err.Error() {
 		case "user not found":
 			r.l.Error(err, "http - v1 - login")
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+// _maxCatalogFile bounds the size of an imported CSV file.
+const _maxCatalogFile = 5 << 20
+
+type catalogRoutes struct {
+	n usecase.Nutrition
+	l logger.Interface
+}
+
+type catalogFile struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+func newCatalogRoutes(handler *gin.RouterGroup, n usecase.Nutrition, l logger.Interface) {
+	r := &catalogRoutes{n, l}
+
+	handler.GET("/catalog/ingredients", r.search)
+	handler.POST("/admin/catalog/import", r.importCSV)
+}
+
+// @Summary     Search ingredient catalog
+// @Description Catalog entries with nutrition per 100 g. Set catalog_id on recipe ingredients to link them.
+// @ID          catalog-search
+// @Tags        catalog
+// @Produce     json
+// @Param       q      query string false "Part of the name"
+// @Param       limit  query int    false "Page size, 20 by default and at most 100"
+// @Param       offset query int    false "Offset"
+// @Success     200 {object} entity.CatalogList
+// @Failure     500 {object} response
+// @Router      /catalog/ingredients [get]
+func (r *catalogRoutes) search(c *gin.Context) {
+	list, err := r.n.Search(c.Request.Context(), entity.CatalogFilter{
+		Search: c.Query("q"),
+		Limit:  cast.ToUint64(c.Query("limit")),
+		Offset: cast.ToUint64(c.Query("offset")),
+	})
+	if err != nil {
+		r.l.Error(err, "http - v1 - catalog - search")
+		errorResponse(c, http.StatusInternalServerError, "catalog service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Import ingredient catalog
+// @Description Adds or updates catalog entries from a CSV file, matched by name.
+// @Description Columns: name, calories, protein, fat, carbohydrates, fiber (per 100 g), density (g/ml), piece_weight (g), aisle,
+// @Description aliases, allergens (contained) and diets (fitted); the last three are "|" separated.
+// @Description Nothing is imported if a line is invalid. Recipes pick up new values when they are next saved.
+// @ID          admin-catalog-import
+// @Tags        admin
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file formData file true "CSV file"
+// @Success     200 {object} entity.CatalogImport
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/catalog/import [post]
+func (r *catalogRoutes) importCSV(c *gin.Context) {
+	var request catalogFile
+	if err := c.ShouldBind(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	if request.File.Size > _maxCatalogFile {
+		errorResponse(c, http.StatusBadRequest, "file is larger than 5 MB")
+		return
+	}
+
+	file, err := request.File.Open()
+	if err != nil {
+		r.l.Error(err, "http - v1 - catalog - importCSV - Open")
+		errorResponse(c, http.StatusInternalServerError, "catalog service problems")
+
+		return
+	}
+	defer file.Close()
+
+	result, err := r.n.Import(c.Request.Context(), file)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCatalog) {
+			errorResponse(c, http.StatusBadRequest, err.Error())
+		} else {
+			r.l.Error(err, "http - v1 - catalog - importCSV")
+			errorResponse(c, http.StatusInternalServerError, "catalog service problems")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
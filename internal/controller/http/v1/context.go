@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/middleware"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// currentUserID returns the subject of the access token validated by the authorizer.
+func currentUserID(c *gin.Context) string {
+	return c.GetString(middleware.CtxUserID)
+}
+
+// currentSessionID returns the jti of the access token.
+func currentSessionID(c *gin.Context) string {
+	return c.GetString(middleware.CtxSessionID)
+}
+
+// withActor puts the caller into the request context for the audit hook.
+func withActor(c *gin.Context) {
+	ctx := usecase.WithActor(c.Request.Context(), entity.Actor{
+		UserID:    currentUserID(c),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.Request = c.Request.WithContext(ctx)
+}
@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type cookRoutes struct {
+	ck usecase.Cook
+	l  logger.Interface
+}
+
+func newCookRoutes(handler *gin.RouterGroup, ck usecase.Cook, l logger.Interface) {
+	r := &cookRoutes{ck, l}
+
+	handler.POST("/recipes/:id/cook-sessions", r.start)
+
+	h := handler.Group("/cook-sessions")
+	{
+		h.GET("", r.list)
+		h.GET("/:id", r.get)
+		h.PATCH("/:id", r.update)
+		h.POST("/:id/complete", r.complete)
+		h.DELETE("/:id", r.abandon)
+	}
+}
+
+// @Summary     Start cooking
+// @Description Opens a cook mode session on the current version of the recipe.
+// @Description An open session on the same recipe is returned instead, so other devices join it.
+// @ID          cook-sessions-start
+// @Tags        cook
+// @Produce     json
+// @Param       id path string true "Recipe ID"
+// @Success     201 {object} entity.CookSession
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/cook-sessions [post]
+func (r *cookRoutes) start(c *gin.Context) {
+	session, err := r.ck.Start(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "start")
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// @Summary     Open cook sessions
+// @ID          cook-sessions-list
+// @Tags        cook
+// @Produce     json
+// @Success     200 {array}  entity.CookSession
+// @Failure     500 {object} response
+// @Router      /cook-sessions [get]
+func (r *cookRoutes) list(c *gin.Context) {
+	sessions, err := r.ck.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Summary     Get cook session
+// @ID          cook-sessions-get
+// @Tags        cook
+// @Produce     json
+// @Param       id path string true "Session ID"
+// @Success     200 {object} entity.CookSession
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /cook-sessions/{id} [get]
+func (r *cookRoutes) get(c *gin.Context) {
+	session, err := r.ck.Get(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "get")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary     Update cook progress
+// @Description Moves to a step, starts or stops step timers and checks off ingredients.
+// @Description The new state is also pushed to the user's other devices as a cook_session event.
+// @ID          cook-sessions-update
+// @Tags        cook
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true "Session ID"
+// @Param       request body models.CookProgressRequest true "Progress"
+// @Success     200 {object} entity.CookSession
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /cook-sessions/{id} [patch]
+func (r *cookRoutes) update(c *gin.Context) {
+	var request models.CookProgressRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	session, err := r.ck.Update(c.Request.Context(), currentUserID(c), c.Param("id"), entity.CookProgress{
+		CurrentStep: request.CurrentStep,
+		StartTimers: request.StartTimers,
+		StopTimers:  request.StopTimers,
+		Check:       request.Check,
+		Uncheck:     request.Uncheck,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "update")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary     Finish cooking
+// @Description Closes the session. With cooked_it the cook counts towards the recipe's stats.
+// @ID          cook-sessions-complete
+// @Tags        cook
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true  "Session ID"
+// @Param       request body models.CompleteCookRequest false "Outcome"
+// @Success     200 {object} entity.CookSession
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /cook-sessions/{id}/complete [post]
+func (r *cookRoutes) complete(c *gin.Context) {
+	var request models.CompleteCookRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			bindErrorResponse(c, err)
+			return
+		}
+	}
+
+	session, err := r.ck.Complete(c.Request.Context(), currentUserID(c), c.Param("id"), request.CookedIt)
+	if err != nil {
+		r.errorResponse(c, err, "complete")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary     Abandon cooking
+// @Description Closes the session without recording a cook.
+// @ID          cook-sessions-abandon
+// @Tags        cook
+// @Produce     json
+// @Param       id path string true "Session ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /cook-sessions/{id} [delete]
+func (r *cookRoutes) abandon(c *gin.Context) {
+	if err := r.ck.Abandon(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "abandon")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Cook session closed",
+	})
+}
+
+func (r *cookRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Cook session not found")
+	case errors.Is(err, entity.ErrTooManyCookSessions),
+		errors.Is(err, entity.ErrCookSessionBusy):
+		errorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrInvalidStep),
+		errors.Is(err, entity.ErrUnknownIngredient):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - cook - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "cook service problems")
+	}
+}
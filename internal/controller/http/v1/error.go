@@ -4,10 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// response is the error envelope of every endpoint. Fields is set when the
+// request body failed validation.
 type response struct {
-	Error string `json:"error" example:"message"`
+	Error  string       `json:"error"            example:"message"`
+	Fields []fieldError `json:"fields,omitempty"`
 }
 
 func errorResponse(c *gin.Context, code int, msg string) {
-	c.AbortWithStatusJSON(code, response{msg})
+	c.AbortWithStatusJSON(code, response{Error: msg})
 }
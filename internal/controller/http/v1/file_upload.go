@@ -13,15 +13,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
 	"tarkib.uz/pkg/logger"
 )
 
 type fileRoutes struct {
+	j usecase.JobPublisher
 	l logger.Interface
 }
 
-func newFileRoutes(handler *gin.RouterGroup, l logger.Interface) {
-	r := &fileRoutes{l}
+func newFileRoutes(handler *gin.RouterGroup, j usecase.JobPublisher, l logger.Interface) {
+	r := &fileRoutes{j, l}
 
 	h := handler.Group("/file")
 	{
@@ -94,6 +97,17 @@ func (f *fileRoutes) upload(c *gin.Context) {
 		return
 	}
 
+	// Variants are optional; the original is already available.
+	if ext != ".svg" {
+		err = f.j.Publish(c.Request.Context(), entity.JobImageVariants, entity.ImageVariantsJob{
+			Bucket:     bucketName,
+			ObjectName: objectName,
+		})
+		if err != nil {
+			f.l.Error(err, "http - v1 - upload - Publish")
+		}
+	}
+
 	minioURL := fmt.Sprintf("http://%s/%s/%s", endpoint, bucketName, objectName)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,334 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+const (
+	_icsSuffix    = ".ics"
+	_feedTokenKey = "token"
+)
+
+type mealPlanRoutes struct {
+	mp usecase.MealPlan
+	l  logger.Interface
+}
+
+func newMealPlanRoutes(handler *gin.RouterGroup, mp usecase.MealPlan, l logger.Interface) {
+	r := &mealPlanRoutes{mp, l}
+
+	h := handler.Group("/meal-plans")
+	{
+		h.POST("", r.create)
+		h.GET("", r.list)
+		// Gin can't route "/:id.ics" apart from "/:id", so get serves both.
+		h.GET("/:id", r.get)
+		h.DELETE("/:id", r.delete)
+		h.PUT("/:id/slots/:day/:meal", r.setSlot)
+		h.DELETE("/:id/slots/:day/:meal", r.removeSlot)
+		h.POST("/:id/copy", r.copy)
+		h.POST("/:id/feed-token", r.rotateFeedToken)
+		h.POST("/:id/shopping-list", r.shoppingList)
+	}
+}
+
+// @Summary     Create meal plan
+// @Description Creates an empty plan of consecutive days from start_date, or a week template without dates.
+// @ID          meal-plans-create
+// @Tags        meal-plans
+// @Accept      json
+// @Produce     json
+// @Param       request body models.MealPlanRequest true "Plan"
+// @Success     201 {object} entity.MealPlan
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans [post]
+func (r *mealPlanRoutes) create(c *gin.Context) {
+	var request models.MealPlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	plan, err := r.mp.Create(c.Request.Context(), currentUserID(c), entity.MealPlan{
+		Name:      request.Name,
+		Template:  request.Template,
+		StartDate: request.StartDate,
+		Days:      request.Days,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "create")
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// @Summary     Meal plans
+// @Description The user's plans without slots, templates first.
+// @ID          meal-plans-list
+// @Tags        meal-plans
+// @Produce     json
+// @Success     200 {array}  entity.MealPlan
+// @Failure     500 {object} response
+// @Router      /meal-plans [get]
+func (r *mealPlanRoutes) list(c *gin.Context) {
+	plans, err := r.mp.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// @Summary     Get meal plan
+// @ID          meal-plans-get
+// @Tags        meal-plans
+// @Produce     json
+// @Param       id path string true "Plan ID"
+// @Success     200 {object} entity.MealPlan
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id} [get]
+func (r *mealPlanRoutes) get(c *gin.Context) {
+	if id := c.Param("id"); strings.HasSuffix(id, _icsSuffix) {
+		r.calendar(c, strings.TrimSuffix(id, _icsSuffix))
+		return
+	}
+
+	plan, err := r.mp.Get(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "get")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary     Meal plan calendar
+// @Description Exports the plan as an iCalendar feed. Calendar apps that can't sign in pass the plan's feed_token.
+// @ID          meal-plans-calendar
+// @Tags        meal-plans
+// @Produce     text/calendar
+// @Param       id    path  string true  "Plan ID"
+// @Param       token query string false "Feed token"
+// @Success     200 {string} string
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}.ics [get]
+func (r *mealPlanRoutes) calendar(c *gin.Context, planID string) {
+	calendar, err := r.mp.Calendar(c.Request.Context(), currentUserID(c), planID, c.Query(_feedTokenKey))
+	if err != nil {
+		r.errorResponse(c, err, "calendar")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = calendar.Encode(&buf); err != nil {
+		r.errorResponse(c, err, "calendar")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="meal-plan.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// @Summary     Delete meal plan
+// @ID          meal-plans-delete
+// @Tags        meal-plans
+// @Produce     json
+// @Param       id path string true "Plan ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id} [delete]
+func (r *mealPlanRoutes) delete(c *gin.Context) {
+	if err := r.mp.Delete(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "delete")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Meal plan deleted",
+	})
+}
+
+// @Summary     Plan a meal
+// @Description Puts a recipe on a meal of a plan day, replacing what was planned. Days count from 0.
+// @ID          meal-plans-set-slot
+// @Tags        meal-plans
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                 true "Plan ID"
+// @Param       day     path int                    true "Day"
+// @Param       meal    path string                 true "Meal" Enums(breakfast, lunch, dinner)
+// @Param       request body models.MealSlotRequest true "Recipe"
+// @Success     200 {object} entity.MealPlan
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}/slots/{day}/{meal} [put]
+func (r *mealPlanRoutes) setSlot(c *gin.Context) {
+	day, err := strconv.Atoi(c.Param("day"))
+	if err != nil {
+		r.errorResponse(c, entity.ErrInvalidMealSlot, "setSlot")
+		return
+	}
+
+	var request models.MealSlotRequest
+	if err = c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	plan, err := r.mp.SetSlot(c.Request.Context(), currentUserID(c), c.Param("id"), entity.MealSlot{
+		Day:      day,
+		Meal:     c.Param("meal"),
+		RecipeID: request.RecipeID,
+		Servings: request.Servings,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "setSlot")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary     Clear a meal
+// @ID          meal-plans-remove-slot
+// @Tags        meal-plans
+// @Produce     json
+// @Param       id   path string true "Plan ID"
+// @Param       day  path int    true "Day"
+// @Param       meal path string true "Meal" Enums(breakfast, lunch, dinner)
+// @Success     200 {object} entity.MealPlan
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}/slots/{day}/{meal} [delete]
+func (r *mealPlanRoutes) removeSlot(c *gin.Context) {
+	day, err := strconv.Atoi(c.Param("day"))
+	if err != nil {
+		r.errorResponse(c, entity.ErrInvalidMealSlot, "removeSlot")
+		return
+	}
+
+	plan, err := r.mp.RemoveSlot(c.Request.Context(), currentUserID(c), c.Param("id"), day, c.Param("meal"))
+	if err != nil {
+		r.errorResponse(c, err, "removeSlot")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary     Copy meal plan
+// @Description Copies the plan's meals to a new plan starting on start_date, or to a week template without one.
+// @ID          meal-plans-copy
+// @Tags        meal-plans
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true "Plan ID"
+// @Param       request body models.CopyMealPlanRequest true "Copy"
+// @Success     201 {object} entity.MealPlan
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}/copy [post]
+func (r *mealPlanRoutes) copy(c *gin.Context) {
+	var request models.CopyMealPlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	plan, err := r.mp.Copy(c.Request.Context(), currentUserID(c), c.Param("id"), request.Name, request.StartDate)
+	if err != nil {
+		r.errorResponse(c, err, "copy")
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// @Summary     Rotate feed token
+// @Description Issues a new feed token; calendars subscribed with the old one stop updating.
+// @ID          meal-plans-rotate-feed-token
+// @Tags        meal-plans
+// @Produce     json
+// @Param       id path string true "Plan ID"
+// @Success     200 {object} entity.MealPlan
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}/feed-token [post]
+func (r *mealPlanRoutes) rotateFeedToken(c *gin.Context) {
+	plan, err := r.mp.RotateFeedToken(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "rotateFeedToken")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary     Shopping list from meal plan
+// @Description Creates a shopping list with the recipes planned from from to to, both included.
+// @ID          meal-plans-shopping-list
+// @Tags        meal-plans
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                         true "Plan ID"
+// @Param       request body models.MealPlanShoppingRequest true "Date range"
+// @Success     201 {object} entity.ShoppingList
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /meal-plans/{id}/shopping-list [post]
+func (r *mealPlanRoutes) shoppingList(c *gin.Context) {
+	var request models.MealPlanShoppingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	list, err := r.mp.ShoppingList(c.Request.Context(), currentUserID(c), c.Param("id"), request.From, request.To, request.Name)
+	if err != nil {
+		r.errorResponse(c, err, "shoppingList")
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+func (r *mealPlanRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, entity.ErrInvalidMealPlan),
+		errors.Is(err, entity.ErrInvalidMealSlot),
+		errors.Is(err, entity.ErrInvalidDateRange),
+		errors.Is(err, entity.ErrNoMeals):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrMealPlanTemplate),
+		errors.Is(err, entity.ErrTooManyListRecipes):
+		errorResponse(c, http.StatusConflict, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - meal plan - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "meal plan service problems")
+	}
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_langEn = "en"
+	_langRu = "ru"
+	_langUz = "uz"
+
+	_msgValidationFailed = "validation_failed"
+	_msgInvalidBody      = "invalid_body"
+	_msgInvalid          = "invalid"
+)
+
+// _messages holds validation messages per language, keyed by validator tag.
+// {param} is replaced with the tag parameter.
+var _messages = map[string]map[string]string{
+	_langEn: {
+		_msgValidationFailed: "validation failed",
+		_msgInvalidBody:      "invalid request body",
+		_msgInvalid:          "is invalid",
+		"required":           "is required",
+		"required_without":   "is required",
+		"min":                "must be at least {param} characters",
+		"max":                "must be at most {param} characters",
+		"len":                "must be exactly {param} characters",
+		"numeric":            "must contain digits only",
+		"hexadecimal":        "must be a hexadecimal string",
+		"oneof":              "must be one of: {param}",
+		"url":                "must be a valid URL",
+		"uuid":               "must be a valid ID",
+		"datetime":           "must be a date like {param}",
+		_tagNickname:         `may contain only latin letters, digits, "_" and "."`,
+		_tagPassword:         "must contain at least one letter and one digit",
+		_tagPhone:            "must be an Uzbek mobile number",
+		_tagBase64Image:      "must be a base64 encoded JPEG or PNG up to 2 MB",
+	},
+	_langRu: {
+		_msgValidationFailed: "ошибка валидации",
+		_msgInvalidBody:      "неверное тело запроса",
+		_msgInvalid:          "неверное значение",
+		"required":           "обязательное поле",
+		"required_without":   "обязательное поле",
+		"min":                "должно содержать не менее {param} символов",
+		"max":                "должно содержать не более {param} символов",
+		"len":                "должно содержать ровно {param} символов",
+		"numeric":            "должно содержать только цифры",
+		"hexadecimal":        "должно быть шестнадцатеричной строкой",
+		"oneof":              "должно быть одним из: {param}",
+		"url":                "должно быть корректным URL",
+		"uuid":               "должно быть корректным ID",
+		"datetime":           "должно быть датой в формате {param}",
+		_tagNickname:         "может содержать только латинские буквы, цифры, «_» и «.»",
+		_tagPassword:         "должен содержать хотя бы одну букву и одну цифру",
+		_tagPhone:            "должен быть мобильным номером Узбекистана",
+		_tagBase64Image:      "должно быть изображением JPEG или PNG в base64 размером до 2 МБ",
+	},
+	_langUz: {
+		_msgValidationFailed: "so‘rov ma’lumotlari noto‘g‘ri",
+		_msgInvalidBody:      "so‘rov tanasi noto‘g‘ri",
+		_msgInvalid:          "noto‘g‘ri qiymat",
+		"required":           "majburiy maydon",
+		"required_without":   "majburiy maydon",
+		"min":                "kamida {param} ta belgidan iborat bo‘lishi kerak",
+		"max":                "ko‘pi bilan {param} ta belgidan iborat bo‘lishi kerak",
+		"len":                "aynan {param} ta belgidan iborat bo‘lishi kerak",
+		"numeric":            "faqat raqamlardan iborat bo‘lishi kerak",
+		"hexadecimal":        "o‘n oltilik satr bo‘lishi kerak",
+		"oneof":              "quyidagilardan biri bo‘lishi kerak: {param}",
+		"url":                "to‘g‘ri URL bo‘lishi kerak",
+		"uuid":               "to‘g‘ri ID bo‘lishi kerak",
+		"datetime":           "{param} ko‘rinishidagi sana bo‘lishi kerak",
+		_tagNickname:         "faqat lotin harflari, raqamlar, «_» va «.» bo‘lishi mumkin",
+		_tagPassword:         "kamida bitta harf va bitta raqam bo‘lishi kerak",
+		_tagPhone:            "O‘zbekiston mobil raqami bo‘lishi kerak",
+		_tagBase64Image:      "2 MB gacha bo‘lgan base64 JPEG yoki PNG rasm bo‘lishi kerak",
+	},
+}
+
+// requestLanguage picks the first supported language from Accept-Language.
+func requestLanguage(c *gin.Context) string {
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+
+		if _, ok := _messages[lang]; ok {
+			return lang
+		}
+	}
+
+	return _langEn
+}
+
+func translate(lang, key, param string) string {
+	msg, ok := _messages[lang][key]
+	if !ok {
+		msg = _messages[lang][_msgInvalid]
+	}
+
+	return strings.ReplaceAll(msg, "{param}", param)
+}
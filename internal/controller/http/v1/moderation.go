@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type moderationRoutes struct {
+	m usecase.Moderation
+	l logger.Interface
+}
+
+func newModerationRoutes(handler *gin.RouterGroup, m usecase.Moderation, l logger.Interface) {
+	r := &moderationRoutes{m, l}
+
+	handler.POST("/reports", r.report)
+
+	h := handler.Group("/moderation/reports")
+	{
+		h.GET("", r.list)
+		h.GET("/:id", r.get)
+		h.POST("/:id/triage", r.triage)
+		h.POST("/:id/action", r.action)
+		h.POST("/:id/dismiss", r.dismiss)
+	}
+}
+
+// @Summary     Report content
+// @Description Reports a recipe, comment or profile to the moderators.
+// @ID          reports-create
+// @Tags        moderation
+// @Accept      json
+// @Produce     json
+// @Param       request body models.ReportRequest true "Report"
+// @Success     201 {object} entity.Report
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /reports [post]
+func (r *moderationRoutes) report(c *gin.Context) {
+	var request models.ReportRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	report, err := r.m.Report(c.Request.Context(), entity.Report{
+		ReporterID: currentUserID(c),
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+		Reason:     request.Reason,
+		Details:    request.Details,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "report")
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// @Summary     Moderation queue
+// @Description Reports oldest first.
+// @ID          moderation-reports-list
+// @Tags        moderation
+// @Produce     json
+// @Param       status      query string false "open, triaged, actioned or dismissed"
+// @Param       target_type query string false "recipe, comment or profile"
+// @Param       limit       query int    false "Page size, 50 by default and at most 200"
+// @Param       offset      query int    false "Offset"
+// @Success     200 {object} entity.ReportList
+// @Failure     500 {object} response
+// @Router      /moderation/reports [get]
+func (r *moderationRoutes) list(c *gin.Context) {
+	list, err := r.m.List(c.Request.Context(), entity.ReportFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("target_type"),
+		Limit:      cast.ToUint64(c.Query("limit")),
+		Offset:     cast.ToUint64(c.Query("offset")),
+	})
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Get report
+// @ID          moderation-reports-get
+// @Tags        moderation
+// @Produce     json
+// @Param       id path string true "Report ID"
+// @Success     200 {object} entity.Report
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /moderation/reports/{id} [get]
+func (r *moderationRoutes) get(c *gin.Context) {
+	report, err := r.m.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "get")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary     Triage report
+// @Description Assigns an open report to the caller.
+// @ID          moderation-reports-triage
+// @Tags        moderation
+// @Produce     json
+// @Param       id path string true "Report ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /moderation/reports/{id}/triage [post]
+func (r *moderationRoutes) triage(c *gin.Context) {
+	if err := r.m.Triage(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "triage")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Report triaged",
+	})
+}
+
+// @Summary     Act on report
+// @Description Hides or deletes the target, or warns or bans its author. Every pending
+// @Description report on the target is closed and the reporters are notified.
+// @ID          moderation-reports-action
+// @Tags        moderation
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                         true "Report ID"
+// @Param       request body models.ModerationActionRequest true "Action"
+// @Success     200 {object} models.MessageResponse
+// @Failure     400 {object} response
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /moderation/reports/{id}/action [post]
+func (r *moderationRoutes) action(c *gin.Context) {
+	var request models.ModerationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	err := r.m.Action(c.Request.Context(), currentUserID(c), c.Param("id"), entity.ModerationDecision{
+		Action:   request.Action,
+		Note:     request.Note,
+		BanUntil: request.BanUntil,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "action")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Report actioned",
+	})
+}
+
+// @Summary     Dismiss report
+// @Description Closes every pending report on the target without action.
+// @ID          moderation-reports-dismiss
+// @Tags        moderation
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                      true "Report ID"
+// @Param       request body models.DismissReportRequest false "Note"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /moderation/reports/{id}/dismiss [post]
+func (r *moderationRoutes) dismiss(c *gin.Context) {
+	var request models.DismissReportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			bindErrorResponse(c, err)
+			return
+		}
+	}
+
+	if err := r.m.Dismiss(c.Request.Context(), currentUserID(c), c.Param("id"), request.Note); err != nil {
+		r.errorResponse(c, err, "dismiss")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Report dismissed",
+	})
+}
+
+func (r *moderationRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, entity.ErrAlreadyReported),
+		errors.Is(err, entity.ErrReportClosed):
+		errorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrUnsupportedAction),
+		errors.Is(err, entity.ErrSelfAction),
+		errors.Is(err, entity.ErrInvalidBanExpiry):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrForbidden):
+		errorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - moderation - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "moderation service problems")
+	}
+}
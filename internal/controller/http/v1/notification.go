@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type notificationRoutes struct {
+	n usecase.Notification
+	l logger.Interface
+}
+
+func newNotificationRoutes(handler *gin.RouterGroup, n usecase.Notification, l logger.Interface) {
+	r := &notificationRoutes{n, l}
+
+	h := handler.Group("/notifications")
+	{
+		h.GET("", r.list)
+		h.GET("/unread-count", r.unreadCount)
+		h.PUT("/read-all", r.markAllRead)
+		h.PUT("/:id/read", r.markRead)
+	}
+}
+
+// @Summary     List notifications
+// @Description Returns the caller's notifications, newest first.
+// @ID          list-notifications
+// @Tags        notifications
+// @Produce     json
+// @Param       limit  query    int false "Page size (default 20, max 100)"
+// @Param       offset query    int false "Offset"
+// @Success     200 {object} entity.NotificationList
+// @Failure     500 {object} response
+// @Router      /notifications [get]
+func (r *notificationRoutes) list(c *gin.Context) {
+	list, err := r.n.List(
+		c.Request.Context(),
+		currentUserID(c),
+		cast.ToUint64(c.Query("limit")),
+		cast.ToUint64(c.Query("offset")),
+	)
+	if err != nil {
+		r.l.Error(err, "http - v1 - listNotifications")
+		errorResponse(c, http.StatusInternalServerError, "notification service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Unread notifications count
+// @Description Returns the number of unread notifications of the caller.
+// @ID          unread-notifications-count
+// @Tags        notifications
+// @Produce     json
+// @Success     200 {object} models.UnreadCountResponse
+// @Failure     500 {object} response
+// @Router      /notifications/unread-count [get]
+func (r *notificationRoutes) unreadCount(c *gin.Context) {
+	count, err := r.n.UnreadCount(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.l.Error(err, "http - v1 - unreadCount")
+		errorResponse(c, http.StatusInternalServerError, "notification service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UnreadCountResponse{Count: count})
+}
+
+// @Summary     Mark notification as read
+// @ID          mark-notification-read
+// @Tags        notifications
+// @Produce     json
+// @Param       id path string true "Notification ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /notifications/{id}/read [put]
+func (r *notificationRoutes) markRead(c *gin.Context) {
+	err := r.n.MarkRead(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			errorResponse(c, http.StatusNotFound, "Notification not found")
+		} else {
+			r.l.Error(err, "http - v1 - markRead")
+			errorResponse(c, http.StatusInternalServerError, "notification service problems")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Notification marked as read."})
+}
+
+// @Summary     Mark all notifications as read
+// @ID          mark-all-notifications-read
+// @Tags        notifications
+// @Produce     json
+// @Success     200 {object} models.MessageResponse
+// @Failure     500 {object} response
+// @Router      /notifications/read-all [put]
+func (r *notificationRoutes) markAllRead(c *gin.Context) {
+	if err := r.n.MarkAllRead(c.Request.Context(), currentUserID(c)); err != nil {
+		r.l.Error(err, "http - v1 - markAllRead")
+		errorResponse(c, http.StatusInternalServerError, "notification service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "All notifications marked as read."})
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type pantryRoutes struct {
+	p usecase.Pantry
+	l logger.Interface
+}
+
+func newPantryRoutes(handler *gin.RouterGroup, p usecase.Pantry, l logger.Interface) {
+	r := &pantryRoutes{p, l}
+
+	h := handler.Group("/pantry")
+	{
+		h.GET("", r.list)
+		h.POST("", r.add)
+		h.GET("/recipes", r.cookable)
+		h.PUT("/:id", r.update)
+		h.DELETE("/:id", r.delete)
+	}
+}
+
+// @Summary     Pantry
+// @Description What the user has at home, items expiring first.
+// @ID          pantry-list
+// @Tags        pantry
+// @Produce     json
+// @Success     200 {array}  entity.PantryItem
+// @Failure     500 {object} response
+// @Router      /pantry [get]
+func (r *pantryRoutes) list(c *gin.Context) {
+	items, err := r.p.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary     Add pantry item
+// @Description A quantity of 0 means "some". Items with expires_on are reminded of through notifications shortly before.
+// @ID          pantry-add
+// @Tags        pantry
+// @Accept      json
+// @Produce     json
+// @Param       request body models.PantryItemRequest true "Item"
+// @Success     201 {object} entity.PantryItem
+// @Failure     400 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /pantry [post]
+func (r *pantryRoutes) add(c *gin.Context) {
+	var request models.PantryItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	item, err := r.p.Add(c.Request.Context(), currentUserID(c), pantryItem(request))
+	if err != nil {
+		r.errorResponse(c, err, "add")
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// @Summary     Update pantry item
+// @ID          pantry-update
+// @Tags        pantry
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                   true "Item ID"
+// @Param       request body models.PantryItemRequest true "Item"
+// @Success     200 {object} entity.PantryItem
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /pantry/{id} [put]
+func (r *pantryRoutes) update(c *gin.Context) {
+	var request models.PantryItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	item := pantryItem(request)
+	item.ID = c.Param("id")
+
+	updated, err := r.p.Update(c.Request.Context(), currentUserID(c), item)
+	if err != nil {
+		r.errorResponse(c, err, "update")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// @Summary     Remove pantry item
+// @ID          pantry-delete
+// @Tags        pantry
+// @Produce     json
+// @Param       id path string true "Item ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /pantry/{id} [delete]
+func (r *pantryRoutes) delete(c *gin.Context) {
+	if err := r.p.Delete(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "delete")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Pantry item removed",
+	})
+}
+
+// @Summary     What can I cook
+// @Description Published recipes ranked by how many of their required ingredients the pantry covers.
+// @Description Expired items don't count; lines without a quantity, such as salt to taste, are not required.
+// @ID          pantry-cookable
+// @Tags        pantry
+// @Produce     json
+// @Param       max_missing query int false "Most missing ingredients, 0 to 2, 2 by default"
+// @Param       limit       query int false "Page size, 20 by default and at most 50"
+// @Success     200 {array}  entity.RecipeMatch
+// @Failure     500 {object} response
+// @Router      /pantry/recipes [get]
+func (r *pantryRoutes) cookable(c *gin.Context) {
+	matches, err := r.p.Cookable(c.Request.Context(), currentUserID(c), entity.CookableFilter{
+		MaxMissing: cast.ToInt(c.DefaultQuery("max_missing", strconv.Itoa(entity.MaxMissingIngredients))),
+		Limit:      cast.ToUint64(c.Query("limit")),
+	})
+	if err != nil {
+		r.errorResponse(c, err, "cookable")
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+func pantryItem(request models.PantryItemRequest) entity.PantryItem {
+	return entity.PantryItem{
+		Name:      request.Name,
+		CatalogID: request.CatalogID,
+		Quantity:  request.Quantity,
+		Unit:      request.Unit,
+		ExpiresOn: request.ExpiresOn,
+	}
+}
+
+func (r *pantryRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Pantry item not found")
+	case errors.Is(err, entity.ErrInvalidPantryItem):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrPantryFull):
+		errorResponse(c, http.StatusConflict, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - pantry - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "pantry service problems")
+	}
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type preferenceRoutes struct {
+	d usecase.Dietary
+	l logger.Interface
+}
+
+func newPreferenceRoutes(handler *gin.RouterGroup, d usecase.Dietary, l logger.Interface) {
+	r := &preferenceRoutes{d, l}
+
+	h := handler.Group("/me/preferences")
+	{
+		h.GET("", r.get)
+		h.PUT("", r.set)
+	}
+}
+
+// @Summary     Dietary preferences
+// @Description Allergens to avoid and diets to follow. Recipe lists apply them.
+// @ID          preferences-get
+// @Tags        preferences
+// @Produce     json
+// @Success     200 {object} entity.DietaryPreferences
+// @Failure     500 {object} response
+// @Router      /me/preferences [get]
+func (r *preferenceRoutes) get(c *gin.Context) {
+	preferences, err := r.d.Preferences(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.l.Error(err, "http - v1 - preferences - get")
+		errorResponse(c, http.StatusInternalServerError, "preference service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// @Summary     Set dietary preferences
+// @ID          preferences-set
+// @Tags        preferences
+// @Accept      json
+// @Produce     json
+// @Param       request body models.DietaryPreferencesRequest true "Preferences"
+// @Success     200 {object} entity.DietaryPreferences
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /me/preferences [put]
+func (r *preferenceRoutes) set(c *gin.Context) {
+	var request models.DietaryPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	preferences, err := r.d.SetPreferences(c.Request.Context(), currentUserID(c), entity.DietaryPreferences{
+		Allergens: request.Allergens,
+		Diets:     request.Diets,
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidPreference) {
+			errorResponse(c, http.StatusBadRequest, err.Error())
+		} else {
+			r.l.Error(err, "http - v1 - preferences - set")
+			errorResponse(c, http.StatusInternalServerError, "preference service problems")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,461 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type recipeRoutes struct {
+	rc usecase.Recipe
+	l  logger.Interface
+}
+
+func newRecipeRoutes(handler *gin.RouterGroup, rc usecase.Recipe, l logger.Interface) {
+	r := &recipeRoutes{rc, l}
+
+	handler.GET("/me/recipes", r.mine)
+
+	h := handler.Group("/recipes")
+	{
+		h.GET("", r.list)
+		h.POST("", r.create)
+		h.GET("/:id", r.get)
+		h.PUT("/:id", r.update)
+		h.PUT("/:id/status", r.setStatus)
+		h.DELETE("/:id", r.delete)
+		h.GET("/:id/revisions", r.revisions)
+		h.GET("/:id/revisions/diff", r.diff)
+		h.GET("/:id/revisions/:version", r.revision)
+		h.POST("/:id/revisions/:version/restore", r.restore)
+		h.PUT("/:id/dietary", r.setDietary)
+	}
+}
+
+// @Summary     List recipes
+// @Description Published recipes, newest first.
+// @ID          recipes-list
+// @Tags        recipes
+// @Produce     json
+// @Description Signed-in users only see recipes matching their dietary preferences unless ignore_preferences is set.
+// @Param       q                  query string   false "Search in titles"
+// @Param       author_id          query string   false "Author"
+// @Param       without_allergens  query []string false "Leave out recipes with these allergens" collectionFormat(multi)
+// @Param       diet               query []string false "Only recipes fitting these diets" collectionFormat(multi)
+// @Param       ignore_preferences query bool     false "Don't apply the caller's dietary preferences"
+// @Param       limit              query int      false "Page size, 20 by default and at most 100"
+// @Param       offset             query int      false "Offset"
+// @Success     200 {object} entity.RecipeList
+// @Failure     500 {object} response
+// @Router      /recipes [get]
+func (r *recipeRoutes) list(c *gin.Context) {
+	filter := entity.RecipeFilter{
+		AuthorID:         c.Query("author_id"),
+		Search:           c.Query("q"),
+		WithoutAllergens: c.QueryArray("without_allergens"),
+		Diets:            c.QueryArray("diet"),
+		Limit:            cast.ToUint64(c.Query("limit")),
+		Offset:           cast.ToUint64(c.Query("offset")),
+	}
+
+	if !cast.ToBool(c.Query("ignore_preferences")) {
+		filter.ViewerID = currentUserID(c)
+	}
+
+	list, err := r.rc.List(c.Request.Context(), filter)
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     My recipes
+// @Description The caller's recipes in every state, last edited first.
+// @ID          recipes-mine
+// @Tags        recipes
+// @Produce     json
+// @Param       status query string false "draft, published, unlisted or archived"
+// @Param       limit  query int    false "Page size, 20 by default and at most 100"
+// @Param       offset query int    false "Offset"
+// @Success     200 {object} entity.RecipeList
+// @Failure     500 {object} response
+// @Router      /me/recipes [get]
+func (r *recipeRoutes) mine(c *gin.Context) {
+	list, err := r.rc.ListByAuthor(c.Request.Context(), entity.RecipeFilter{
+		AuthorID: currentUserID(c),
+		Status:   c.Query("status"),
+		Limit:    cast.ToUint64(c.Query("limit")),
+		Offset:   cast.ToUint64(c.Query("offset")),
+	})
+	if err != nil {
+		r.errorResponse(c, err, "mine")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Create recipe
+// @Description Starts a draft.
+// @ID          recipes-create
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       request body models.RecipeRequest true "Recipe"
+// @Success     201 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes [post]
+func (r *recipeRoutes) create(c *gin.Context) {
+	var request models.RecipeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	recipe, err := r.rc.Create(c.Request.Context(), entity.Recipe{
+		AuthorID:    currentUserID(c),
+		Title:       request.Title,
+		Description: request.Description,
+		Servings:    request.Servings,
+		Ingredients: ingredients(request.Ingredients),
+		Sections:    sections(request.Sections),
+	})
+	if err != nil {
+		r.errorResponse(c, err, "create")
+		return
+	}
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// @Summary     Get recipe
+// @Description Drafts and archived recipes are visible to their author only.
+// @ID          recipes-get
+// @Tags        recipes
+// @Produce     json
+// @Param       id path string true "Recipe ID"
+// @Success     200 {object} entity.Recipe
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id} [get]
+func (r *recipeRoutes) get(c *gin.Context) {
+	recipe, err := r.rc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "get")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// @Summary     Save recipe
+// @Description Saves the content; also used for autosave. Fails with 409 when the
+// @Description recipe was saved elsewhere since the given version was loaded.
+// @ID          recipes-update
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true "Recipe ID"
+// @Param       request body models.UpdateRecipeRequest true "Recipe"
+// @Success     200 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id} [put]
+func (r *recipeRoutes) update(c *gin.Context) {
+	var request models.UpdateRecipeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	recipe, err := r.rc.Update(c.Request.Context(), currentUserID(c), entity.Recipe{
+		ID:          c.Param("id"),
+		Title:       request.Title,
+		Description: request.Description,
+		Servings:    request.Servings,
+		Ingredients: ingredients(request.Ingredients),
+		Sections:    sections(request.Sections),
+		Version:     request.Version,
+	})
+	if err != nil {
+		r.errorResponse(c, err, "update")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// @Summary     Change recipe state
+// @Description Publishes, unlists, archives or returns the recipe to drafts.
+// @Description Publishing with a future publish_at schedules it.
+// @ID          recipes-status
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true "Recipe ID"
+// @Param       request body models.RecipeStatusRequest true "State"
+// @Success     200 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/status [put]
+func (r *recipeRoutes) setStatus(c *gin.Context) {
+	var request models.RecipeStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	recipe, err := r.rc.SetStatus(c.Request.Context(), currentUserID(c), c.Param("id"), request.Status, request.PublishAt)
+	if err != nil {
+		r.errorResponse(c, err, "setStatus")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// @Summary     Delete recipe
+// @ID          recipes-delete
+// @Tags        recipes
+// @Produce     json
+// @Param       id path string true "Recipe ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id} [delete]
+func (r *recipeRoutes) delete(c *gin.Context) {
+	if err := r.rc.Delete(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "delete")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Recipe deleted",
+	})
+}
+
+// @Summary     Recipe revisions
+// @Description Saved versions of the recipe, newest first, without their content.
+// @ID          recipes-revisions
+// @Tags        recipes
+// @Produce     json
+// @Param       id path string true "Recipe ID"
+// @Success     200 {array}  entity.RecipeRevision
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/revisions [get]
+func (r *recipeRoutes) revisions(c *gin.Context) {
+	revisions, err := r.rc.Revisions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// @Summary     Recipe revision
+// @ID          recipes-revision
+// @Tags        recipes
+// @Produce     json
+// @Param       id      path string true "Recipe ID"
+// @Param       version path int    true "Version"
+// @Success     200 {object} entity.RecipeRevision
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/revisions/{version} [get]
+func (r *recipeRoutes) revision(c *gin.Context) {
+	revision, err := r.rc.Revision(c.Request.Context(), c.Param("id"), cast.ToInt(c.Param("version")))
+	if err != nil {
+		r.errorResponse(c, err, "revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// @Summary     Compare revisions
+// @Description Title, description and servings changes, and element changes of the
+// @Description ingredient and section lists.
+// @ID          recipes-revisions-diff
+// @Tags        recipes
+// @Produce     json
+// @Param       id   path  string true "Recipe ID"
+// @Param       from query int    true "Older version"
+// @Param       to   query int    true "Newer version"
+// @Success     200 {object} entity.RecipeDiff
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/revisions/diff [get]
+func (r *recipeRoutes) diff(c *gin.Context) {
+	from, to := cast.ToInt(c.Query("from")), cast.ToInt(c.Query("to"))
+	if from < 1 || to < 1 {
+		errorResponse(c, http.StatusBadRequest, "from and to must be versions")
+		return
+	}
+
+	d, err := r.rc.Diff(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		r.errorResponse(c, err, "diff")
+		return
+	}
+
+	c.JSON(http.StatusOK, d)
+}
+
+// @Summary     Restore revision
+// @Description Saves the content of the revision as the newest version.
+// @ID          recipes-revision-restore
+// @Tags        recipes
+// @Produce     json
+// @Param       id      path string true "Recipe ID"
+// @Param       version path int    true "Version"
+// @Success     200 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/revisions/{version}/restore [post]
+func (r *recipeRoutes) restore(c *gin.Context) {
+	recipe, err := r.rc.Restore(c.Request.Context(), currentUserID(c), c.Param("id"), cast.ToInt(c.Param("version")))
+	if err != nil {
+		r.errorResponse(c, err, "restore")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// @Summary     Override dietary flags
+// @Description Replaces the author's corrections of detected allergens and diets.
+// @Description Each override says whether the allergen is present or the diet is met, and why.
+// @ID          recipes-dietary
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                         true "Recipe ID"
+// @Param       request body models.DietaryOverridesRequest true "Overrides"
+// @Success     200 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /recipes/{id}/dietary [put]
+func (r *recipeRoutes) setDietary(c *gin.Context) {
+	var request models.DietaryOverridesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	overrides := make([]entity.DietaryOverride, 0, len(request.Overrides))
+	for _, o := range request.Overrides {
+		overrides = append(overrides, entity.DietaryOverride{
+			Kind:    o.Kind,
+			Value:   o.Value,
+			Present: o.Present,
+			Reason:  o.Reason,
+		})
+	}
+
+	recipe, err := r.rc.SetDietaryOverrides(c.Request.Context(), currentUserID(c), c.Param("id"), overrides)
+	if err != nil {
+		r.errorResponse(c, err, "setDietary")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+func (r *recipeRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Recipe not found")
+	case errors.Is(err, entity.ErrVersionConflict):
+		errorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrRecipeIncomplete),
+		errors.Is(err, entity.ErrInvalidStatus),
+		errors.Is(err, entity.ErrInvalidSection),
+		errors.Is(err, entity.ErrDuplicateIngredient),
+		errors.Is(err, entity.ErrInvalidOverride):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - recipes - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "recipe service problems")
+	}
+}
+
+func ingredients(request []models.IngredientRequest) []entity.Ingredient {
+	result := make([]entity.Ingredient, 0, len(request))
+	for _, i := range request {
+		result = append(result, entity.Ingredient{
+			ID:        i.ID,
+			Name:      i.Name,
+			CatalogID: i.CatalogID,
+			Quantity:  i.Quantity,
+			Unit:      i.Unit,
+			Note:      i.Note,
+		})
+	}
+
+	return result
+}
+
+func sections(request []models.SectionRequest) []entity.Section {
+	result := make([]entity.Section, 0, len(request))
+	for _, s := range request {
+		result = append(result, entity.Section{
+			Type:    s.Type,
+			Content: s.Content,
+			URL:     s.URL,
+			Step:    step(s.Step),
+		})
+	}
+
+	return result
+}
+
+func step(request *models.StepRequest) *entity.Step {
+	if request == nil {
+		return nil
+	}
+
+	st := &entity.Step{
+		Duration: request.Duration,
+	}
+
+	if t := request.Timer; t != nil {
+		st.Timer = &entity.Timer{Duration: t.Duration, Label: t.Label}
+	}
+
+	if t := request.Temperature; t != nil {
+		st.Temperature = &entity.Temperature{Value: t.Value, Unit: t.Unit}
+	}
+
+	if m := request.Media; m != nil {
+		st.Media = &entity.Media{Type: m.Type, URL: m.URL}
+	}
+
+	for _, ref := range request.Ingredients {
+		st.Ingredients = append(st.Ingredients, entity.IngredientRef{
+			IngredientID: ref.IngredientID,
+			Quantity:     ref.Quantity,
+			Unit:         ref.Unit,
+		})
+	}
+
+	return st
+}
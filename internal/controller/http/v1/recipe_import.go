@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type recipeImportRoutes struct {
+	ri usecase.RecipeImport
+	l  logger.Interface
+}
+
+func newRecipeImportRoutes(handler *gin.RouterGroup, ri usecase.RecipeImport, l logger.Interface) {
+	r := &recipeImportRoutes{ri, l}
+
+	h := handler.Group("/recipe-imports")
+	{
+		h.POST("/url", r.fromURL)
+		h.POST("/document", r.fromDocument)
+	}
+}
+
+// @Summary     Import recipe from URL
+// @Description Reads the schema.org Recipe (JSON-LD or microdata) on the page into a new draft to review.
+// @Description Only public http(s) addresses are fetched.
+// @ID          recipe-imports-url
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       request body models.ImportURLRequest true "Page"
+// @Success     201 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     422 {object} response
+// @Failure     502 {object} response
+// @Failure     500 {object} response
+// @Router      /recipe-imports/url [post]
+func (r *recipeImportRoutes) fromURL(c *gin.Context) {
+	var request models.ImportURLRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	recipe, err := r.ri.FromURL(c.Request.Context(), currentUserID(c), request.URL)
+	if err != nil {
+		r.errorResponse(c, err, "fromURL")
+		return
+	}
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// @Summary     Import recipe from document
+// @Description Reads an HTML page with a schema.org Recipe, or a Markdown file or Telegram post with
+// @Description ingredients and steps under their headings, into a new draft to review.
+// @ID          recipe-imports-document
+// @Tags        recipes
+// @Accept      json
+// @Produce     json
+// @Param       request body models.ImportDocumentRequest true "Document"
+// @Success     201 {object} entity.Recipe
+// @Failure     400 {object} response
+// @Failure     422 {object} response
+// @Failure     500 {object} response
+// @Router      /recipe-imports/document [post]
+func (r *recipeImportRoutes) fromDocument(c *gin.Context) {
+	var request models.ImportDocumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	recipe, err := r.ri.FromDocument(c.Request.Context(), currentUserID(c), request.Format, request.Content)
+	if err != nil {
+		r.errorResponse(c, err, "fromDocument")
+		return
+	}
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+func (r *recipeImportRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidImportURL):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrNoRecipeFound):
+		errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, entity.ErrImportFetch):
+		errorResponse(c, http.StatusBadGateway, entity.ErrImportFetch.Error())
+	case errors.Is(err, entity.ErrInvalidSection),
+		errors.Is(err, entity.ErrDuplicateIngredient):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - recipe imports - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "recipe import service problems")
+	}
+}
@@ -4,6 +4,7 @@ package v1
 import (
 	"net/http"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -11,11 +12,41 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	// Swagger docs.
+	"tarkib.uz/config"
 	_ "tarkib.uz/docs"
+	"tarkib.uz/internal/controller/middleware"
 	"tarkib.uz/internal/usecase"
 	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/ratelimit"
+	tokens "tarkib.uz/pkg/token"
 )
 
+// Deps are the services the routes are built on.
+type Deps struct {
+	Logger       logger.Interface
+	Config       *config.Config
+	Enforcer     *casbin.Enforcer
+	Limiter      *ratelimit.Limiter
+	Tokens       *tokens.Manager
+	Jobs         usecase.JobPublisher
+	Auth         usecase.Auth
+	BotGuard     usecase.BotGuard
+	Sessions     usecase.Sessions
+	Admin        usecase.Admin
+	Audit        usecase.Audit
+	Moderation   usecase.Moderation
+	Recipe       usecase.Recipe
+	RecipeImport usecase.RecipeImport
+	Cook         usecase.Cook
+	Shopping     usecase.Shopping
+	MealPlan     usecase.MealPlan
+	Pantry       usecase.Pantry
+	Nutrition    usecase.Nutrition
+	Dietary      usecase.Dietary
+	Notification usecase.Notification
+	Realtime     usecase.Realtime
+}
+
 // NewRouter -.
 // Swagger spec:
 // @title       tarkib.uz back-end
@@ -23,8 +54,11 @@ import (
 // @version     1.0
 // @BasePath    /v1
 // @security    BearerAuth
-func NewRouter(handler *gin.Engine, l logger.Interface, t usecase.Auth) {
+func NewRouter(handler *gin.Engine, d Deps) {
+	l := d.Logger
+
 	// Options
+	handler.Use(hideStreamToken)
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
 
@@ -43,13 +77,37 @@ func NewRouter(handler *gin.Engine, l logger.Interface, t usecase.Auth) {
 	// K8s probe
 	handler.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
 
+	// Public keys for services verifying our access tokens
+	handler.GET("/.well-known/jwks.json", func(c *gin.Context) { c.JSON(http.StatusOK, d.Tokens.JWKS()) })
+
 	// Prometheus metrics
 	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	registerValidators()
+
 	// Routers
 	h := handler.Group("/v1")
+	h.Use(middleware.NewAuthorizer(d.Enforcer, d.Tokens, d.Sessions, map[string]usecase.ResourceAccessor{
+		"/v1/recipes/:id": d.Recipe,
+	}, d.Config, l))
+	h.Use(middleware.NewRateLimiter(d.Limiter, d.Config, l))
+	h.Use(withActor)
 	{
-		newAuthRoutes(h, t, l)
-		newFileRoutes(h, l)
+		newAuthRoutes(h, d.Auth, d.BotGuard, l)
+		newSessionRoutes(h, d.Sessions, l)
+		newAdminRoutes(h, d.Admin, l)
+		newAuditRoutes(h, d.Audit, l)
+		newModerationRoutes(h, d.Moderation, l)
+		newRecipeRoutes(h, d.Recipe, l)
+		newRecipeImportRoutes(h, d.RecipeImport, l)
+		newCookRoutes(h, d.Cook, l)
+		newShoppingRoutes(h, d.Shopping, l)
+		newMealPlanRoutes(h, d.MealPlan, l)
+		newPantryRoutes(h, d.Pantry, l)
+		newCatalogRoutes(h, d.Nutrition, l)
+		newPreferenceRoutes(h, d.Dietary, l)
+		newFileRoutes(h, d.Jobs, l)
+		newNotificationRoutes(h, d.Notification, l)
+		newStreamRoutes(h, d.Realtime, d.Sessions, d.Tokens, l)
 	}
 }
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type sessionRoutes struct {
+	s usecase.Sessions
+	l logger.Interface
+}
+
+func newSessionRoutes(handler *gin.RouterGroup, s usecase.Sessions, l logger.Interface) {
+	r := &sessionRoutes{s, l}
+
+	h := handler.Group("/auth/sessions")
+	{
+		h.GET("", r.list)
+		h.DELETE("", r.revokeAll)
+		h.DELETE("/:id", r.revoke)
+	}
+}
+
+// @Summary     List sessions
+// @Description Devices the user is signed in on, most recently used first.
+// @ID          sessions-list
+// @Tags        auth
+// @Produce     json
+// @Success     200 {array}  entity.Session
+// @Failure     500 {object} response
+// @Router      /auth/sessions [get]
+func (r *sessionRoutes) list(c *gin.Context) {
+	sessions, err := r.s.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.l.Error(err, "http - v1 - sessions - list")
+		errorResponse(c, http.StatusInternalServerError, "auth service problems")
+
+		return
+	}
+
+	current := currentSessionID(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Summary     Revoke session
+// @Description Signs out one device.
+// @ID          sessions-revoke
+// @Tags        auth
+// @Produce     json
+// @Param       id path string true "Session ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /auth/sessions/{id} [delete]
+func (r *sessionRoutes) revoke(c *gin.Context) {
+	err := r.s.Revoke(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			errorResponse(c, http.StatusNotFound, "Session not found")
+		} else {
+			r.l.Error(err, "http - v1 - sessions - revoke")
+			errorResponse(c, http.StatusInternalServerError, "auth service problems")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Session revoked.",
+	})
+}
+
+// @Summary     Log out everywhere
+// @Description Signs out every device. With keep_current=true the current one stays signed in.
+// @ID          sessions-revoke-all
+// @Tags        auth
+// @Produce     json
+// @Param       keep_current query bool false "Keep the current session"
+// @Success     200 {object} models.MessageResponse
+// @Failure     500 {object} response
+// @Router      /auth/sessions [delete]
+func (r *sessionRoutes) revokeAll(c *gin.Context) {
+	var keep []string
+	if cast.ToBool(c.Query("keep_current")) {
+		keep = append(keep, currentSessionID(c))
+	}
+
+	if err := r.s.RevokeAll(c.Request.Context(), currentUserID(c), keep...); err != nil {
+		r.l.Error(err, "http - v1 - sessions - revokeAll")
+		errorResponse(c, http.StatusInternalServerError, "auth service problems")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Signed out.",
+	})
+}
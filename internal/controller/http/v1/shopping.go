@@ -0,0 +1,311 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/internal/controller/http/models"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+)
+
+type shoppingRoutes struct {
+	s usecase.Shopping
+	l logger.Interface
+}
+
+func newShoppingRoutes(handler *gin.RouterGroup, s usecase.Shopping, l logger.Interface) {
+	r := &shoppingRoutes{s, l}
+
+	h := handler.Group("/shopping-lists")
+	{
+		h.POST("", r.create)
+		h.GET("", r.list)
+		h.POST("/join", r.join)
+		h.GET("/:id", r.get)
+		h.DELETE("/:id", r.delete)
+		h.POST("/:id/recipes", r.addRecipe)
+		h.DELETE("/:id/recipes/:recipe_id", r.removeRecipe)
+		h.PUT("/:id/items/:item_id", r.checkItem)
+		h.POST("/:id/share", r.share)
+		h.DELETE("/:id/share", r.unshare)
+		h.DELETE("/:id/members/:user_id", r.removeMember)
+	}
+}
+
+// @Summary     Create shopping list
+// @ID          shopping-lists-create
+// @Tags        shopping
+// @Accept      json
+// @Produce     json
+// @Param       request body models.ShoppingListRequest true "List"
+// @Success     201 {object} entity.ShoppingList
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists [post]
+func (r *shoppingRoutes) create(c *gin.Context) {
+	var request models.ShoppingListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	list, err := r.s.Create(c.Request.Context(), currentUserID(c), request.Name)
+	if err != nil {
+		r.errorResponse(c, err, "create")
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+// @Summary     Shopping lists
+// @Description Lists the user owns or joined, without their items.
+// @ID          shopping-lists-list
+// @Tags        shopping
+// @Produce     json
+// @Success     200 {array}  entity.ShoppingList
+// @Failure     500 {object} response
+// @Router      /shopping-lists [get]
+func (r *shoppingRoutes) list(c *gin.Context) {
+	lists, err := r.s.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		r.errorResponse(c, err, "list")
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// @Summary     Get shopping list
+// @Description Items of the same ingredient are merged across recipes and grouped by aisle.
+// @ID          shopping-lists-get
+// @Tags        shopping
+// @Produce     json
+// @Param       id path string true "List ID"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id} [get]
+func (r *shoppingRoutes) get(c *gin.Context) {
+	list, err := r.s.Get(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "get")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Delete shopping list
+// @Description Only the owner can delete a list.
+// @ID          shopping-lists-delete
+// @Tags        shopping
+// @Produce     json
+// @Param       id path string true "List ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id} [delete]
+func (r *shoppingRoutes) delete(c *gin.Context) {
+	if err := r.s.Delete(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		r.errorResponse(c, err, "delete")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Shopping list deleted",
+	})
+}
+
+// @Summary     Add recipe to shopping list
+// @Description Adds the recipe's ingredients scaled to servings. Adding a recipe again replaces it.
+// @ID          shopping-lists-add-recipe
+// @Tags        shopping
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                       true "List ID"
+// @Param       request body models.ShoppingRecipeRequest true "Recipe"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     409 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/recipes [post]
+func (r *shoppingRoutes) addRecipe(c *gin.Context) {
+	var request models.ShoppingRecipeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	list, err := r.s.AddRecipe(c.Request.Context(), currentUserID(c), c.Param("id"), request.RecipeID, request.Servings)
+	if err != nil {
+		r.errorResponse(c, err, "addRecipe")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Remove recipe from shopping list
+// @ID          shopping-lists-remove-recipe
+// @Tags        shopping
+// @Produce     json
+// @Param       id        path string true "List ID"
+// @Param       recipe_id path string true "Recipe ID"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/recipes/{recipe_id} [delete]
+func (r *shoppingRoutes) removeRecipe(c *gin.Context) {
+	list, err := r.s.RemoveRecipe(c.Request.Context(), currentUserID(c), c.Param("id"), c.Param("recipe_id"))
+	if err != nil {
+		r.errorResponse(c, err, "removeRecipe")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Check shopping list item
+// @ID          shopping-lists-check-item
+// @Tags        shopping
+// @Accept      json
+// @Produce     json
+// @Param       id      path string                     true "List ID"
+// @Param       item_id path string                     true "Item ID"
+// @Param       request body models.ShoppingItemRequest true "State"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/items/{item_id} [put]
+func (r *shoppingRoutes) checkItem(c *gin.Context) {
+	var request models.ShoppingItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	list, err := r.s.CheckItem(c.Request.Context(), currentUserID(c), c.Param("id"), c.Param("item_id"), request.Checked)
+	if err != nil {
+		r.errorResponse(c, err, "checkItem")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Share shopping list
+// @Description Issues a new share token for the household link, revoking the previous one.
+// @ID          shopping-lists-share
+// @Tags        shopping
+// @Produce     json
+// @Param       id path string true "List ID"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/share [post]
+func (r *shoppingRoutes) share(c *gin.Context) {
+	list, err := r.s.Share(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "share")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Stop sharing shopping list
+// @Description Revokes the share link. Members who already joined stay.
+// @ID          shopping-lists-unshare
+// @Tags        shopping
+// @Produce     json
+// @Param       id path string true "List ID"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/share [delete]
+func (r *shoppingRoutes) unshare(c *gin.Context) {
+	list, err := r.s.Unshare(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		r.errorResponse(c, err, "unshare")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Join shopping list
+// @Description Joins the list shared with the token from the share link.
+// @ID          shopping-lists-join
+// @Tags        shopping
+// @Accept      json
+// @Produce     json
+// @Param       request body models.JoinShoppingListRequest true "Share token"
+// @Success     200 {object} entity.ShoppingList
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/join [post]
+func (r *shoppingRoutes) join(c *gin.Context) {
+	var request models.JoinShoppingListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindErrorResponse(c, err)
+		return
+	}
+
+	list, err := r.s.Join(c.Request.Context(), currentUserID(c), request.Token)
+	if err != nil {
+		r.errorResponse(c, err, "join")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary     Remove shopping list member
+// @Description The owner removes a member; members remove themselves to leave.
+// @ID          shopping-lists-remove-member
+// @Tags        shopping
+// @Produce     json
+// @Param       id      path string true "List ID"
+// @Param       user_id path string true "Member ID"
+// @Success     200 {object} models.MessageResponse
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /shopping-lists/{id}/members/{user_id} [delete]
+func (r *shoppingRoutes) removeMember(c *gin.Context) {
+	if err := r.s.RemoveMember(c.Request.Context(), currentUserID(c), c.Param("id"), c.Param("user_id")); err != nil {
+		r.errorResponse(c, err, "removeMember")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Member removed",
+	})
+}
+
+func (r *shoppingRoutes) errorResponse(c *gin.Context, err error, handler string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, entity.ErrInvalidShareToken):
+		errorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrNotListOwner):
+		errorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, entity.ErrTooManyListRecipes):
+		errorResponse(c, http.StatusConflict, err.Error())
+	default:
+		r.l.Error(err, "http - v1 - shopping - "+handler)
+		errorResponse(c, http.StatusInternalServerError, "shopping list service problems")
+	}
+}
@@ -0,0 +1,303 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/logger"
+	tokens "tarkib.uz/pkg/token"
+)
+
+const (
+	_wsWriteWait    = 10 * time.Second
+	_wsPongWait     = 60 * time.Second
+	_wsPingPeriod   = (_wsPongWait * 9) / 10
+	_wsMaxMessage   = 1024
+	_sseKeepAlive   = 25 * time.Second
+	_streamTokenKey = "access_token"
+)
+
+type streamRoutes struct {
+	rt       usecase.Realtime
+	s        usecase.Sessions
+	tm       *tokens.Manager
+	l        logger.Interface
+	upgrader websocket.Upgrader
+}
+
+// wsCommand is sent by WebSocket clients to manage recipe subscriptions.
+type wsCommand struct {
+	Action   string `json:"action"`
+	RecipeID string `json:"recipe_id"`
+}
+
+type wsError struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+func newStreamRoutes(handler *gin.RouterGroup, rt usecase.Realtime, s usecase.Sessions, tm *tokens.Manager, l logger.Interface) {
+	r := &streamRoutes{
+		rt: rt,
+		s:  s,
+		tm: tm,
+		l:  l,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// CORS is open for the whole API; the token is what authenticates.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+
+	h := handler.Group("/stream")
+	{
+		h.GET("/ws", r.websocket)
+		h.GET("/sse", r.sse)
+	}
+}
+
+// hideStreamToken moves the access_token query param of stream requests into
+// the context. It runs before gin.Logger, which would otherwise write the
+// token to the access log along with the URL.
+func hideStreamToken(c *gin.Context) {
+	if !strings.HasPrefix(c.FullPath(), "/v1/stream/") {
+		return
+	}
+
+	query := c.Request.URL.Query()
+
+	token := query.Get(_streamTokenKey)
+	if token == "" {
+		return
+	}
+
+	query.Del(_streamTokenKey)
+	c.Request.URL.RawQuery = query.Encode()
+	c.Request.RequestURI = c.Request.URL.RequestURI()
+
+	c.Set(_streamTokenKey, token)
+}
+
+// authenticate validates the access token. Browsers can't set headers on
+// WebSocket or EventSource requests, so the token may also come as a query
+// param, which hideStreamToken has moved into the context.
+func (r *streamRoutes) authenticate(c *gin.Context) (string, error) {
+	token := c.GetHeader("Authorization")
+	if token == "" {
+		token = c.GetString(_streamTokenKey)
+	}
+
+	if token == "" {
+		return "", errors.New("missing access token")
+	}
+
+	claims, err := r.tm.Parse(strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return "", err
+	}
+
+	if err = r.s.Validate(c.Request.Context(), claims.Subject, claims.ID); err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+// @Summary     Event stream (WebSocket)
+// @Description Streams notifications and comments of watched recipes.
+// @Description Send {"action":"subscribe"|"unsubscribe","recipe_id":"..."} to manage recipes.
+// @Description Recipes that aren't public can only be watched by their author.
+// @ID          stream-websocket
+// @Tags        stream
+// @Param       access_token query string false "Access token if the Authorization header can't be set"
+// @Success     101
+// @Failure     401 {object} response
+// @Router      /stream/ws [get]
+func (r *streamRoutes) websocket(c *gin.Context) {
+	userID, err := r.authenticate(c)
+	if err != nil {
+		errorResponse(c, http.StatusUnauthorized, "invalid access token")
+		return
+	}
+
+	sub, err := r.rt.Subscribe(c.Request.Context(), userID)
+	if err != nil {
+		r.l.Error(err, "http - v1 - websocket - Subscribe")
+		errorResponse(c, http.StatusInternalServerError, "stream service problems")
+
+		return
+	}
+	defer sub.Close()
+
+	conn, err := r.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written the HTTP error.
+		r.l.Error(err, "http - v1 - websocket - Upgrade")
+		return
+	}
+	defer conn.Close()
+
+	commands := make(chan wsCommand)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	defer close(quit)
+
+	go r.readCommands(conn, commands, done, quit)
+
+	ticker := time.NewTicker(_wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case cmd := <-commands:
+			if err := r.applyCommand(c, sub, cmd); err != nil {
+				if !r.write(conn, wsError{Type: "error", Error: err.Error()}) {
+					return
+				}
+			}
+		case event, ok := <-sub.Events():
+			if !ok || !r.write(conn, event) {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(_wsWriteWait)) //nolint:errcheck // checked by WriteMessage
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommands is the only reader of conn; it stops when the client goes away.
+func (r *streamRoutes) readCommands(conn *websocket.Conn, commands chan<- wsCommand, done chan<- struct{}, quit <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(_wsMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(_wsPongWait)) //nolint:errcheck // checked by ReadJSON
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(_wsPongWait))
+	})
+
+	for {
+		var cmd wsCommand
+		if err := conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+
+		select {
+		case commands <- cmd:
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (r *streamRoutes) applyCommand(c *gin.Context, sub usecase.EventSubscription, cmd wsCommand) error {
+	if cmd.RecipeID == "" {
+		return errors.New("recipe_id is required")
+	}
+
+	switch cmd.Action {
+	case "subscribe":
+		return sub.WatchRecipe(c.Request.Context(), cmd.RecipeID)
+	case "unsubscribe":
+		return sub.UnwatchRecipe(c.Request.Context(), cmd.RecipeID)
+	default:
+		return errors.New("unknown action")
+	}
+}
+
+func (r *streamRoutes) write(conn *websocket.Conn, v interface{}) bool {
+	_ = conn.SetWriteDeadline(time.Now().Add(_wsWriteWait)) //nolint:errcheck // checked by WriteJSON
+
+	return conn.WriteJSON(v) == nil
+}
+
+// @Summary     Event stream (SSE)
+// @Description Server-Sent Events fallback for clients without WebSocket support.
+// @ID          stream-sse
+// @Tags        stream
+// @Produce     text/event-stream
+// @Param       access_token query string false "Access token if the Authorization header can't be set"
+// @Param       recipe_id    query []string false "Recipes to watch" collectionFormat(multi)
+// @Success     200
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Failure     404 {object} response
+// @Router      /stream/sse [get]
+func (r *streamRoutes) sse(c *gin.Context) {
+	userID, err := r.authenticate(c)
+	if err != nil {
+		errorResponse(c, http.StatusUnauthorized, "invalid access token")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	sub, err := r.rt.Subscribe(ctx, userID)
+	if err != nil {
+		r.l.Error(err, "http - v1 - sse - Subscribe")
+		errorResponse(c, http.StatusInternalServerError, "stream service problems")
+
+		return
+	}
+	defer sub.Close()
+
+	for _, recipeID := range c.QueryArray("recipe_id") {
+		if err := sub.WatchRecipe(ctx, recipeID); err != nil {
+			switch {
+			case errors.Is(err, entity.ErrTooManyWatchedRecipes):
+				errorResponse(c, http.StatusBadRequest, "Too many recipes to watch")
+			case errors.Is(err, entity.ErrNotFound):
+				errorResponse(c, http.StatusNotFound, "Recipe not found")
+			default:
+				r.l.Error(err, "http - v1 - sse - WatchRecipe")
+				errorResponse(c, http.StatusInternalServerError, "stream service problems")
+			}
+
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(_sseKeepAlive)
+	defer ticker.Stop()
+
+	// The server's WriteTimeout is meant for regular requests; push it forward
+	// before every write so the stream stays open.
+	rc := http.NewResponseController(c.Writer)
+
+	c.Stream(func(w io.Writer) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(_sseKeepAlive + _wsWriteWait)) //nolint:errcheck // unsupported writers keep the default
+
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+
+			return true
+		case <-ticker.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+
+			return err == nil
+		}
+	})
+}
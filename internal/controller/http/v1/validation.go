@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	_ "image/jpeg" // avatars may be JPEG
+	_ "image/png"  // or PNG
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"tarkib.uz/pkg/phone"
+)
+
+// Custom validation tags used in models.
+const (
+	_tagNickname    = "nickname"
+	_tagPassword    = "password"
+	_tagPhone       = "phone"
+	_tagBase64Image = "base64image"
+)
+
+// _maxAvatarBytes bounds decoded base64 avatars.
+const _maxAvatarBytes = 2 << 20
+
+var (
+	_nicknameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+	registerValidatorsOnce sync.Once
+)
+
+type fieldError struct {
+	Field   string `json:"field"   example:"nickname"`
+	Rule    string `json:"rule"    example:"min"`
+	Message string `json:"message" example:"must be at least 3 characters"`
+}
+
+// registerValidators adds the custom tags to gin's validator and makes it
+// report JSON field names.
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(jsonFieldName)
+
+		// Registration only fails for malformed tag names.
+		_ = v.RegisterValidation(_tagNickname, validNickname)       //nolint:errcheck // constant tag
+		_ = v.RegisterValidation(_tagPassword, validPassword)       //nolint:errcheck // constant tag
+		_ = v.RegisterValidation(_tagPhone, validPhone)             //nolint:errcheck // constant tag
+		_ = v.RegisterValidation(_tagBase64Image, validBase64Image) //nolint:errcheck // constant tag
+	})
+}
+
+// bindErrorResponse answers a failed ShouldBindJSON: one localized message
+// per invalid field, or a generic one when the body isn't valid JSON.
+func bindErrorResponse(c *gin.Context, err error) {
+	lang := requestLanguage(c)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		errorResponse(c, http.StatusBadRequest, translate(lang, _msgInvalidBody, ""))
+		return
+	}
+
+	fields := make([]fieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, fieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: translate(lang, fe.Tag(), fe.Param()),
+		})
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response{
+		Error:  translate(lang, _msgValidationFailed, ""),
+		Fields: fields,
+	})
+}
+
+// fieldPath is the JSON path of the field, such as "ingredients[2].name".
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+
+	return fe.Field()
+}
+
+func jsonFieldName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	if name == "" {
+		return fld.Name
+	}
+
+	return name
+}
+
+func validNickname(fl validator.FieldLevel) bool {
+	return _nicknameRegexp.MatchString(fl.Field().String())
+}
+
+// validPassword requires at least one letter and one digit; length is left
+// to min/max.
+func validPassword(fl validator.FieldLevel) bool {
+	var letter, digit bool
+
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+
+	return letter && digit
+}
+
+func validPhone(fl validator.FieldLevel) bool {
+	_, err := phone.Normalize(fl.Field().String())
+
+	return err == nil
+}
+
+// validBase64Image accepts plain base64 or a data URI holding a JPEG or PNG.
+func validBase64Image(fl validator.FieldLevel) bool {
+	encoded := fl.Field().String()
+	if i := strings.IndexByte(encoded, ','); i != -1 {
+		encoded = encoded[i+1:]
+	}
+
+	if base64.StdEncoding.DecodedLen(len(encoded)) > _maxAvatarBytes {
+		return false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	_, _, err = image.DecodeConfig(bytes.NewReader(data))
+
+	return err == nil
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func testValidator(t *testing.T) *validator.Validate {
+	t.Helper()
+
+	registerValidators()
+
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		t.Fatal("gin validator is not go-playground/validator")
+	}
+
+	return v
+}
+
+func pngBase64(t *testing.T) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestCustomValidators(t *testing.T) {
+	t.Parallel()
+
+	v := testValidator(t)
+	avatar := pngBase64(t)
+
+	tests := []struct {
+		tag   string
+		value string
+		valid bool
+	}{
+		{_tagNickname, "aziz_99", true},
+		{_tagNickname, "a.b", true},
+		{_tagNickname, "aziz-99", false},
+		{_tagNickname, "азиз", false},
+		{_tagNickname, "aziz 99", false},
+		{_tagPassword, "secret12", true},
+		{_tagPassword, "пароль1", true},
+		{_tagPassword, "12345678", false},
+		{_tagPassword, "password", false},
+		{_tagPhone, "+998 90 123-45-67", true},
+		{_tagPhone, "901234567", true},
+		{_tagPhone, "+7 912 345 67 89", false},
+		{_tagPhone, "+998 71 123 45 67", false},
+		{_tagBase64Image, avatar, true},
+		{_tagBase64Image, "data:image/png;base64," + avatar, true},
+		{_tagBase64Image, "not base64!", false},
+		{_tagBase64Image, base64.StdEncoding.EncodeToString([]byte("plain text")), false},
+		{_tagBase64Image, strings.Repeat("A", base64.StdEncoding.EncodedLen(_maxAvatarBytes+3)), false},
+	}
+
+	for _, tt := range tests {
+		err := v.Var(tt.value, tt.tag)
+		if valid := err == nil; valid != tt.valid {
+			value := tt.value
+			if len(value) > 40 {
+				value = value[:40] + "..."
+			}
+
+			t.Errorf("%s(%q) valid = %v, want %v", tt.tag, value, valid, tt.valid)
+		}
+	}
+}
+
+func TestRequestLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", _langEn},
+		{"ru-RU,ru;q=0.9,en;q=0.8", _langRu},
+		{"de-DE, uz;q=0.8, ru;q=0.5", _langUz},
+		{"UZ", _langUz},
+		{"fr-FR,fr;q=0.9", _langEn},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+		c.Request.Header.Set("Accept-Language", tt.header)
+
+		if got := requestLanguage(c); got != tt.want {
+			t.Errorf("requestLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestBindErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	testValidator(t)
+
+	var body struct {
+		NickName string `json:"nickname" binding:"required,min=3,nickname"`
+		Phone    string `json:"phone_number" binding:"required,phone"`
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"nickname": "a-", "phone_number": "123"}`))
+	c.Request.Header.Set("Accept-Language", "ru")
+
+	bindErrorResponse(c, c.ShouldBindJSON(&body))
+
+	var got response
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []fieldError{
+		{Field: "nickname", Rule: "min", Message: "должно содержать не менее 3 символов"},
+		{Field: "phone_number", Rule: _tagPhone, Message: _messages[_langRu][_tagPhone]},
+	}
+
+	if w.Code != http.StatusBadRequest || got.Error != _messages[_langRu][_msgValidationFailed] || len(got.Fields) != len(want) {
+		t.Fatalf("response = %d %+v", w.Code, got)
+	}
+
+	for i := range want {
+		if got.Fields[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, got.Fields[i], want[i])
+		}
+	}
+}
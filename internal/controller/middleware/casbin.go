@@ -1,40 +1,55 @@
 package middleware
 
 import (
-	// "fmt"
-
-	"log"
+	"errors"
 	"net/http"
+	"strings"
 
-	jWT "tarkib.uz/pkg/token"
 	"tarkib.uz/config"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
 	"tarkib.uz/pkg/logger"
+	jWT "tarkib.uz/pkg/token"
 
 	"github.com/casbin/casbin/v2"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/cast"
+)
+
+// Context keys set by the authorizer for downstream handlers.
+const (
+	CtxUserID    = "user_id"
+	CtxSessionID = "session_id"
+	CtxRole      = "role"
 )
 
 type JWTRoleAuth struct {
-	enforcer   *casbin.Enforcer
-	cfg        *config.Config
-	jwtHandler jWT.JWTHandler
+	enforcer  *casbin.Enforcer
+	cfg       *config.Config
+	tokens    *jWT.Manager
+	sessions  usecase.Sessions
+	resources map[string]usecase.ResourceAccessor
 }
 
-func NewAuthorizer(e *casbin.Enforcer, jwtHandler jWT.JWTHandler, cfg *config.Config, l logger.Interface) gin.HandlerFunc {
+// NewAuthorizer enforces the casbin policy. resources maps route prefixes
+// such as "/v1/recipes/:id" to the owner of the resource named by :id.
+func NewAuthorizer(e *casbin.Enforcer, tokens *jWT.Manager, s usecase.Sessions, resources map[string]usecase.ResourceAccessor, cfg *config.Config, l logger.Interface) gin.HandlerFunc {
 	a := &JWTRoleAuth{
-		enforcer:   e,
-		cfg:        cfg,
-		jwtHandler: jwtHandler,
+		enforcer:  e,
+		cfg:       cfg,
+		tokens:    tokens,
+		sessions:  s,
+		resources: resources,
 	}
 
 	return func(c *gin.Context) {
-		allow, err := a.CheckPermission(c.Request, l)
+		allow, err := a.CheckPermission(c, l)
 		if err != nil {
-			v, _ := err.(*jwt.ValidationError)
-			if v.Errors == jwt.ValidationErrorExpired {
+			if errors.Is(err, jWT.ErrExpired) {
 				a.RequireRefresh(c)
+			} else if errors.Is(err, entity.ErrSessionRevoked) {
+				a.RequireLogin(c)
+			} else if errors.Is(err, entity.ErrNotFound) {
+				a.NotFound(c)
 			} else {
 				a.RequirePermission(c)
 			}
@@ -44,15 +59,34 @@ func NewAuthorizer(e *casbin.Enforcer, jwtHandler jWT.JWTHandler, cfg *config.Co
 	}
 }
 
-func (a *JWTRoleAuth) CheckPermission(r *http.Request, l logger.Interface) (bool, error) {
-	user, err := a.GetRole(r)
+func (a *JWTRoleAuth) CheckPermission(c *gin.Context, l logger.Interface) (bool, error) {
+	user, claims, err := a.GetRole(c.Request)
 	if err != nil {
-		log.Println("error get role", err)
 		return false, err
 	}
 
-	method := r.Method
-	path := r.URL.Path
+	if claims != nil {
+		err = a.sessions.Validate(c.Request.Context(), claims.Subject, claims.ID)
+		if err != nil {
+			if !errors.Is(err, entity.ErrSessionRevoked) {
+				l.Error(err, "middleware - CheckPermission - sessions.Validate")
+			}
+
+			return false, err
+		}
+	}
+
+	user, err = a.resourceRole(c, user, claims)
+	if err != nil {
+		if !errors.Is(err, entity.ErrNotFound) {
+			l.Error(err, "middleware - CheckPermission - resourceRole")
+		}
+
+		return false, err
+	}
+
+	method := c.Request.Method
+	path := c.Request.URL.Path
 
 	allowed, err := a.enforcer.Enforce(user, path, method)
 	if err != nil {
@@ -60,38 +94,59 @@ func (a *JWTRoleAuth) CheckPermission(r *http.Request, l logger.Interface) (bool
 		return false, err
 	}
 
+	if allowed && claims != nil {
+		c.Set(CtxUserID, claims.Subject)
+		c.Set(CtxSessionID, claims.ID)
+		c.Set(CtxRole, user)
+	}
+
 	return allowed, nil
 }
 
-func (a *JWTRoleAuth) GetRole(r *http.Request) (string, error) {
-	var (
-		role   string
-		claims jwt.MapClaims
-		err    error
-	)
-
-	jwtToken := r.Header.Get("Authorization")
+// GetRole returns the role of the bearer token, or "unauthorized" without one.
+func (a *JWTRoleAuth) GetRole(r *http.Request) (string, *jWT.Claims, error) {
+	jwtToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
 
 	if jwtToken == "" {
-		return "unauthorized", nil
+		return "unauthorized", nil, nil
 	}
 
-	a.jwtHandler.Token = jwtToken
-
-	claims, err = a.jwtHandler.ExtractClaims()
-
+	claims, err := a.tokens.Parse(jwtToken)
 	if err != nil {
-		log.Println("error chack token", err)
-		return "", err
+		return "", nil, err
+	}
+
+	switch claims.Role {
+	case "owner", "user", "moderator", "admin", "unauthorized":
+		return claims.Role, claims, nil
+	default:
+		return "unknown", claims, nil
 	}
-	if cast.ToString(claims["role"]) == "owner" {
-		role = "owner"
-	} else if cast.ToString(claims["role"]) == "user" {
-		role = "user"
-	} else if cast.ToString(claims["role"]) == "unauthorized" {
-		role = "unauthorized"
-	} else {
-		role = "unknown"
+}
+
+// resourceRole makes the caller "owner" on routes of resources they own.
+// Resources that aren't public are hidden from everyone else except
+// admins and moderators.
+func (a *JWTRoleAuth) resourceRole(c *gin.Context, role string, claims *jWT.Claims) (string, error) {
+	for prefix, resource := range a.resources {
+		if !strings.HasPrefix(c.FullPath(), prefix) {
+			continue
+		}
+
+		access, err := resource.Access(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			return "", err
+		}
+
+		if claims != nil && access.OwnerID == claims.Subject {
+			return entity.RoleOwner, nil
+		}
+
+		if !access.Public && role != entity.RoleAdmin && role != entity.RoleModerator {
+			return "", entity.ErrNotFound
+		}
+
+		return role, nil
 	}
 
 	return role, nil
@@ -104,6 +159,20 @@ func (a *JWTRoleAuth) RequireRefresh(c *gin.Context) {
 	c.AbortWithStatus(401)
 }
 
+// RequireLogin answers tokens whose session was revoked; refreshing won't help.
+func (a *JWTRoleAuth) RequireLogin(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": "session revoked, please log in again",
+	})
+}
+
+// NotFound answers requests for resources the caller may not know about.
+func (a *JWTRoleAuth) NotFound(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"error": "not found",
+	})
+}
+
 func (a *JWTRoleAuth) RequirePermission(c *gin.Context) {
 	c.JSON(http.StatusForbidden, gin.H{
 		"Error": "You have no access this page",
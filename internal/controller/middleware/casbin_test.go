@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	e, err := casbin.NewEnforcer("../../../config/auth.conf", "../../../config/auth.csv")
+	if err != nil {
+		t.Fatalf("NewEnforcer: %v", err)
+	}
+
+	tests := []struct {
+		sub, obj, act string
+		allowed       bool
+	}{
+		{"unauthorized", "/v1/recipes/3f6c", "GET", true},
+		{"unauthorized", "/v1/pantry", "GET", false},
+		{"user", "/v1/pantry/3f6c", "PUT", true},
+		{"user", "/v1/recipes/3f6c", "PUT", false},
+		{"user", "/v1/moderation/reports", "GET", false},
+		{"owner", "/v1/recipes/3f6c", "PUT", true},
+		// Moderators inherit what users may do, with wildcards.
+		{"moderator", "/v1/moderation/reports/3f6c/actions", "POST", true},
+		{"moderator", "/v1/pantry/3f6c", "PUT", true},
+		{"moderator", "/v1/auth/sessions/3f6c", "DELETE", true},
+		{"moderator", "/v1/recipes/3f6c", "GET", true},
+		{"moderator", "/v1/recipes/3f6c", "DELETE", false},
+		{"moderator", "/v1/admin/users", "GET", false},
+		{"admin", "/v1/admin/users", "GET", true},
+	}
+
+	for _, tt := range tests {
+		allowed, err := e.Enforce(tt.sub, tt.obj, tt.act)
+		if err != nil {
+			t.Fatalf("Enforce(%s, %s, %s): %v", tt.sub, tt.obj, tt.act, err)
+		}
+
+		if allowed != tt.allowed {
+			t.Errorf("Enforce(%s, %s, %s) = %v, want %v", tt.sub, tt.obj, tt.act, allowed, tt.allowed)
+		}
+	}
+}
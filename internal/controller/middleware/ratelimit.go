@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"tarkib.uz/config"
+	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/phone"
+	"tarkib.uz/pkg/ratelimit"
+)
+
+// Rate limit keys.
+const (
+	RateLimitByIP    = "ip"
+	RateLimitByPhone = "phone"
+	RateLimitByUser  = "user"
+)
+
+// _maxPeekBody bounds how much of a request body is read to find the phone.
+const _maxPeekBody = 1 << 20
+
+// NewRateLimiter throttles requests per route by the keys configured in
+// cfg.RateLimit.Rules. It must run after the authorizer for "user" rules.
+// Redis failures let the request through.
+func NewRateLimiter(limiter *ratelimit.Limiter, cfg *config.Config, l logger.Interface) gin.HandlerFunc {
+	rules := make(map[string][]config.RateLimitRule)
+	for _, rule := range cfg.RateLimit.Rules {
+		rules[rule.Route] = append(rules[rule.Route], rule)
+	}
+
+	return func(c *gin.Context) {
+		if !cfg.RateLimit.Enabled {
+			return
+		}
+
+		routeRules, ok := rules[c.FullPath()]
+		if !ok {
+			return
+		}
+
+		for _, rule := range routeRules {
+			subject := rateLimitSubject(c, rule.Key)
+			if subject == "" {
+				continue
+			}
+
+			key := c.FullPath() + ":" + rule.Key + ":" + subject
+			window := time.Duration(rule.Window) * time.Second
+
+			allowed, retryAfter, err := limiter.Allow(c.Request.Context(), key, rule.Limit, window)
+			if err != nil {
+				l.Error(err, "middleware - RateLimiter - limiter.Allow")
+				return
+			}
+
+			if !allowed {
+				TooManyRequests(c, retryAfter)
+				return
+			}
+		}
+	}
+}
+
+// TooManyRequests aborts with 429 and a Retry-After header in whole seconds.
+func TooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error": "too many requests, try again later",
+	})
+}
+
+func rateLimitSubject(c *gin.Context, key string) string {
+	switch key {
+	case RateLimitByIP:
+		return c.ClientIP()
+	case RateLimitByUser:
+		return c.GetString(CtxUserID)
+	case RateLimitByPhone:
+		return peekPhoneNumber(c)
+	default:
+		return ""
+	}
+}
+
+// peekPhoneNumber reads phone_number from a JSON body and puts the body back
+// for the handler. Spellings of the same number share one bucket.
+func peekPhoneNumber(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, _maxPeekBody))
+	if err != nil {
+		return ""
+	}
+
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var payload struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	if normalized, err := phone.Normalize(payload.PhoneNumber); err == nil {
+		return normalized
+	}
+
+	return payload.PhoneNumber
+}
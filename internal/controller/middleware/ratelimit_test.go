@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+
+	"tarkib.uz/config"
+	"tarkib.uz/internal/controller/middleware"
+	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/ratelimit"
+)
+
+func TestRateLimitByPhone(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	limiter := ratelimit.New(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	cfg := &config.Config{}
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Rules = []config.RateLimitRule{
+		{Route: "/v1/auth/register", Key: middleware.RateLimitByPhone, Limit: 1, Window: 60},
+	}
+
+	var bodies []string
+
+	handler := gin.New()
+	handler.POST("/v1/auth/register", middleware.NewRateLimiter(limiter, cfg, logger.New("error")), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		bodies = append(bodies, string(body))
+	})
+
+	register := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/register", strings.NewReader(body))
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	first := `{"phone_number": "+998 90 123-45-67", "nickname": "aziz"}`
+	if w := register(first); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", w.Code)
+	}
+
+	if len(bodies) != 1 || bodies[0] != first {
+		t.Errorf("handler read %q, want the whole body back", bodies)
+	}
+
+	// Another spelling of the same number shares its bucket.
+	w := register(`{"phone_number": "901234567"}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("same number status = %d, want 429", w.Code)
+	}
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After missing")
+	}
+
+	if w := register(`{"phone_number": "+998931234567"}`); w.Code != http.StatusOK {
+		t.Errorf("other number status = %d, want 200", w.Code)
+	}
+
+	// Without a phone number the rule doesn't apply.
+	if w := register(`not json`); w.Code != http.StatusOK {
+		t.Errorf("body without a phone status = %d, want 200", w.Code)
+	}
+}
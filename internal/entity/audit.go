@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// Audit actions.
+const (
+	AuditLoginSucceeded = "auth.login.succeeded"
+	AuditLoginFailed    = "auth.login.failed"
+	AuditPasswordReset  = "auth.password.reset"
+	AuditRoleChanged    = "user.role.changed"
+	AuditUserBanned     = "user.banned"
+	AuditUserUnbanned   = "user.unbanned"
+	AuditUserLoggedOut  = "user.logged_out"
+	AuditRecipeTakedown = "recipe.takedown"
+)
+
+// Actor is who performs an action, taken from the request.
+type Actor struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+// AuditChange is the value of a field before and after an action.
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditEntry is one record of the append-only audit log. ActorID is empty
+// for anonymous requests such as failed logins.
+type AuditEntry struct {
+	ID         string                 `json:"id"`
+	Action     string                 `json:"action"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Diff       map[string]AuditChange `json:"diff,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditFilter narrows the audit log query. Zero values match everything.
+type AuditFilter struct {
+	Action   string
+	ActorID  string
+	TargetID string
+	From     time.Time
+	To       time.Time
+	Limit    uint64
+	Offset   uint64
+}
+
+type AuditList struct {
+	Entries []AuditEntry `json:"entries"`
+	Limit   uint64       `json:"limit"`
+	Offset  uint64       `json:"offset"`
+}
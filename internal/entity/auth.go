@@ -1,16 +1,72 @@
 package entity
 
+import "time"
+
+// Roles carried in access tokens and matched by the casbin policy.
+const (
+	RoleUser  = "user"
+	RoleOwner = "owner"
+	RoleAdmin = "admin"
+	// RoleModerator works the moderation queue.
+	RoleModerator = "moderator"
+)
+
+// AssignableRoles can be given to users through the admin API.
+var AssignableRoles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+// ValidRole reports whether role can be assigned.
+func ValidRole(role string) bool {
+	for _, r := range AssignableRoles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserBan is set while a user is banned. A nil Until bans permanently.
+type UserBan struct {
+	Reason   string     `json:"reason"`
+	BannedAt time.Time  `json:"banned_at"`
+	Until    *time.Time `json:"until,omitempty"`
+}
+
+// Active reports whether the ban is still in force at now.
+func (b *UserBan) Active(now time.Time) bool {
+	return b != nil && (b.Until == nil || now.Before(*b.Until))
+}
+
+// UserFilter narrows the admin user search. PhoneNumber matches any part of
+// the number, NickName a case-insensitive prefix.
+type UserFilter struct {
+	PhoneNumber string
+	NickName    string
+	Limit       uint64
+	Offset      uint64
+}
+
 type User struct {
-	ID          string `json:"id"`
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	PhoneNumber string `json:"phone_number"`
-	NickName    string `json:"nickname"`
-	Password    string `json:"password"`
-	Avatar      string `json:"avatar"`
-	AccessToken string `json:"access_token"`
+	ID          string   `json:"id"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	PhoneNumber string   `json:"phone_number"`
+	NickName    string   `json:"nickname"`
+	Password    string   `json:"password"`
+	Avatar      string   `json:"avatar"`
+	Role        string   `json:"role"`
+	Ban         *UserBan `json:"ban,omitempty"`
+	AccessToken string   `json:"access_token"`
 }
 
+// Registration states. A pending registration moves to verified once the
+// code matches and is removed after the user row is committed.
+const (
+	RegistrationPending  = "pending"
+	RegistrationVerified = "verified"
+)
+
+// UserForRedis is a pending registration. Password holds the bcrypt hash.
 type UserForRedis struct {
 	ID          string
 	FirstName   string
@@ -20,11 +76,28 @@ type UserForRedis struct {
 	Password    string
 	Avatar      string
 	Code        string
+	State       string
+	Attempts    int
+}
+
+// PasswordReset is a requested reset kept until the code is entered.
+type PasswordReset struct {
+	UserID   string
+	Code     string
+	Attempts int
+}
+
+// PasswordResetToken is handed out for a correct reset code and sets a new
+// password once.
+type PasswordResetToken struct {
+	Token     string `json:"reset_token"`
+	ExpiresIn int    `json:"expires_in"`
 }
 
 type VerifyUser struct {
 	PhoneNumber string
 	Code        string
+	Client      ClientInfo
 }
 
 type VerifyUserResponse struct {
@@ -49,9 +122,10 @@ type LoginUser struct {
 }
 
 type LoginRequest struct {
-	NickName    string `json:"nickname"`
-	PhoneNumber string `json:"phone_number"`
-	Password    string `json:"password"`
+	NickName    string     `json:"nickname"`
+	PhoneNumber string     `json:"phone_number"`
+	Password    string     `json:"password"`
+	Client      ClientInfo `json:"-"`
 }
 
 type LoginResponse struct {
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Challenge is a proof-of-work puzzle a client solves before the API sends
+// an SMS on a request that looks automated.
+type Challenge struct {
+	ID         string    `json:"id"`
+	Seed       string    `json:"seed"`
+	Difficulty int       `json:"difficulty"`
+	Algorithm  string    `json:"algorithm"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ChallengeSolution is what the client sent back: a solved challenge, a
+// captcha token, or nothing. ClientIP feeds the risk signals.
+type ChallengeSolution struct {
+	ChallengeID  string
+	Nonce        string
+	CaptchaToken string
+	ClientIP     string
+}
@@ -0,0 +1,191 @@
+package entity
+
+import "time"
+
+// Cook session states.
+const (
+	CookActive    = "active"
+	CookCompleted = "completed"
+	CookAbandoned = "abandoned"
+)
+
+// CookSession is a run through a recipe in cook mode. It is kept in Redis
+// and shared by all devices of the user; every change is pushed to them as
+// an EventCookSession. RecipeVersion pins the revision being cooked so
+// edits by the author don't move the steps under the cook.
+type CookSession struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	RecipeID      string `json:"recipe_id"`
+	RecipeVersion int    `json:"recipe_version"`
+	Status        string `json:"status"`
+	// CurrentStep is the number of the step on screen; 0 is the
+	// ingredient overview before the first step.
+	CurrentStep        int         `json:"current_step"`
+	Steps              int         `json:"steps"`
+	Timers             []CookTimer `json:"timers"`
+	CheckedIngredients []string    `json:"checked_ingredients"`
+	StartedAt          time.Time   `json:"started_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	CompletedAt        *time.Time  `json:"completed_at,omitempty"`
+}
+
+// CookTimer is a running step timer. Clients count down to EndsAt so all
+// devices ring together.
+type CookTimer struct {
+	Step      int       `json:"step"`
+	Label     string    `json:"label,omitempty"`
+	Duration  int       `json:"duration"`
+	StartedAt time.Time `json:"started_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+// CookProgress is a change to a session. Changes are applied on top of the
+// stored session, so devices sending them at once don't undo each other.
+type CookProgress struct {
+	CurrentStep *int
+	StartTimers []int
+	StopTimers  []int
+	Check       []string
+	Uncheck     []string
+}
+
+// RecipeCook records that a user cooked a recipe. It feeds the recipe's
+// cook count and, later, who may rate it.
+type RecipeCook struct {
+	SessionID     string    `json:"session_id"`
+	RecipeID      string    `json:"recipe_id"`
+	RecipeVersion int       `json:"recipe_version"`
+	UserID        string    `json:"user_id"`
+	CookedAt      time.Time `json:"cooked_at"`
+}
+
+// CountSteps returns the number of step sections.
+func CountSteps(sections []Section) int {
+	count := 0
+
+	for i := range sections {
+		if sections[i].Type == SectionStep {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Apply applies p to the session. revision is the recipe content the
+// session cooks; steps are looked up by number, so sections must have gone
+// through NumberSteps. Starting a running timer leaves it alone, so two
+// devices starting the same timer don't reset it.
+func (s *CookSession) Apply(p CookProgress, revision *RecipeRevision, now time.Time) error {
+	steps := make(map[int]*Step, s.Steps)
+	for i := range revision.Sections {
+		if revision.Sections[i].Type == SectionStep && revision.Sections[i].Step != nil {
+			steps[revision.Sections[i].Step.Number] = revision.Sections[i].Step
+		}
+	}
+
+	if p.CurrentStep != nil {
+		if *p.CurrentStep < 0 || *p.CurrentStep > s.Steps {
+			return ErrInvalidStep
+		}
+
+		s.CurrentStep = *p.CurrentStep
+	}
+
+	for _, number := range p.StopTimers {
+		s.stopTimer(number)
+	}
+
+	for _, number := range p.StartTimers {
+		step, ok := steps[number]
+		if !ok || step.Timer == nil {
+			return ErrInvalidStep
+		}
+
+		if s.timerRunning(number, now) {
+			continue
+		}
+
+		s.stopTimer(number)
+		s.Timers = append(s.Timers, CookTimer{
+			Step:      number,
+			Label:     step.Timer.Label,
+			Duration:  step.Timer.Duration,
+			StartedAt: now,
+			EndsAt:    now.Add(time.Duration(step.Timer.Duration) * time.Second),
+		})
+	}
+
+	for _, id := range p.Check {
+		if !hasIngredient(revision.Ingredients, id) {
+			return ErrUnknownIngredient
+		}
+
+		if !containsString(s.CheckedIngredients, id) {
+			s.CheckedIngredients = append(s.CheckedIngredients, id)
+		}
+	}
+
+	for _, id := range p.Uncheck {
+		s.CheckedIngredients = removeString(s.CheckedIngredients, id)
+	}
+
+	s.UpdatedAt = now
+
+	return nil
+}
+
+func (s *CookSession) timerRunning(step int, now time.Time) bool {
+	for _, timer := range s.Timers {
+		if timer.Step == step && timer.EndsAt.After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *CookSession) stopTimer(step int) {
+	timers := s.Timers[:0]
+
+	for _, timer := range s.Timers {
+		if timer.Step != step {
+			timers = append(timers, timer)
+		}
+	}
+
+	s.Timers = timers
+}
+
+func hasIngredient(ingredients []Ingredient, id string) bool {
+	for i := range ingredients {
+		if ingredients[i].ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	result := values[:0]
+
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
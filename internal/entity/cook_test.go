@@ -0,0 +1,113 @@
+package entity_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tarkib.uz/internal/entity"
+)
+
+func cookRevision() *entity.RecipeRevision {
+	sections := []entity.Section{
+		{Type: entity.SectionText, Content: "Intro"},
+		{Type: entity.SectionStep, Content: "Boil the water"},
+		{Type: entity.SectionStep, Content: "Cook the pasta", Step: &entity.Step{
+			Timer: &entity.Timer{Duration: 600, Label: "pasta"},
+		}},
+	}
+	entity.NumberSteps(sections)
+
+	return &entity.RecipeRevision{
+		Ingredients: []entity.Ingredient{
+			{ID: "pasta", Name: "pasta"},
+			{ID: "salt", Name: "salt"},
+		},
+		Sections: sections,
+	}
+}
+
+func TestCookSessionApply(t *testing.T) {
+	t.Parallel()
+
+	revision := cookRevision()
+	session := entity.CookSession{Steps: entity.CountSteps(revision.Sections)}
+	now := time.Date(2024, 7, 30, 12, 0, 0, 0, time.UTC)
+	step := 2
+
+	err := session.Apply(entity.CookProgress{
+		CurrentStep: &step,
+		StartTimers: []int{2},
+		Check:       []string{"pasta", "salt", "pasta"},
+	}, revision, now)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if session.CurrentStep != 2 {
+		t.Errorf("CurrentStep = %d, want 2", session.CurrentStep)
+	}
+
+	if len(session.Timers) != 1 || !session.Timers[0].EndsAt.Equal(now.Add(10*time.Minute)) {
+		t.Fatalf("Timers = %+v, want one ending in 10 minutes", session.Timers)
+	}
+
+	if len(session.CheckedIngredients) != 2 {
+		t.Errorf("CheckedIngredients = %v, want pasta and salt once", session.CheckedIngredients)
+	}
+
+	// A second device starting the same timer must not reset it.
+	if err = session.Apply(entity.CookProgress{StartTimers: []int{2}}, revision, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if !session.Timers[0].StartedAt.Equal(now) {
+		t.Errorf("running timer restarted at %v", session.Timers[0].StartedAt)
+	}
+
+	err = session.Apply(entity.CookProgress{
+		StopTimers: []int{2},
+		Uncheck:    []string{"salt"},
+	}, revision, now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if len(session.Timers) != 0 {
+		t.Errorf("Timers = %+v, want none", session.Timers)
+	}
+
+	if len(session.CheckedIngredients) != 1 || session.CheckedIngredients[0] != "pasta" {
+		t.Errorf("CheckedIngredients = %v, want [pasta]", session.CheckedIngredients)
+	}
+}
+
+func TestCookSessionApplyInvalid(t *testing.T) {
+	t.Parallel()
+
+	revision := cookRevision()
+	tooFar := 3
+
+	tests := []struct {
+		name     string
+		progress entity.CookProgress
+		want     error
+	}{
+		{"step out of range", entity.CookProgress{CurrentStep: &tooFar}, entity.ErrInvalidStep},
+		{"step without timer", entity.CookProgress{StartTimers: []int{1}}, entity.ErrInvalidStep},
+		{"unknown ingredient", entity.CookProgress{Check: []string{"sugar"}}, entity.ErrUnknownIngredient},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			session := entity.CookSession{Steps: entity.CountSteps(revision.Sections)}
+			if err := session.Apply(tt.progress, revision, time.Now()); !errors.Is(err, tt.want) {
+				t.Errorf("Apply() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
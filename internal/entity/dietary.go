@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// Allergens detected from the ingredient catalog.
+const (
+	AllergenNuts      = "nuts"
+	AllergenGluten    = "gluten"
+	AllergenDairy     = "dairy"
+	AllergenEggs      = "eggs"
+	AllergenSesame    = "sesame"
+	AllergenShellfish = "shellfish"
+)
+
+// Diets a recipe can comply with.
+const (
+	DietHalal      = "halal"
+	DietVegetarian = "vegetarian"
+	DietVegan      = "vegan"
+)
+
+// Kinds of dietary overrides.
+const (
+	OverrideAllergen = "allergen"
+	OverrideDiet     = "diet"
+)
+
+var (
+	Allergens = []string{AllergenNuts, AllergenGluten, AllergenDairy, AllergenEggs, AllergenSesame, AllergenShellfish}
+	Diets     = []string{DietHalal, DietVegetarian, DietVegan}
+)
+
+// DietaryOverride is the author correcting detection, e.g. declaring a
+// recipe halal because the meat is certified, or gluten-free because of a
+// substitute. Present says whether the allergen is in the recipe or the
+// recipe fits the diet.
+type DietaryOverride struct {
+	Kind    string `json:"kind"`
+	Value   string `json:"value"`
+	Present bool   `json:"present"`
+	Reason  string `json:"reason"`
+}
+
+// RecipeDietary is worked out from the catalog entries of the ingredients
+// when the recipe is saved. Allergens found in any ingredient are flagged;
+// a diet is detected only if every ingredient is in the catalog and fits
+// it. Unverified lists ingredient lines without a catalog entry. Allergens
+// and Diets are the detected values with the author's overrides applied.
+type RecipeDietary struct {
+	Allergens         []string          `json:"allergens"`
+	Diets             []string          `json:"diets"`
+	DetectedAllergens []string          `json:"detected_allergens"`
+	DetectedDiets     []string          `json:"detected_diets"`
+	Unverified        []string          `json:"unverified,omitempty"`
+	Overrides         []DietaryOverride `json:"overrides,omitempty"`
+}
+
+// DietaryPreferences are kept on the user's profile. Recipe lists leave out
+// recipes with any of Allergens and those not fitting every one of Diets.
+type DietaryPreferences struct {
+	Allergens []string  `json:"allergens"`
+	Diets     []string  `json:"diets"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ValidAllergen reports whether a is a known allergen.
+func ValidAllergen(a string) bool {
+	return containsString(Allergens, a)
+}
+
+// ValidDiet reports whether d is a known diet.
+func ValidDiet(d string) bool {
+	return containsString(Diets, d)
+}
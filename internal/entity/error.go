@@ -1 +1,164 @@
-package entity
\ No newline at end of file
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist or
+	// does not belong to the caller.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidPhoneNumber is returned for numbers that are not Uzbek mobile
+	// numbers in any common spelling.
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+	ErrNicknameTaken = errors.New("this nickname is already taken")
+	ErrPhoneTaken    = errors.New("user with this phone number already registered")
+
+	// ErrRegistrationNotFound means the code expired or was never sent.
+	ErrRegistrationNotFound = errors.New("verification code expired")
+	ErrInvalidCode          = errors.New("invalid verification code")
+	// ErrRegistrationInProgress is returned to a concurrent Verify of the
+	// same registration.
+	ErrRegistrationInProgress = errors.New("registration is already being verified")
+
+	// Password reset errors don't say whether the number is registered.
+	ErrInvalidResetCode  = errors.New("invalid or expired reset code")
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+	// ErrSessionRevoked is returned for access tokens issued before the
+	// user's sessions were revoked.
+	ErrSessionRevoked = errors.New("session revoked")
+
+	// ErrBanned is matched by BannedError.
+	ErrBanned = errors.New("account banned")
+
+	// ErrInvalidBanExpiry is returned for bans that would already be over.
+	ErrInvalidBanExpiry = errors.New("ban expiry must be in the future")
+	// ErrInvalidRole is returned when assigning a role that doesn't exist.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrSelfAction stops admins from banning or demoting themselves by
+	// accident.
+	ErrSelfAction = errors.New("action not allowed on own account")
+	// ErrForbidden is returned when moderators act on staff accounts, which
+	// only admins may ban.
+	ErrForbidden = errors.New("action not allowed on this account")
+
+	// ErrAlreadyReported is returned when the user already has an open
+	// report on the same target.
+	ErrAlreadyReported = errors.New("already reported")
+	// ErrReportClosed is returned when acting on an actioned or dismissed report.
+	ErrReportClosed = errors.New("report is already closed")
+	// ErrUnsupportedAction is returned for moderation actions that don't apply
+	// to the reported target.
+	ErrUnsupportedAction = errors.New("action not supported for this target")
+
+	// ErrVersionConflict is returned when saving over a newer version.
+	ErrVersionConflict = errors.New("recipe was changed by another save")
+	// ErrRecipeIncomplete is returned when publishing a recipe without a
+	// title, ingredients or sections, or with empty sections.
+	ErrRecipeIncomplete = errors.New("recipe needs a title, ingredients and filled sections to be published")
+	// ErrInvalidSection is matched by SectionError.
+	ErrInvalidSection = errors.New("invalid section")
+	// ErrDuplicateIngredient is returned when two ingredient lines of a
+	// recipe share an ID.
+	ErrDuplicateIngredient = errors.New("ingredient ids must be unique")
+
+	// ErrInvalidCatalog is matched by CatalogImportError.
+	ErrInvalidCatalog = errors.New("invalid catalog file")
+
+	// ErrInvalidOverride is returned for dietary overrides of unknown
+	// allergens or diets, or the same one twice.
+	ErrInvalidOverride = errors.New("invalid dietary override")
+	// ErrInvalidPreference is returned for unknown allergens or diets in
+	// dietary preferences.
+	ErrInvalidPreference = errors.New("unknown allergen or diet")
+
+	// ErrNotListOwner is returned when a member tries what only the owner
+	// of a shopping list may do.
+	ErrNotListOwner = errors.New("only the owner can do this")
+	// ErrInvalidShareToken is returned for unknown or revoked share links.
+	ErrInvalidShareToken = errors.New("invalid share link")
+	// ErrTooManyListRecipes is returned when a shopping list is full.
+	ErrTooManyListRecipes = errors.New("too many recipes on the list")
+
+	// ErrInvalidMealPlan is returned for plans with bad dates or length.
+	ErrInvalidMealPlan = errors.New("invalid meal plan")
+	// ErrInvalidMealSlot is returned for days outside the plan and unknown
+	// meals.
+	ErrInvalidMealSlot = errors.New("invalid meal slot")
+	// ErrMealPlanTemplate is returned when a date is needed from a template.
+	ErrMealPlanTemplate = errors.New("templates have no dates")
+	// ErrInvalidDateRange is returned for ranges ending before they start.
+	ErrInvalidDateRange = errors.New("invalid date range")
+	// ErrNoMeals is returned when nothing is planned in the range.
+	ErrNoMeals = errors.New("no meals planned in the range")
+
+	// ErrInvalidPantryItem is returned for pantry items without a name or
+	// linked to a missing catalog entry.
+	ErrInvalidPantryItem = errors.New("invalid pantry item")
+	// ErrPantryFull is returned when the pantry has MaxPantryItems items.
+	ErrPantryFull = errors.New("too many pantry items")
+
+	// ErrInvalidImportURL is returned for import URLs that aren't http(s)
+	// or point at an address that isn't public.
+	ErrInvalidImportURL = errors.New("invalid import URL")
+	// ErrImportFetch is returned when the page to import can't be fetched
+	// or isn't an HTML page.
+	ErrImportFetch = errors.New("recipe page could not be fetched")
+	// ErrNoRecipeFound is returned for documents without a recipe in them.
+	ErrNoRecipeFound = errors.New("no recipe found in the document")
+
+	// ErrInvalidStep is returned for cook progress pointing at a step the
+	// recipe doesn't have, or starting a timer on a step without one.
+	ErrInvalidStep = errors.New("invalid step")
+	// ErrUnknownIngredient is returned for checking off an ingredient the
+	// recipe doesn't have.
+	ErrUnknownIngredient = errors.New("unknown ingredient")
+	// ErrTooManyCookSessions limits the sessions a user can have open.
+	ErrTooManyCookSessions = errors.New("too many cook sessions")
+	// ErrCookSessionBusy is returned when a session kept changing while an
+	// update was being applied.
+	ErrCookSessionBusy = errors.New("cook session is being updated, try again")
+	// ErrInvalidStatus is returned for unknown recipe states.
+	ErrInvalidStatus = errors.New("invalid recipe status")
+
+	// ErrAccountLocked is matched by LockedError.
+	ErrAccountLocked = errors.New("account temporarily locked")
+
+	// ErrChallengeRequired means the request tripped a risk signal and came
+	// without a valid challenge solution.
+	ErrChallengeRequired = errors.New("challenge required")
+
+	// ErrTooManyWatchedRecipes limits recipe subscriptions per connection.
+	ErrTooManyWatchedRecipes = errors.New("too many watched recipes")
+)
+
+// LockedError is returned by Login while an account is locked out after
+// repeated failed attempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// BannedError is returned by Login for banned users.
+type BannedError struct {
+	Ban UserBan
+}
+
+func (e *BannedError) Error() string {
+	return ErrBanned.Error()
+}
+
+func (e *BannedError) Is(target error) bool {
+	return target == ErrBanned
+}
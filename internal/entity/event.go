@@ -0,0 +1,17 @@
+package entity
+
+import "encoding/json"
+
+// Event types pushed to connected clients.
+const (
+	EventNotification = "notification"
+	EventComment      = "comment"
+	EventCookSession  = "cook_session"
+	EventShoppingList = "shopping_list"
+)
+
+type Event struct {
+	Type     string          `json:"type"`
+	RecipeID string          `json:"recipe_id,omitempty"`
+	Data     json.RawMessage `json:"data"`
+}
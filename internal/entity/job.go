@@ -0,0 +1,34 @@
+package entity
+
+// Background job types handled by the worker.
+const (
+	JobSendSMS       = "sms.send"
+	JobRenderAvatar  = "avatar.render"
+	JobImageVariants = "image.variants"
+)
+
+// SMS types understood by AuthWebAPI.SendSMSWithAndroid.
+const (
+	SMSRegister = "register"
+	SMSVerify   = "verify" // password reset code
+)
+
+type SendSMSJob struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+	Type        string `json:"type"`
+}
+
+// RenderAvatarJob renders an initials avatar into Bucket/ObjectName.
+type RenderAvatarJob struct {
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Bucket     string `json:"bucket"`
+	ObjectName string `json:"object_name"`
+}
+
+// ImageVariantsJob generates resized copies of an uploaded image.
+type ImageVariantsJob struct {
+	Bucket     string `json:"bucket"`
+	ObjectName string `json:"object_name"`
+}
@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+// Meals of a plan day, in the order they are eaten.
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+)
+
+var Meals = []string{MealBreakfast, MealLunch, MealDinner}
+
+// ValidMeal reports whether m is a known meal.
+func ValidMeal(m string) bool {
+	return containsString(Meals, m)
+}
+
+// DateLayout is the layout of calendar dates in the API.
+const DateLayout = "2006-01-02"
+
+const (
+	MaxMealPlanDays = 28
+	// WeekDays is the length of a template.
+	WeekDays = 7
+)
+
+// MealPlan assigns recipes to the meals of consecutive days. Templates have
+// no dates; copying one to a start date makes a dated plan of it.
+type MealPlan struct {
+	ID       string `json:"id"`
+	OwnerID  string `json:"owner_id"`
+	Name     string `json:"name"`
+	Template bool   `json:"template"`
+	// StartDate is the date of day 0, empty for templates.
+	StartDate string `json:"start_date,omitempty"`
+	Days      int    `json:"days"`
+	// FeedToken lets calendar apps fetch the iCalendar feed without
+	// signing in.
+	FeedToken string     `json:"feed_token"`
+	Slots     []MealSlot `json:"slots,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// MealSlot is a recipe planned for a meal. Day counts from the plan's
+// start; Date is filled in for dated plans.
+type MealSlot struct {
+	Day      int    `json:"day"`
+	Date     string `json:"date,omitempty"`
+	Meal     string `json:"meal"`
+	RecipeID string `json:"recipe_id"`
+	Title    string `json:"title"`
+	Servings int    `json:"servings"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+// Report targets.
+const (
+	ReportTargetRecipe  = "recipe"
+	ReportTargetComment = "comment"
+	ReportTargetProfile = "profile"
+)
+
+// Report states. Open reports become triaged once a moderator picks them up,
+// and are closed as actioned or dismissed.
+const (
+	ReportOpen      = "open"
+	ReportTriaged   = "triaged"
+	ReportActioned  = "actioned"
+	ReportDismissed = "dismissed"
+)
+
+// Moderation actions. Warn and ban apply to the author of the target.
+const (
+	ModerationHide   = "hide"
+	ModerationDelete = "delete"
+	ModerationWarn   = "warn"
+	ModerationBan    = "ban"
+)
+
+type Report struct {
+	ID          string     `json:"id"`
+	ReporterID  string     `json:"reporter_id"`
+	TargetType  string     `json:"target_type"`
+	TargetID    string     `json:"target_id"`
+	Reason      string     `json:"reason"`
+	Details     string     `json:"details,omitempty"`
+	Status      string     `json:"status"`
+	ModeratorID string     `json:"moderator_id,omitempty"`
+	Action      string     `json:"action,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReportFilter narrows the moderation queue. Zero values match everything.
+type ReportFilter struct {
+	Status     string
+	TargetType string
+	Limit      uint64
+	Offset     uint64
+}
+
+type ReportList struct {
+	Reports []Report `json:"reports"`
+	Limit   uint64   `json:"limit"`
+	Offset  uint64   `json:"offset"`
+}
+
+// ModerationDecision closes a report. Ban fields are used by ModerationBan.
+type ModerationDecision struct {
+	Action   string
+	Note     string
+	BanUntil *time.Time
+}
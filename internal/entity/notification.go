@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Notification types produced by other use cases.
+const (
+	NotificationFollow  = "follow"
+	NotificationComment = "comment"
+	NotificationRating  = "rating"
+	NotificationReply   = "reply"
+	// NotificationReportResolved tells a reporter how their report ended.
+	NotificationReportResolved = "report_resolved"
+	// NotificationWarning is a moderator's warning about reported content.
+	NotificationWarning = "warning"
+	// NotificationPantryExpiry lists pantry items about to expire.
+	NotificationPantryExpiry = "pantry_expiry"
+)
+
+type Notification struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ActorID   string    `json:"actor_id"`
+	Type      string    `json:"type"`
+	EntityID  string    `json:"entity_id,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	IsRead    bool      `json:"is_read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	Limit         uint64         `json:"limit"`
+	Offset        uint64         `json:"offset"`
+}
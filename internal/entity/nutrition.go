@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Nutrition holds energy in kcal and macronutrients in grams.
+type Nutrition struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Fat           float64 `json:"fat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fiber         float64 `json:"fiber"`
+}
+
+func (n Nutrition) Add(o Nutrition) Nutrition {
+	return Nutrition{
+		Calories:      n.Calories + o.Calories,
+		Protein:       n.Protein + o.Protein,
+		Fat:           n.Fat + o.Fat,
+		Carbohydrates: n.Carbohydrates + o.Carbohydrates,
+		Fiber:         n.Fiber + o.Fiber,
+	}
+}
+
+func (n Nutrition) Scale(factor float64) Nutrition {
+	return Nutrition{
+		Calories:      n.Calories * factor,
+		Protein:       n.Protein * factor,
+		Fat:           n.Fat * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+		Fiber:         n.Fiber * factor,
+	}
+}
+
+// CatalogIngredient is an entry of the ingredient catalog. Recipe lines
+// are matched to it by CatalogID or by name and Aliases, case-insensitive.
+// Density (g/ml) and PieceWeight (g) let volumes and counts be weighed.
+// Allergens are those the ingredient contains; Diets those it fits. Aisle
+// groups it on shopping lists.
+type CatalogIngredient struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Aliases     []string  `json:"aliases"`
+	Aisle       string    `json:"aisle"`
+	Per100g     Nutrition `json:"per_100g"`
+	Allergens   []string  `json:"allergens"`
+	Diets       []string  `json:"diets"`
+	Density     *float64  `json:"density,omitempty"`
+	PieceWeight *float64  `json:"piece_weight,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CatalogFilter struct {
+	Search string
+	Limit  uint64
+	Offset uint64
+}
+
+type CatalogList struct {
+	Ingredients []CatalogIngredient `json:"ingredients"`
+	Limit       uint64              `json:"limit"`
+	Offset      uint64              `json:"offset"`
+}
+
+// CatalogImport counts the entries a CSV import created and updated.
+type CatalogImport struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// CatalogImportError points at the CSV line that failed an import.
+type CatalogImportError struct {
+	Line   int
+	Reason string
+}
+
+func (e *CatalogImportError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+func (e *CatalogImportError) Is(target error) bool {
+	return target == ErrInvalidCatalog
+}
+
+// RecipeNutrition is computed from the ingredients when the recipe is saved.
+// Missing lists ingredient lines that couldn't be weighed or have no
+// catalog entry; they count as zero. Estimated is set when lines are missing
+// or a volume was weighed without a known density.
+type RecipeNutrition struct {
+	Total      Nutrition  `json:"total"`
+	PerServing *Nutrition `json:"per_serving,omitempty"`
+	Estimated  bool       `json:"estimated"`
+	Missing    []string   `json:"missing,omitempty"`
+	ComputedAt time.Time  `json:"computed_at"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain events written to the outbox.
+const (
+	EventUserCreated = "user.created"
+)
+
+// OutboxEvent is stored in the same transaction as the domain change and
+// relayed to the broker afterwards. Type doubles as the worker job type.
+type OutboxEvent struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type UserCreatedEvent struct {
+	UserID      string `json:"user_id"`
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	NickName    string `json:"nickname"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+const (
+	MaxPantryItems = 500
+	// MaxMissingIngredients is the most missing ingredients a cookable
+	// recipe may have.
+	MaxMissingIngredients = 2
+)
+
+// SystemActorID is the actor of notifications nobody triggered, such as
+// reminders.
+const SystemActorID = "00000000-0000-0000-0000-000000000000"
+
+// PantryItem is an ingredient the user has at home. A zero Quantity means
+// "some". ExpiresOn is a date in DateLayout; expired items don't count
+// when matching recipes.
+type PantryItem struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Name      string    `json:"name"`
+	CatalogID string    `json:"catalog_id,omitempty"`
+	Quantity  float64   `json:"quantity,omitempty"`
+	Unit      string    `json:"unit,omitempty"`
+	ExpiresOn string    `json:"expires_on,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Expired reports whether the item expired before today.
+func (p *PantryItem) Expired(today string) bool {
+	return p.ExpiresOn != "" && p.ExpiresOn < today
+}
+
+// RecipeMatch is a recipe ranked by how much of it the pantry covers.
+// Lines without a quantity, such as salt to taste, are not required.
+type RecipeMatch struct {
+	Recipe   Recipe   `json:"recipe"`
+	Coverage float64  `json:"coverage"`
+	Required int      `json:"required"`
+	Have     int      `json:"have"`
+	Missing  []string `json:"missing"`
+}
+
+type CookableFilter struct {
+	MaxMissing int
+	Limit      uint64
+}
@@ -1,11 +1,102 @@
 package entity
 
-type Section struct {
-	Type    string `json:"type"`
-	Content string `json:"content,omitempty"`
-	URL     string `json:"url,omitempty"`
+import "time"
+
+// Recipe states. Drafts and archived recipes are seen only by their author;
+// unlisted ones by anyone with the link; published ones are also listed and
+// searchable. A draft with PublishAt set is published by the scheduler.
+const (
+	RecipeDraft     = "draft"
+	RecipePublished = "published"
+	RecipeUnlisted  = "unlisted"
+	RecipeArchived  = "archived"
+)
+
+// Ingredient is one line of a recipe's ingredient list. ID is unique within
+// the recipe and lets steps refer to the line; clients may choose it so new
+// lines can be referenced in the same save.
+type Ingredient struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	// CatalogID links the line to the ingredient catalog; without it the
+	// line is matched by name.
+	CatalogID string  `json:"catalog_id,omitempty"`
+	Quantity  float64 `json:"quantity,omitempty"`
+	Unit      string  `json:"unit,omitempty"`
+	Note      string  `json:"note,omitempty"`
 }
 
 type Recipe struct {
-	Sections []Section `json:"sections"`
+	ID          string       `json:"id"`
+	AuthorID    string       `json:"author_id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Servings    int          `json:"servings,omitempty"`
+	Ingredients []Ingredient `json:"ingredients"`
+	Sections    []Section    `json:"sections"`
+	Status      string       `json:"status"`
+	// Version is bumped by every save; saves with a stale version fail, so
+	// autosave from two devices can't silently overwrite each other.
+	Version     int              `json:"version"`
+	Hidden      bool             `json:"hidden,omitempty"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty"`
+	PublishedAt *time.Time       `json:"published_at,omitempty"`
+	Nutrition   *RecipeNutrition `json:"nutrition,omitempty"`
+	Dietary     *RecipeDietary   `json:"dietary,omitempty"`
+	// CookCount is how many times the recipe was cooked in cook mode.
+	CookCount int       `json:"cook_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Public reports whether people other than the author may open the recipe.
+func (r *Recipe) Public() bool {
+	return !r.Hidden && (r.Status == RecipePublished || r.Status == RecipeUnlisted)
+}
+
+// Complete reports whether the recipe has enough content to be published.
+func (r *Recipe) Complete() bool {
+	if r.Title == "" || len(r.Ingredients) == 0 || len(r.Sections) == 0 {
+		return false
+	}
+
+	for i := range r.Sections {
+		if !r.Sections[i].Filled() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RecipeFilter narrows recipe lists. Search matches the title. Published
+// lists also leave out recipes with any of WithoutAllergens and those not
+// fitting all of Diets; with ViewerID set, the viewer's preferences are
+// added to both.
+type RecipeFilter struct {
+	AuthorID         string
+	Status           string
+	Search           string
+	WithoutAllergens []string
+	Diets            []string
+	ViewerID         string
+	// IngredientIDs and IngredientNames list recipes using any of these
+	// catalog entries or lowercase ingredient names, those using them for
+	// most of their lines first.
+	IngredientIDs   []string
+	IngredientNames []string
+	Limit           uint64
+	Offset          uint64
+}
+
+type RecipeList struct {
+	Recipes []Recipe `json:"recipes"`
+	Limit   uint64   `json:"limit"`
+	Offset  uint64   `json:"offset"`
+}
+
+// ResourceAccess is what the authorizer needs to know about a resource.
+type ResourceAccess struct {
+	OwnerID string
+	Public  bool
 }
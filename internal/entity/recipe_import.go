@@ -0,0 +1,10 @@
+package entity
+
+// Formats of documents recipes are imported from.
+const (
+	ImportHTML     = "html"
+	ImportMarkdown = "markdown"
+)
+
+// MaxImportSize caps the size of an imported document in bytes.
+const MaxImportSize = 2 << 20
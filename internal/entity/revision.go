@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// RecipeRevision is the content of a recipe as saved at Version. Revisions
+// are never changed; restoring one saves its content as a new version.
+type RecipeRevision struct {
+	RecipeID    string       `json:"recipe_id"`
+	Version     int          `json:"version"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Servings    int          `json:"servings,omitempty"`
+	Ingredients []Ingredient `json:"ingredients,omitempty"`
+	Sections    []Section    `json:"sections,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// List change kinds.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// FieldChange is a scalar field that differs between two revisions.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ListChange is one changed element of an ordered list. OldIndex is set
+// for removed and changed elements, NewIndex for added and changed ones.
+type ListChange[T any] struct {
+	Change   string `json:"change"`
+	OldIndex *int   `json:"old_index,omitempty"`
+	NewIndex *int   `json:"new_index,omitempty"`
+	Old      *T     `json:"old,omitempty"`
+	New      *T     `json:"new,omitempty"`
+}
+
+// RecipeDiff lists what changed from revision From to revision To.
+// Unchanged fields and list elements are left out.
+type RecipeDiff struct {
+	From        int                      `json:"from"`
+	To          int                      `json:"to"`
+	Title       *FieldChange             `json:"title,omitempty"`
+	Description *FieldChange             `json:"description,omitempty"`
+	Servings    *FieldChange             `json:"servings,omitempty"`
+	Ingredients []ListChange[Ingredient] `json:"ingredients,omitempty"`
+	Sections    []ListChange[Section]    `json:"sections,omitempty"`
+}
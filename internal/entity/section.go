@@ -0,0 +1,245 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Section types.
+const (
+	SectionText  = "text"
+	SectionImage = "image"
+	SectionVideo = "video"
+	SectionStep  = "step"
+)
+
+// Step media types.
+const (
+	MediaPhoto = "photo"
+	MediaVideo = "video"
+)
+
+// Temperature units.
+const (
+	Celsius    = "C"
+	Fahrenheit = "F"
+)
+
+// Limits of a step.
+const (
+	MaxStepDuration = 24 * 60 * 60
+	maxTemperature  = 600
+)
+
+// Section is one block of a recipe's body. Text sections carry Content,
+// image and video sections a URL, and step sections Content plus Step.
+type Section struct {
+	Type    string `json:"type"`
+	Content string `json:"content,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Step    *Step  `json:"step,omitempty"`
+}
+
+// Step is a cooking step. Durations are in seconds.
+type Step struct {
+	Number      int             `json:"number"`
+	Duration    int             `json:"duration,omitempty"`
+	Timer       *Timer          `json:"timer,omitempty"`
+	Temperature *Temperature    `json:"temperature,omitempty"`
+	Media       *Media          `json:"media,omitempty"`
+	Ingredients []IngredientRef `json:"ingredients,omitempty"`
+}
+
+// Timer is offered to the cook when they reach the step.
+type Timer struct {
+	Duration int    `json:"duration"`
+	Label    string `json:"label,omitempty"`
+}
+
+type Temperature struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+type Media struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// IngredientRef points a step at a line of the recipe's ingredients.
+// Quantity and Unit are set when the step uses only part of the line.
+type IngredientRef struct {
+	IngredientID string  `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity,omitempty"`
+	Unit         string  `json:"unit,omitempty"`
+}
+
+// _legacySectionTypes maps type names found in sections saved before the
+// step model to the current ones.
+var _legacySectionTypes = map[string]string{
+	"paragraph":   SectionText,
+	"photo":       SectionImage,
+	"picture":     SectionImage,
+	"img":         SectionImage,
+	"instruction": SectionStep,
+}
+
+// UnmarshalJSON accepts sections saved before the step model: type names
+// in any case or under legacy names, and no type at all, which means an
+// image when only a URL is set and text otherwise.
+func (s *Section) UnmarshalJSON(data []byte) error {
+	type section Section
+
+	var decoded section
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	decoded.Type = strings.ToLower(strings.TrimSpace(decoded.Type))
+	if legacy, ok := _legacySectionTypes[decoded.Type]; ok {
+		decoded.Type = legacy
+	}
+
+	if decoded.Type == "" {
+		decoded.Type = SectionText
+		if decoded.Content == "" && decoded.URL != "" {
+			decoded.Type = SectionImage
+		}
+	}
+
+	*s = Section(decoded)
+
+	return nil
+}
+
+// Filled reports whether the section has the content its type needs.
+// Drafts may have empty sections; published recipes may not.
+func (s *Section) Filled() bool {
+	switch s.Type {
+	case SectionImage, SectionVideo:
+		return s.URL != ""
+	default:
+		return s.Content != ""
+	}
+}
+
+// Validate checks the section on its own; references to ingredients are
+// checked by ValidateSections.
+func (s *Section) Validate() error {
+	switch s.Type {
+	case SectionText, SectionImage, SectionVideo, SectionStep:
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+
+	if s.Step == nil {
+		return nil
+	}
+
+	if s.Type != SectionStep {
+		return errors.New("only steps have step details")
+	}
+
+	return s.Step.validate()
+}
+
+func (st *Step) validate() error {
+	if st.Duration < 0 || st.Duration > MaxStepDuration {
+		return fmt.Errorf("duration must be between 0 and %d seconds", MaxStepDuration)
+	}
+
+	if st.Timer != nil && (st.Timer.Duration <= 0 || st.Timer.Duration > MaxStepDuration) {
+		return fmt.Errorf("timer must be between 1 and %d seconds", MaxStepDuration)
+	}
+
+	if t := st.Temperature; t != nil {
+		if t.Unit != Celsius && t.Unit != Fahrenheit {
+			return errors.New("temperature unit must be C or F")
+		}
+
+		if t.Value < 0 || t.Value > maxTemperature {
+			return fmt.Errorf("temperature must be between 0 and %d", maxTemperature)
+		}
+	}
+
+	if m := st.Media; m != nil {
+		if m.Type != MediaPhoto && m.Type != MediaVideo {
+			return errors.New("media type must be photo or video")
+		}
+
+		if m.URL == "" {
+			return errors.New("media needs a url")
+		}
+	}
+
+	for _, ref := range st.Ingredients {
+		if ref.Quantity < 0 {
+			return errors.New("ingredient quantity must not be negative")
+		}
+	}
+
+	return nil
+}
+
+// SectionError names the invalid section of a recipe.
+type SectionError struct {
+	Index  int
+	Reason string
+}
+
+func (e *SectionError) Error() string {
+	return fmt.Sprintf("section %d: %s", e.Index, e.Reason)
+}
+
+func (e *SectionError) Is(target error) bool {
+	return target == ErrInvalidSection
+}
+
+// ValidateSections validates every section and that steps only refer to
+// ingredient lines of the recipe.
+func ValidateSections(sections []Section, ingredients []Ingredient) error {
+	ids := make(map[string]struct{}, len(ingredients))
+	for _, i := range ingredients {
+		ids[i.ID] = struct{}{}
+	}
+
+	for n := range sections {
+		if err := sections[n].Validate(); err != nil {
+			return &SectionError{Index: n, Reason: err.Error()}
+		}
+
+		if sections[n].Step == nil {
+			continue
+		}
+
+		for _, ref := range sections[n].Step.Ingredients {
+			if _, ok := ids[ref.IngredientID]; !ok || ref.IngredientID == "" {
+				return &SectionError{Index: n, Reason: fmt.Sprintf("unknown ingredient %q", ref.IngredientID)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// NumberSteps gives step sections consecutive numbers starting at 1, in
+// the order they appear, and adds step details to steps without them.
+func NumberSteps(sections []Section) {
+	number := 0
+
+	for n := range sections {
+		if sections[n].Type != SectionStep {
+			continue
+		}
+
+		number++
+
+		if sections[n].Step == nil {
+			sections[n].Step = &Step{}
+		}
+
+		sections[n].Step.Number = number
+	}
+}
@@ -0,0 +1,99 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+)
+
+func TestSectionLegacyDecoding(t *testing.T) {
+	t.Parallel()
+
+	const legacy = `[
+		{"type": "Text", "content": "Intro"},
+		{"type": "photo", "url": "https://cdn/1.jpg"},
+		{"url": "https://cdn/2.jpg"},
+		{"content": "No type"},
+		{"type": "instruction", "content": "Fry the onions"}
+	]`
+
+	var sections []entity.Section
+	if err := json.Unmarshal([]byte(legacy), &sections); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := []string{entity.SectionText, entity.SectionImage, entity.SectionImage, entity.SectionText, entity.SectionStep}
+	for i, s := range sections {
+		if s.Type != want[i] {
+			t.Errorf("section %d type = %q, want %q", i, s.Type, want[i])
+		}
+	}
+
+	entity.NumberSteps(sections)
+
+	if step := sections[4].Step; step == nil || step.Number != 1 {
+		t.Errorf("legacy step = %+v, want number 1", step)
+	}
+}
+
+func TestValidateSections(t *testing.T) {
+	t.Parallel()
+
+	ingredients := []entity.Ingredient{{ID: "carrot", Name: "carrot", Quantity: 500, Unit: "g"}}
+
+	tests := []struct {
+		name    string
+		section entity.Section
+		valid   bool
+	}{
+		{
+			name: "step with timer and reference",
+			section: entity.Section{Type: entity.SectionStep, Content: "Add carrots", Step: &entity.Step{
+				Duration:    600,
+				Timer:       &entity.Timer{Duration: 300},
+				Temperature: &entity.Temperature{Value: 180, Unit: entity.Celsius},
+				Media:       &entity.Media{Type: entity.MediaPhoto, URL: "https://cdn/3.jpg"},
+				Ingredients: []entity.IngredientRef{{IngredientID: "carrot", Quantity: 200, Unit: "g"}},
+			}},
+			valid: true,
+		},
+		{
+			name:    "unknown ingredient",
+			section: entity.Section{Type: entity.SectionStep, Step: &entity.Step{Ingredients: []entity.IngredientRef{{IngredientID: "rice"}}}},
+		},
+		{
+			name:    "step details on text",
+			section: entity.Section{Type: entity.SectionText, Content: "Intro", Step: &entity.Step{}},
+		},
+		{
+			name:    "bad temperature unit",
+			section: entity.Section{Type: entity.SectionStep, Step: &entity.Step{Temperature: &entity.Temperature{Value: 180, Unit: "K"}}},
+		},
+		{
+			name:    "zero timer",
+			section: entity.Section{Type: entity.SectionStep, Step: &entity.Step{Timer: &entity.Timer{}}},
+		},
+		{
+			name:    "unknown type",
+			section: entity.Section{Type: "gallery"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := entity.ValidateSections([]entity.Section{tt.section}, ingredients)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateSections = %v, want nil", err)
+			}
+
+			if !tt.valid && !errors.Is(err, entity.ErrInvalidSection) {
+				t.Errorf("ValidateSections = %v, want ErrInvalidSection", err)
+			}
+		})
+	}
+}
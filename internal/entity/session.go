@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Session is one signed-in device. Its ID is the jti of the access token.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// ClientInfo describes the device a login comes from.
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
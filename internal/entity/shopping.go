@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// Store aisles shopping list items are grouped by, in walking order.
+const (
+	AisleProduce   = "produce"
+	AisleMeat      = "meat"
+	AisleDairy     = "dairy"
+	AisleBakery    = "bakery"
+	AislePantry    = "pantry"
+	AisleSpices    = "spices"
+	AisleFrozen    = "frozen"
+	AisleBeverages = "beverages"
+	AisleOther     = "other"
+)
+
+var Aisles = []string{
+	AisleProduce, AisleMeat, AisleDairy, AisleBakery, AislePantry,
+	AisleSpices, AisleFrozen, AisleBeverages, AisleOther,
+}
+
+// ValidAisle reports whether a is a known aisle.
+func ValidAisle(a string) bool {
+	return containsString(Aisles, a)
+}
+
+// ShoppingList combines the ingredients of the recipes added to it. The
+// owner and the household members who joined through the share link can
+// use it; only the owner can share or delete it.
+type ShoppingList struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id"`
+	Name    string `json:"name"`
+	// ShareToken is shown to the owner only.
+	ShareToken string               `json:"share_token,omitempty"`
+	Members    []string             `json:"members"`
+	Recipes    []ShoppingListRecipe `json:"recipes,omitempty"`
+	Aisles     []ShoppingAisle      `json:"aisles,omitempty"`
+	// CheckedItems holds the IDs of checked items.
+	CheckedItems []string  `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ShoppingListRecipe is a recipe added to a list. Entries are its
+// ingredients as they were when it was added, scaled to Servings.
+type ShoppingListRecipe struct {
+	RecipeID string          `json:"recipe_id"`
+	Title    string          `json:"title"`
+	Servings int             `json:"servings"`
+	Entries  []ShoppingEntry `json:"-"`
+	AddedAt  time.Time       `json:"added_at"`
+}
+
+// ShoppingEntry is an ingredient line resolved against the catalog. Key is
+// the catalog entry's ID or, without one, the lowercase name; lines with
+// the same key merge.
+type ShoppingEntry struct {
+	Key      string  `json:"key"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Aisle    string  `json:"aisle"`
+}
+
+type ShoppingAisle struct {
+	Aisle string         `json:"aisle"`
+	Items []ShoppingItem `json:"items"`
+}
+
+// ShoppingItem is the merged quantity of an ingredient. Its ID stays the
+// same while recipes come and go, so checks survive.
+type ShoppingItem struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Quantity float64  `json:"quantity,omitempty"`
+	Unit     string   `json:"unit,omitempty"`
+	Checked  bool     `json:"checked"`
+	Recipes  []string `json:"recipes"`
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_defaultUserSearchLimit = 20
+	_maxUserSearchLimit     = 100
+
+	_auditTargetUser     = "user"
+	_auditTargetNickName = "nickname"
+	_auditTargetPhone    = "phone_number"
+)
+
+// AdminUseCase manages users on behalf of admins. The first argument of
+// every mutating method is the acting admin's ID.
+type AdminUseCase struct {
+	repo     AdminRepo
+	sessions Sessions
+	audit    AuditHook
+}
+
+func NewAdminUseCase(r AdminRepo, s Sessions, a AuditHook) *AdminUseCase {
+	return &AdminUseCase{
+		repo:     r,
+		sessions: s,
+		audit:    a,
+	}
+}
+
+func (uc *AdminUseCase) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	if filter.Limit == 0 {
+		filter.Limit = _defaultUserSearchLimit
+	}
+
+	if filter.Limit > _maxUserSearchLimit {
+		filter.Limit = _maxUserSearchLimit
+	}
+
+	// Numbers are stored in E.164; match on digits however they were typed.
+	filter.PhoneNumber = strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, filter.PhoneNumber)
+
+	users, err := uc.repo.SearchUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range users {
+		users[i].Password = ""
+	}
+
+	return users, nil
+}
+
+// BanUser bans the user and signs them out everywhere.
+func (uc *AdminUseCase) BanUser(ctx context.Context, actorID, userID string, ban entity.UserBan) error {
+	if actorID == userID {
+		return entity.ErrSelfAction
+	}
+
+	ban.BannedAt = time.Now().UTC()
+	if ban.Until != nil && !ban.Until.After(ban.BannedAt) {
+		return entity.ErrInvalidBanExpiry
+	}
+
+	user, err := uc.repo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.repo.UpdateBan(ctx, userID, &ban); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, entity.AuditEntry{
+		Action:     entity.AuditUserBanned,
+		ActorID:    actorID,
+		TargetType: _auditTargetUser,
+		TargetID:   userID,
+		Reason:     ban.Reason,
+		Diff:       banDiff(user.Ban, &ban),
+	})
+
+	return uc.sessions.RevokeAll(ctx, userID)
+}
+
+func (uc *AdminUseCase) UnbanUser(ctx context.Context, actorID, userID string) error {
+	user, err := uc.repo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.repo.UpdateBan(ctx, userID, nil); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, entity.AuditEntry{
+		Action:     entity.AuditUserUnbanned,
+		ActorID:    actorID,
+		TargetType: _auditTargetUser,
+		TargetID:   userID,
+		Diff:       banDiff(user.Ban, nil),
+	})
+
+	return nil
+}
+
+// SetRole changes the user's role. Tokens carry the role, so existing
+// sessions are revoked and the new role applies from the next login.
+func (uc *AdminUseCase) SetRole(ctx context.Context, actorID, userID, role string) error {
+	if !entity.ValidRole(role) {
+		return entity.ErrInvalidRole
+	}
+
+	if actorID == userID {
+		return entity.ErrSelfAction
+	}
+
+	user, err := uc.repo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.repo.UpdateRole(ctx, userID, role); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, entity.AuditEntry{
+		Action:     entity.AuditRoleChanged,
+		ActorID:    actorID,
+		TargetType: _auditTargetUser,
+		TargetID:   userID,
+		Diff: map[string]entity.AuditChange{
+			"role": {Old: user.Role, New: role},
+		},
+	})
+
+	return uc.sessions.RevokeAll(ctx, userID)
+}
+
+// ForceLogout signs the user out on every device.
+func (uc *AdminUseCase) ForceLogout(ctx context.Context, actorID, userID string) error {
+	if _, err := uc.repo.GetUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := uc.sessions.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	uc.audit.Record(ctx, entity.AuditEntry{
+		Action:     entity.AuditUserLoggedOut,
+		ActorID:    actorID,
+		TargetType: _auditTargetUser,
+		TargetID:   userID,
+	})
+
+	return nil
+}
+
+func banDiff(old, new *entity.UserBan) map[string]entity.AuditChange {
+	var before, after entity.UserBan
+	if old != nil {
+		before = *old
+	}
+
+	if new != nil {
+		after = *new
+	}
+
+	return map[string]entity.AuditChange{
+		"banned":       {Old: old != nil, New: new != nil},
+		"ban_reason":   {Old: before.Reason, New: after.Reason},
+		"banned_until": {Old: before.Until, New: after.Until},
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/logger"
+)
+
+const (
+	_defaultAuditLimit = 50
+	_maxAuditLimit     = 500
+
+	_defaultAuditPurgeInterval = time.Hour
+)
+
+type actorKey struct{}
+
+// WithActor stores who is making the request so audit entries can name them.
+func WithActor(ctx context.Context, actor entity.Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFrom(ctx context.Context) entity.Actor {
+	actor, _ := ctx.Value(actorKey{}).(entity.Actor)
+	return actor
+}
+
+// AuditUseCase is the audit hook of the other use cases. Entries are never
+// changed once written, and are purged after the retention period.
+type AuditUseCase struct {
+	repo      AuditRepo
+	l         logger.Interface
+	retention time.Duration
+	purge     time.Duration
+}
+
+// NewAuditUseCase keeps entries for retention; zero keeps them forever.
+func NewAuditUseCase(r AuditRepo, l logger.Interface, retention, purge time.Duration) *AuditUseCase {
+	if purge <= 0 {
+		purge = _defaultAuditPurgeInterval
+	}
+
+	return &AuditUseCase{
+		repo:      r,
+		l:         l,
+		retention: retention,
+		purge:     purge,
+	}
+}
+
+// Record fills in the actor from ctx and stores the entry. A failed write is
+// logged and doesn't fail the audited action.
+func (uc *AuditUseCase) Record(ctx context.Context, entry entity.AuditEntry) {
+	actor := actorFrom(ctx)
+	if entry.ActorID == "" {
+		entry.ActorID = actor.UserID
+	}
+
+	entry.ID = uuid.NewString()
+	entry.IP = actor.IP
+	entry.UserAgent = actor.UserAgent
+	entry.CreatedAt = time.Now().UTC()
+
+	if err := uc.repo.Insert(ctx, &entry); err != nil {
+		uc.l.Error(err, "usecase - AuditUseCase - Record - "+entry.Action)
+	}
+}
+
+func (uc *AuditUseCase) List(ctx context.Context, filter entity.AuditFilter) (*entity.AuditList, error) {
+	if filter.Limit == 0 {
+		filter.Limit = _defaultAuditLimit
+	}
+
+	if filter.Limit > _maxAuditLimit {
+		filter.Limit = _maxAuditLimit
+	}
+
+	entries, err := uc.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AuditList{
+		Entries: entries,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// Run purges expired entries until ctx is cancelled.
+func (uc *AuditUseCase) Run(ctx context.Context) {
+	if uc.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(uc.purge)
+	defer ticker.Stop()
+
+	for {
+		if _, err := uc.repo.DeleteBefore(ctx, time.Now().Add(-uc.retention)); err != nil {
+			uc.l.Error(err, "usecase - AuditUseCase - Run - DeleteBefore")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,15 +1,9 @@
 package usecase
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"math/rand"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"time"
 
@@ -18,42 +12,62 @@ import (
 	"github.com/minio/minio-go/v7"
 	"tarkib.uz/config"
 	"tarkib.uz/internal/entity"
-	avatargenerator "tarkib.uz/pkg/avatar-generator"
 	avatar "tarkib.uz/pkg/base64-image"
 	"tarkib.uz/pkg/password"
-	tokens "tarkib.uz/pkg/token"
+	"tarkib.uz/pkg/phone"
 )
 
+const _avatarBucket = "avatars"
+
 type AuthUseCase struct {
 	repo        AuthRepo
-	webAPI      AuthWebAPI
+	jobs        JobPublisher
+	guard       BotGuard
+	sessions    Sessions
+	tokens      TokenIssuer
+	audit       AuditHook
 	cfg         *config.Config
 	RedisClient *redis.Client
 	MinioClient *minio.Client
 }
 
-func NewAuthUseCase(r AuthRepo, w AuthWebAPI, cfg *config.Config, RedisClient *redis.Client, minioClient *minio.Client) *AuthUseCase {
+func NewAuthUseCase(r AuthRepo, j JobPublisher, g BotGuard, s Sessions, t TokenIssuer, a AuditHook, cfg *config.Config, RedisClient *redis.Client, minioClient *minio.Client) *AuthUseCase {
 	return &AuthUseCase{
 		repo:        r,
-		webAPI:      w,
+		jobs:        j,
+		guard:       g,
+		sessions:    s,
+		tokens:      t,
+		audit:       a,
 		cfg:         cfg,
 		RedisClient: RedisClient,
 		MinioClient: minioClient,
 	}
 }
 
-func (uc *AuthUseCase) Register(ctx context.Context, user *entity.User) error {
-	var (
-		userForRedis entity.UserForRedis
-		imageName    string
-	)
+// Register stores a pending registration and enqueues the SMS with its code.
+// Uniqueness is checked here for a friendly error and enforced again by the
+// database when the user is created. Risky requests must pass the bot guard
+// first, before anything is looked up or sent.
+func (uc *AuthUseCase) Register(ctx context.Context, user *entity.User, solution entity.ChallengeSolution) error {
+	phoneNumber, err := normalizePhone(user.PhoneNumber)
+	if err != nil {
+		return err
+	}
+
+	user.PhoneNumber = phoneNumber
+
+	if err = uc.guard.Check(ctx, user.PhoneNumber, solution); err != nil {
+		return err
+	}
+
 	IsExist, err := uc.repo.CheckField(ctx, "nickname", user.NickName)
 	if err != nil {
 		return err
 	}
 
 	if IsExist {
-		return errors.New("this nickname is already taken")
+		return entity.ErrNicknameTaken
 	}
 
 	IsExist, err = uc.repo.CheckField(ctx, "phone_number", user.PhoneNumber)
@@ -62,259 +76,215 @@ func (uc *AuthUseCase) Register(ctx context.Context, user *entity.User) error {
 	}
 
 	if IsExist {
-		return errors.New("user with this phone number already registered")
+		return entity.ErrPhoneTaken
 	}
 
-	if user.Avatar == "" {
-		imageName = uuid.NewString() + ".png"
-		initials := avatargenerator.GetInitial(user.FirstName, user.LastName)
-		avatargenerator.CreateProfileImage(initials, imageName)
-		file, err := os.Open(imageName)
-		if err != nil {
-			return err
-		}
-
-		body := &bytes.Buffer{}
-		writer := multipart.NewWriter(body)
-
-		part, err := writer.CreateFormFile("file", imageName)
-		if err != nil {
-			fmt.Println("Error writing to form:", err)
-			return err
-		}
-
-		_, err = io.Copy(part, file)
-		if err != nil {
-			fmt.Println("Error copying file:", err)
-			return err
-		}
-
-		err = writer.Close()
-		if err != nil {
-			fmt.Println("Error closing writer:", err)
-			return err
-		}
-
-		req, err := http.NewRequest("POST", os.Getenv("FILE_UPLOAD_URL"), body)
-		if err != nil {
-			fmt.Println("Error creating request:", err)
-			return err
-		}
-		req.Header.Set("Content-Type", writer.FormDataContentType())
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			fmt.Println("Error sending request:", err)
-			return err
-		}
-		defer resp.Body.Close()
-
-		var result struct {
-			Url string `json:"url"`
-		}
-		err = json.NewDecoder(resp.Body).Decode(&result)
-		if err != nil {
-			fmt.Println("Error decoding response:", err)
-			return err
-		}
-
-		fmt.Println("Response:", result)
-		userForRedis.Avatar = result.Url
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-		temp := r.Intn(1000000)
-
-		code := fmt.Sprintf("%06d", temp)
-
-		userForRedis.ID = uuid.NewString()
-		userForRedis.FirstName = user.FirstName
-		userForRedis.LastName = user.LastName
-		userForRedis.NickName = user.NickName
-		userForRedis.Password = user.Password
-		userForRedis.PhoneNumber = user.PhoneNumber
-		userForRedis.Code = code
-
-		byteData, err := json.Marshal(userForRedis)
-		if err != nil {
-			return err
-		}
-
-		status := uc.RedisClient.Set(ctx, user.PhoneNumber, byteData, 10*time.Minute)
-		if status.Err() != nil {
-			return err
-		}
-
-		if err := uc.webAPI.SendSMSWithAndroid(ctx, user.PhoneNumber, code, "register"); err != nil {
-			return err
-		}
-
-		if err := os.Remove(imageName); err != nil {
-			return err
-		}
-
-		return nil
+	code, err := generateCode()
+	if err != nil {
+		return err
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	temp := r.Intn(1000000)
-
-	code := fmt.Sprintf("%06d", temp)
-
-	userForRedis.ID = uuid.NewString()
-	userForRedis.Avatar = user.Avatar
-	userForRedis.FirstName = user.FirstName
-	userForRedis.LastName = user.LastName
-	userForRedis.NickName = user.NickName
-	userForRedis.Password = user.Password
-	userForRedis.PhoneNumber = user.PhoneNumber
-	userForRedis.Code = code
-
-	byteData, err := json.Marshal(userForRedis)
+	hashedPassword, err := password.HashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 
-	if err := uc.webAPI.SendSMSWithAndroid(ctx, user.PhoneNumber, code, "register"); err != nil {
+	pending := entity.UserForRedis{
+		ID:          uuid.NewString(),
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: user.PhoneNumber,
+		NickName:    user.NickName,
+		Password:    hashedPassword,
+		Avatar:      user.Avatar,
+		Code:        code,
+		State:       entity.RegistrationPending,
+	}
+
+	if err = uc.savePendingRegistration(ctx, pending); err != nil {
 		return err
 	}
 
-	status := uc.RedisClient.Set(ctx, user.PhoneNumber, byteData, 10*time.Minute)
-	if status.Err() != nil {
+	err = uc.jobs.Publish(ctx, entity.JobSendSMS, entity.SendSMSJob{
+		PhoneNumber: user.PhoneNumber,
+		Code:        code,
+		Type:        entity.SMSRegister,
+	})
+	if err != nil {
+		// Without the SMS the code can never be entered.
+		uc.RedisClient.Del(ctx, registrationKey(user.PhoneNumber))
 		return err
 	}
 
 	return nil
-
 }
 
+// Verify consumes the pending registration exactly once: the first request
+// with the right code moves it to verified, creates the user and deletes it.
+// If creation fails for a retryable reason the registration goes back to
+// pending so the same code can be used again.
 func (uc *AuthUseCase) Verify(ctx context.Context, request entity.VerifyUser) (*entity.User, error) {
-	var (
-		userForRedis entity.UserForRedis
-	)
-	endpoint := os.Getenv("SERVER_IP")
-	data := uc.RedisClient.Get(ctx, request.PhoneNumber)
-	if data.Err() != nil {
-		return nil, data.Err()
-	}
+	var err error
 
-	if data.Val() == "" {
-		return nil, errors.New("verification code expired")
+	request.PhoneNumber, err = normalizePhone(request.PhoneNumber)
+	if err != nil {
+		return nil, err
 	}
 
-	err := json.Unmarshal([]byte(data.Val()), &userForRedis)
+	pending, raw, err := uc.loadRegistration(ctx, request.PhoneNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	if userForRedis.Code != request.Code {
-		return nil, errors.New("invalid verification code")
+	if pending.State != entity.RegistrationPending {
+		return nil, entity.ErrRegistrationInProgress
 	}
 
-	avatarImage := uuid.NewString() + ".png"
-	if err := avatar.SaveAvatar(userForRedis.Avatar, avatarImage, uc.MinioClient); err != nil {
-		return nil, err
+	if pending.Code != request.Code {
+		return nil, uc.rejectCode(ctx, pending, raw)
 	}
 
-	jwtHandler := tokens.JWTHandler{
-		Sub:       userForRedis.ID,
-		Iss:       time.Now().UTC().Format(time.RFC3339),
-		Exp:       time.Now().UTC().Add(time.Hour * 168).Format(time.RFC3339),
-		Role:      "user",
-		SigninKey: uc.cfg.Casbin.SigningKey,
-		Timeout:   uc.cfg.Casbin.AccessTokenTimeOut,
-	}
+	verified := pending
+	verified.State = entity.RegistrationVerified
 
-	access, _, err := jwtHandler.GenerateAuthJWT()
+	verifiedRaw, err := uc.swapRegistration(ctx, raw, verified)
 	if err != nil {
 		return nil, err
 	}
 
-	hashedPassword, err := password.HashPassword(userForRedis.Password)
+	user, err := uc.createUser(ctx, verified, request.Client)
 	if err != nil {
+		if errors.Is(err, entity.ErrPhoneTaken) || errors.Is(err, entity.ErrNicknameTaken) {
+			_ = uc.consumeRegistration(ctx, verified.PhoneNumber, verifiedRaw) //nolint:errcheck // expires anyway
+		} else {
+			_, _ = uc.swapRegistration(ctx, verifiedRaw, pending) //nolint:errcheck // expires anyway
+		}
+
 		return nil, err
 	}
 
-	_, err = uc.repo.Create(ctx, &entity.User{
-		ID:          userForRedis.ID,
-		FirstName:   userForRedis.FirstName,
-		LastName:    userForRedis.LastName,
-		PhoneNumber: userForRedis.PhoneNumber,
-		NickName:    userForRedis.NickName,
-		Password:    hashedPassword,
-		Avatar:      fmt.Sprintf("https://%s/%s/%s", endpoint, "avatars", avatarImage),
-		AccessToken: access,
-	})
-	if err != nil {
+	if err = uc.consumeRegistration(ctx, verified.PhoneNumber, verifiedRaw); err != nil {
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:          userForRedis.ID,
-		FirstName:   userForRedis.FirstName,
-		LastName:    userForRedis.LastName,
-		PhoneNumber: userForRedis.PhoneNumber,
-		NickName:    userForRedis.NickName,
-		Password:    userForRedis.Password,
-		Avatar:      fmt.Sprintf("https://%s/%s/%s", endpoint, "avatars", avatarImage),
-		AccessToken: access,
-	}, nil
+	return user, nil
 }
 
-func (uc *AuthUseCase) ForgotPassword(ctx context.Context, phoneNumber string) error {
-	IsExists, err := uc.repo.CheckField(ctx, "phone_number", phoneNumber)
+// createUser uploads the avatar and starts the first session, then commits
+// the user row with its outbox events. Both are undone if the insert fails.
+func (uc *AuthUseCase) createUser(ctx context.Context, registration entity.UserForRedis, client entity.ClientInfo) (*entity.User, error) {
+	endpoint := os.Getenv("SERVER_IP")
+
+	avatarImage := uuid.NewString() + ".png"
+	if registration.Avatar != "" {
+		if err := avatar.SaveAvatar(registration.Avatar, avatarImage, uc.MinioClient); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := userCreatedEvents(registration, avatarImage)
 	if err != nil {
-		return err
+		uc.removeAvatar(ctx, registration, avatarImage)
+		return nil, err
 	}
 
-	if IsExists {
-		return errors.New("this phone number not registered in tarkib.uz yet")
+	session, err := uc.sessions.Start(ctx, registration.ID, client)
+	if err != nil {
+		uc.removeAvatar(ctx, registration, avatarImage)
+		return nil, err
 	}
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	temp := r.Intn(1000000)
-	code := fmt.Sprintf("%06d", temp)
 
-	status := uc.RedisClient.Set(ctx, phoneNumber+"_reset", code, 10*time.Minute)
-	if status.Err() != nil {
-		return status.Err()
+	rollback := func() {
+		uc.removeAvatar(ctx, registration, avatarImage)
+		_ = uc.sessions.Revoke(ctx, registration.ID, session.ID) //nolint:errcheck // expires with the token
 	}
 
-	if err := uc.webAPI.SendSMSWithAndroid(ctx, phoneNumber, code, "forgot"); err != nil {
-		return err
+	access, err := uc.tokens.Issue(registration.ID, session.ID, entity.RoleUser)
+	if err != nil {
+		rollback()
+		return nil, err
 	}
 
-	return nil
-}
+	user := &entity.User{
+		ID:          registration.ID,
+		FirstName:   registration.FirstName,
+		LastName:    registration.LastName,
+		PhoneNumber: registration.PhoneNumber,
+		NickName:    registration.NickName,
+		Password:    registration.Password,
+		Avatar:      fmt.Sprintf("https://%s/%s/%s", endpoint, _avatarBucket, avatarImage),
+		Role:        entity.RoleUser,
+		AccessToken: access,
+	}
 
-func (uc *AuthUseCase) ResetPassword(ctx context.Context, phoneNumber, code, newPassword string) error {
-	storedCode := uc.RedisClient.Get(ctx, phoneNumber+"_reset")
-	if storedCode.Err() != nil {
-		return storedCode.Err()
+	if _, err = uc.repo.Create(ctx, user, events...); err != nil {
+		rollback()
+		return nil, err
 	}
 
-	if storedCode.Val() != code {
-		return errors.New("invalid reset code")
+	user.Password = ""
+
+	return user, nil
+}
+
+func (uc *AuthUseCase) removeAvatar(ctx context.Context, registration entity.UserForRedis, avatarImage string) {
+	if registration.Avatar == "" {
+		return
 	}
 
-	hashedPassword, err := password.HashPassword(newPassword)
+	_ = uc.MinioClient.RemoveObject(ctx, _avatarBucket, avatarImage, minio.RemoveObjectOptions{}) //nolint:errcheck // best effort cleanup
+}
+
+// userCreatedEvents are committed together with the user row, so they are
+// never lost when the broker is down. Keys derive from the user ID, so a
+// retried Verify can't enqueue them twice.
+func userCreatedEvents(u entity.UserForRedis, avatarImage string) ([]entity.OutboxEvent, error) {
+	created, err := newOutboxEvent(entity.EventUserCreated, entity.EventUserCreated+":"+u.ID, entity.UserCreatedEvent{
+		UserID:      u.ID,
+		PhoneNumber: u.PhoneNumber,
+		FirstName:   u.FirstName,
+		NickName:    u.NickName,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = uc.repo.UpdatePassword(ctx, phoneNumber, hashedPassword)
+	var avatarJob entity.OutboxEvent
+	if u.Avatar != "" {
+		avatarJob, err = newOutboxEvent(entity.JobImageVariants, entity.JobImageVariants+":"+avatarImage, entity.ImageVariantsJob{
+			Bucket:     _avatarBucket,
+			ObjectName: avatarImage,
+		})
+	} else {
+		avatarJob, err = newOutboxEvent(entity.JobRenderAvatar, entity.JobRenderAvatar+":"+u.ID, entity.RenderAvatarJob{
+			FirstName:  u.FirstName,
+			LastName:   u.LastName,
+			Bucket:     _avatarBucket,
+			ObjectName: avatarImage,
+		})
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	uc.RedisClient.Del(ctx, phoneNumber+"_reset")
+	return []entity.OutboxEvent{created, avatarJob}, nil
+}
 
-	return nil
+// normalizePhone brings every spelling of a number to the E.164 form users
+// are stored under.
+func normalizePhone(raw string) (string, error) {
+	normalized, err := phone.Normalize(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entity.ErrInvalidPhoneNumber, err)
+	}
+
+	return normalized, nil
 }
 
-func (uc *AuthUseCase) Login(ctx context.Context, req entity.LoginRequest) (*entity.LoginResponse, error) {
+func (uc *AuthUseCase) Login(ctx context.Context, req entity.LoginRequest) (response *entity.LoginResponse, err error) {
 	var user *entity.User
-	var err error
+
+	defer func() { uc.auditLogin(ctx, req, user, err) }()
 
 	if req.NickName != "" {
 		user, err = uc.repo.GetUserByNickName(ctx, req.NickName)
@@ -322,7 +292,14 @@ func (uc *AuthUseCase) Login(ctx context.Context, req entity.LoginRequest) (*ent
 			return nil, errors.New("user not found")
 		}
 	} else {
-		user, err = uc.repo.GetUserByPhoneNumber(ctx, req.PhoneNumber)
+		var phoneNumber string
+
+		phoneNumber, err = normalizePhone(req.PhoneNumber)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+
+		user, err = uc.repo.GetUserByPhoneNumber(ctx, phoneNumber)
 		if err != nil {
 			return nil, errors.New("user not found")
 		}
@@ -332,23 +309,36 @@ func (uc *AuthUseCase) Login(ctx context.Context, req entity.LoginRequest) (*ent
 		return nil, errors.New("user not found")
 	}
 
+	if err = uc.checkLockout(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
 	if !password.CheckPasswordHash(req.Password, user.Password) {
+		if err = uc.recordLoginFailure(ctx, user.ID); err != nil {
+			return nil, err
+		}
+
 		return nil, errors.New("invalid password")
 	}
 
-	expDuration := time.Duration(uc.cfg.Casbin.AccessTokenTimeOut) * time.Second
-	expTime := time.Now().Add(expDuration)
+	uc.resetLoginFailures(ctx, user.ID)
 
-	jwtHandler := tokens.JWTHandler{
-		Sub:       user.ID,
-		Iss:       time.Now().String(),
-		Exp:       expTime.String(),
-		Role:      "user",
-		SigninKey: uc.cfg.Casbin.SigningKey,
-		Timeout:   uc.cfg.Casbin.AccessTokenTimeOut,
+	// Checked after the password so the ban isn't revealed to strangers.
+	if user.Ban != nil && user.Ban.Active(time.Now()) {
+		return nil, &entity.BannedError{Ban: *user.Ban}
 	}
 
-	accessToken, _, err := jwtHandler.GenerateAuthJWT()
+	role := user.Role
+	if role == "" {
+		role = entity.RoleUser
+	}
+
+	session, err := uc.sessions.Start(ctx, user.ID, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := uc.tokens.Issue(user.ID, session.ID, role)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %v", err)
 	}
@@ -366,3 +356,37 @@ func (uc *AuthUseCase) Login(ctx context.Context, req entity.LoginRequest) (*ent
 		},
 	}, nil
 }
+
+// auditLogin records the outcome of a login. Failed attempts name the account
+// when it was found, and otherwise the nickname or phone number submitted, so
+// brute forcing shows up per target.
+func (uc *AuthUseCase) auditLogin(ctx context.Context, req entity.LoginRequest, user *entity.User, err error) {
+	entry := entity.AuditEntry{
+		Action:     entity.AuditLoginSucceeded,
+		TargetType: _auditTargetUser,
+	}
+
+	switch {
+	case user != nil:
+		entry.TargetID = user.ID
+	case req.NickName != "":
+		entry.TargetType = _auditTargetNickName
+		entry.TargetID = req.NickName
+	default:
+		entry.TargetType = _auditTargetPhone
+		entry.TargetID = req.PhoneNumber
+
+		if phoneNumber, normalizeErr := normalizePhone(req.PhoneNumber); normalizeErr == nil {
+			entry.TargetID = phoneNumber
+		}
+	}
+
+	if err != nil {
+		entry.Action = entity.AuditLoginFailed
+		entry.Reason = err.Error()
+	} else {
+		entry.ActorID = user.ID
+	}
+
+	uc.audit.Record(ctx, entry)
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"tarkib.uz/config"
+	"tarkib.uz/internal/entity"
+)
+
+const _riskPhonesPrefix = "risk:ip:phones:"
+
+// BotGuardUseCase lets ordinary requests through and asks for a solved
+// challenge once a risk signal trips: one IP trying many phone numbers.
+// Numbers outside Uzbekistan never get here; phone.Normalize rejects them.
+type BotGuardUseCase struct {
+	pow         *ProofOfWork
+	verifiers   []ChallengeVerifier
+	cfg         *config.Config
+	RedisClient *redis.Client
+}
+
+// NewBotGuardUseCase accepts proof-of-work solutions and, when captcha is not
+// nil, captcha tokens as well.
+func NewBotGuardUseCase(cfg *config.Config, RedisClient *redis.Client, captcha ChallengeVerifier) *BotGuardUseCase {
+	p := NewProofOfWork(
+		RedisClient,
+		cfg.Challenge.Difficulty,
+		time.Duration(cfg.Challenge.TTL)*time.Second,
+	)
+
+	verifiers := []ChallengeVerifier{p}
+	if captcha != nil {
+		verifiers = append(verifiers, captcha)
+	}
+
+	return &BotGuardUseCase{
+		pow:         p,
+		verifiers:   verifiers,
+		cfg:         cfg,
+		RedisClient: RedisClient,
+	}
+}
+
+func (uc *BotGuardUseCase) IssueChallenge(ctx context.Context) (*entity.Challenge, error) {
+	return uc.pow.Issue(ctx)
+}
+
+// Check returns ErrChallengeRequired when the request is risky and none of
+// the verifiers accepts its solution.
+func (uc *BotGuardUseCase) Check(ctx context.Context, phoneNumber string, solution entity.ChallengeSolution) error {
+	risky, err := uc.risky(ctx, phoneNumber, solution.ClientIP)
+	if err != nil {
+		return err
+	}
+
+	if !risky {
+		return nil
+	}
+
+	for _, v := range uc.verifiers {
+		ok, err := v.Verify(ctx, solution)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return nil
+		}
+	}
+
+	return entity.ErrChallengeRequired
+}
+
+func (uc *BotGuardUseCase) risky(ctx context.Context, phoneNumber, clientIP string) (bool, error) {
+	phones, err := uc.countPhonesForIP(ctx, clientIP, phoneNumber)
+	if err != nil {
+		return false, err
+	}
+
+	return phones > int64(uc.cfg.Challenge.PhonesPerIP), nil
+}
+
+// countPhonesForIP remembers phoneNumber for clientIP and returns how many
+// distinct numbers the IP used within the window.
+func (uc *BotGuardUseCase) countPhonesForIP(ctx context.Context, clientIP, phoneNumber string) (int64, error) {
+	if clientIP == "" {
+		return 0, nil
+	}
+
+	key := _riskPhonesPrefix + clientIP
+	window := time.Duration(uc.cfg.Challenge.PhonesWindow) * time.Second
+
+	var count *redis.IntCmd
+
+	_, err := uc.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, phoneNumber)
+		pipe.Expire(ctx, key, window)
+		count = pipe.SCard(ctx, key)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count.Val(), nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/pow"
+)
+
+const _challengePrefix = "challenge:"
+
+// ProofOfWork issues challenges and verifies their solutions. A challenge
+// can be redeemed once.
+type ProofOfWork struct {
+	RedisClient *redis.Client
+	difficulty  int
+	ttl         time.Duration
+}
+
+func NewProofOfWork(RedisClient *redis.Client, difficulty int, ttl time.Duration) *ProofOfWork {
+	return &ProofOfWork{
+		RedisClient: RedisClient,
+		difficulty:  difficulty,
+		ttl:         ttl,
+	}
+}
+
+func (p *ProofOfWork) Issue(ctx context.Context) (*entity.Challenge, error) {
+	seed := make([]byte, 16)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+
+	challenge := &entity.Challenge{
+		ID:         uuid.NewString(),
+		Seed:       hex.EncodeToString(seed),
+		Difficulty: p.difficulty,
+		Algorithm:  pow.Algorithm,
+		ExpiresAt:  time.Now().UTC().Add(p.ttl),
+	}
+
+	if err := p.RedisClient.Set(ctx, _challengePrefix+challenge.ID, challenge.Seed, p.ttl).Err(); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+func (p *ProofOfWork) Verify(ctx context.Context, solution entity.ChallengeSolution) (bool, error) {
+	if solution.ChallengeID == "" || solution.Nonce == "" {
+		return false, nil
+	}
+
+	seed, err := p.RedisClient.GetDel(ctx, _challengePrefix+solution.ChallengeID).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return pow.Valid(seed, solution.Nonce, p.difficulty), nil
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	// _cookSessionTTL drops sessions nobody touched for a day.
+	_cookSessionTTL     = 24 * time.Hour
+	_maxCookSessions    = 5
+	_cookUpdateAttempts = 5
+	_cookSessionPrefix  = "cook:session:"
+	_cookSessionsPrefix = "cook:sessions:"
+)
+
+// CookUseCase runs cook mode. Sessions live in Redis so every device of
+// the user sees the same progress; each change is also pushed to them over
+// the event stream.
+type CookUseCase struct {
+	recipes     RecipeRepo
+	repo        CookRepo
+	events      EventPublisher
+	RedisClient *redis.Client
+}
+
+func NewCookUseCase(recipes RecipeRepo, r CookRepo, e EventPublisher, RedisClient *redis.Client) *CookUseCase {
+	return &CookUseCase{
+		recipes:     recipes,
+		repo:        r,
+		events:      e,
+		RedisClient: RedisClient,
+	}
+}
+
+func cookSessionKey(userID, sessionID string) string {
+	return _cookSessionPrefix + userID + ":" + sessionID
+}
+
+// cookSessionsKey holds the IDs of the user's open sessions.
+func cookSessionsKey(userID string) string {
+	return _cookSessionsPrefix + userID
+}
+
+// Start opens a session on the current version of the recipe. The
+// authorizer has already checked the user may see it. An open session on
+// the same recipe is resumed instead, so a second device joins it.
+func (uc *CookUseCase) Start(ctx context.Context, userID, recipeID string) (*entity.CookSession, error) {
+	sessions, err := uc.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		if sessions[i].RecipeID == recipeID {
+			return &sessions[i], nil
+		}
+	}
+
+	if len(sessions) >= _maxCookSessions {
+		return nil, entity.ErrTooManyCookSessions
+	}
+
+	recipe, err := uc.recipes.Get(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := entity.CookSession{
+		ID:                 uuid.NewString(),
+		UserID:             userID,
+		RecipeID:           recipe.ID,
+		RecipeVersion:      recipe.Version,
+		Status:             entity.CookActive,
+		Steps:              entity.CountSteps(recipe.Sections),
+		Timers:             []entity.CookTimer{},
+		CheckedIngredients: []string{},
+		StartedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	byteData, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, cookSessionKey(userID, session.ID), byteData, _cookSessionTTL)
+		pipe.SAdd(ctx, cookSessionsKey(userID), session.ID)
+		pipe.Expire(ctx, cookSessionsKey(userID), _cookSessionTTL)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publish(ctx, &session)
+
+	return &session, nil
+}
+
+// List returns the user's open sessions, dropping expired ones from the index.
+func (uc *CookUseCase) List(ctx context.Context, userID string) ([]entity.CookSession, error) {
+	ids, err := uc.RedisClient.SMembers(ctx, cookSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []entity.CookSession{}
+	if len(ids) == 0 {
+		return sessions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = cookSessionKey(userID, id)
+	}
+
+	values, err := uc.RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []interface{}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var session entity.CookSession
+		if err = json.Unmarshal([]byte(raw), &session); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		_ = uc.RedisClient.SRem(ctx, cookSessionsKey(userID), expired...).Err() //nolint:errcheck // pruned again next time
+	}
+
+	return sessions, nil
+}
+
+func (uc *CookUseCase) Get(ctx context.Context, userID, sessionID string) (*entity.CookSession, error) {
+	session, _, err := uc.load(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Update applies progress from one of the user's devices. Concurrent
+// updates are merged: a lost race reloads the session and applies the
+// change again.
+func (uc *CookUseCase) Update(ctx context.Context, userID, sessionID string, progress entity.CookProgress) (*entity.CookSession, error) {
+	session, raw, err := uc.load(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := uc.recipes.GetRevision(ctx, session.RecipeID, session.RecipeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < _cookUpdateAttempts; attempt++ {
+		if err = session.Apply(progress, revision, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+
+		var swapped bool
+		if swapped, err = uc.swap(ctx, raw, session); err != nil {
+			return nil, err
+		}
+
+		if swapped {
+			uc.publish(ctx, session)
+
+			return session, nil
+		}
+
+		if session, raw, err = uc.load(ctx, userID, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, entity.ErrCookSessionBusy
+}
+
+// Complete closes the session. With cookedIt the cook is recorded for the
+// recipe's stats; the record is written first so a retry after a failure
+// still counts it once.
+func (uc *CookUseCase) Complete(ctx context.Context, userID, sessionID string, cookedIt bool) (*entity.CookSession, error) {
+	session, raw, err := uc.load(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if cookedIt {
+		err = uc.repo.RecordCook(ctx, entity.RecipeCook{
+			SessionID:     session.ID,
+			RecipeID:      session.RecipeID,
+			RecipeVersion: session.RecipeVersion,
+			UserID:        userID,
+			CookedAt:      now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.close(ctx, session, raw, entity.CookCompleted, now)
+}
+
+// Abandon closes the session without recording a cook.
+func (uc *CookUseCase) Abandon(ctx context.Context, userID, sessionID string) error {
+	session, raw, err := uc.load(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = uc.close(ctx, session, raw, entity.CookAbandoned, time.Now().UTC())
+
+	return err
+}
+
+func (uc *CookUseCase) close(ctx context.Context, session *entity.CookSession, raw, status string, now time.Time) (*entity.CookSession, error) {
+	deleted, err := _compareAndDelete.Run(ctx, uc.RedisClient, []string{cookSessionKey(session.UserID, session.ID)}, raw).Int()
+	if err != nil {
+		return nil, err
+	}
+
+	// Another device closed or changed it first; let the caller retry.
+	if deleted == 0 {
+		return nil, entity.ErrCookSessionBusy
+	}
+
+	_ = uc.RedisClient.SRem(ctx, cookSessionsKey(session.UserID), session.ID).Err() //nolint:errcheck // List prunes it
+
+	session.Status = status
+	session.UpdatedAt = now
+	session.CompletedAt = &now
+
+	uc.publish(ctx, session)
+
+	return session, nil
+}
+
+func (uc *CookUseCase) load(ctx context.Context, userID, sessionID string) (*entity.CookSession, string, error) {
+	raw, err := uc.RedisClient.Get(ctx, cookSessionKey(userID, sessionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, "", entity.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	var session entity.CookSession
+	if err = json.Unmarshal([]byte(raw), &session); err != nil {
+		return nil, "", err
+	}
+
+	return &session, raw, nil
+}
+
+// swap stores session if it still holds raw and keeps the session alive
+// for another TTL.
+func (uc *CookUseCase) swap(ctx context.Context, raw string, session *entity.CookSession) (bool, error) {
+	byteData, err := json.Marshal(session)
+	if err != nil {
+		return false, err
+	}
+
+	key := cookSessionKey(session.UserID, session.ID)
+
+	swapped, err := _compareAndSwap.Run(ctx, uc.RedisClient, []string{key}, raw, byteData).Int()
+	if err != nil || swapped == 0 {
+		return false, err
+	}
+
+	_, _ = uc.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error { //nolint:errcheck // the session is saved; TTL is refreshed next time
+		pipe.Expire(ctx, key, _cookSessionTTL)
+		pipe.Expire(ctx, cookSessionsKey(session.UserID), _cookSessionTTL)
+
+		return nil
+	})
+
+	return true, nil
+}
+
+// publish pushes the session to the cook's devices.
+func (uc *CookUseCase) publish(ctx context.Context, session *entity.CookSession) {
+	pushEvent(ctx, uc.events, entity.Event{Type: entity.EventCookSession, RecipeID: session.RecipeID}, session, session.UserID)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tarkib.uz/internal/entity"
+)
+
+const _analyzeBatch = 100
+
+// DietaryUseCase keeps users' allergen and diet preferences. Recipe lists
+// apply them through RecipeFilter.ViewerID.
+type DietaryUseCase struct {
+	repo PreferenceRepo
+}
+
+func NewDietaryUseCase(r PreferenceRepo) *DietaryUseCase {
+	return &DietaryUseCase{
+		repo: r,
+	}
+}
+
+func (uc *DietaryUseCase) Preferences(ctx context.Context, userID string) (*entity.DietaryPreferences, error) {
+	return uc.repo.Get(ctx, userID)
+}
+
+func (uc *DietaryUseCase) SetPreferences(ctx context.Context, userID string, preferences entity.DietaryPreferences) (*entity.DietaryPreferences, error) {
+	allergens := make(map[string]bool, len(preferences.Allergens))
+	for _, allergen := range preferences.Allergens {
+		if !entity.ValidAllergen(allergen) {
+			return nil, entity.ErrInvalidPreference
+		}
+
+		allergens[allergen] = true
+	}
+
+	diets := make(map[string]bool, len(preferences.Diets))
+	for _, diet := range preferences.Diets {
+		if !entity.ValidDiet(diet) {
+			return nil, entity.ErrInvalidPreference
+		}
+
+		diets[diet] = true
+	}
+
+	preferences.Allergens = ordered(entity.Allergens, allergens)
+	preferences.Diets = ordered(entity.Diets, diets)
+	preferences.UpdatedAt = time.Now().UTC()
+
+	if err := uc.repo.Save(ctx, userID, &preferences); err != nil {
+		return nil, err
+	}
+
+	return &preferences, nil
+}
+
+// SetDietaryOverrides replaces the author's overrides and works the dietary
+// flags out again.
+func (uc *RecipeUseCase) SetDietaryOverrides(ctx context.Context, userID, recipeID string, overrides []entity.DietaryOverride) (*entity.Recipe, error) {
+	recipe, err := uc.ownRecipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[entity.DietaryOverride]bool, len(overrides))
+
+	for _, o := range overrides {
+		switch {
+		case o.Kind == entity.OverrideAllergen && entity.ValidAllergen(o.Value):
+		case o.Kind == entity.OverrideDiet && entity.ValidDiet(o.Value):
+		default:
+			return nil, entity.ErrInvalidOverride
+		}
+
+		key := entity.DietaryOverride{Kind: o.Kind, Value: o.Value}
+		if seen[key] {
+			return nil, entity.ErrInvalidOverride
+		}
+
+		seen[key] = true
+	}
+
+	recipe.Dietary = &entity.RecipeDietary{Overrides: overrides}
+
+	if err = uc.analyze(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	if err = uc.repo.UpdateAnalysis(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+// AnalyzeMissing works out the nutrition and dietary flags of recipes saved
+// before they were computed. Until then allergen filters leave them out.
+func (uc *RecipeUseCase) AnalyzeMissing(ctx context.Context) error {
+	for {
+		recipes, err := uc.repo.ListUnanalyzed(ctx, _analyzeBatch)
+		if err != nil {
+			return err
+		}
+
+		for i := range recipes {
+			if err = uc.analyze(ctx, &recipes[i]); err != nil {
+				return err
+			}
+
+			if err = uc.repo.UpdateAnalysis(ctx, &recipes[i]); err != nil && !errors.Is(err, entity.ErrNotFound) {
+				return err
+			}
+		}
+
+		if len(recipes) < _analyzeBatch {
+			return nil
+		}
+	}
+}
+
+// withPreferences adds the viewer's preferences to the filter.
+func (uc *RecipeUseCase) withPreferences(ctx context.Context, filter entity.RecipeFilter) (entity.RecipeFilter, error) {
+	if filter.ViewerID == "" {
+		return filter, nil
+	}
+
+	preferences, err := uc.preferences.Get(ctx, filter.ViewerID)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.WithoutAllergens = append(filter.WithoutAllergens, preferences.Allergens...)
+	filter.Diets = append(filter.Diets, preferences.Diets...)
+
+	return filter, nil
+}
+
+func computeDietary(recipe *entity.Recipe, catalog *catalogIndex, overrides []entity.DietaryOverride) *entity.RecipeDietary {
+	result := &entity.RecipeDietary{Overrides: overrides}
+
+	allergens := make(map[string]bool)
+	fits := make(map[string]int)
+
+	for _, ingredient := range recipe.Ingredients {
+		entry := catalog.entry(ingredient)
+		if entry == nil {
+			result.Unverified = append(result.Unverified, ingredient.ID)
+			continue
+		}
+
+		for _, allergen := range entry.Allergens {
+			allergens[allergen] = true
+		}
+
+		entryDiets := make(map[string]bool, len(entry.Diets)+1)
+		for _, diet := range entry.Diets {
+			entryDiets[diet] = true
+		}
+
+		// Vegan ingredients are vegetarian even if the catalog forgot to say.
+		if entryDiets[entity.DietVegan] {
+			entryDiets[entity.DietVegetarian] = true
+		}
+
+		for diet := range entryDiets {
+			fits[diet]++
+		}
+	}
+
+	diets := make(map[string]bool)
+
+	if len(result.Unverified) == 0 && len(recipe.Ingredients) > 0 {
+		for diet, count := range fits {
+			diets[diet] = count == len(recipe.Ingredients)
+		}
+	}
+
+	result.DetectedAllergens = ordered(entity.Allergens, allergens)
+	result.DetectedDiets = ordered(entity.Diets, diets)
+
+	for _, o := range overrides {
+		switch o.Kind {
+		case entity.OverrideAllergen:
+			allergens[o.Value] = o.Present
+		case entity.OverrideDiet:
+			diets[o.Value] = o.Present
+		}
+	}
+
+	result.Allergens = ordered(entity.Allergens, allergens)
+	result.Diets = ordered(entity.Diets, diets)
+
+	return result
+}
+
+// ordered returns the values of all that are set, in the order of all.
+func ordered(all []string, set map[string]bool) []string {
+	result := []string{}
+
+	for _, value := range all {
+		if set[value] {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+func dietaryCatalog() *memCatalog {
+	return &memCatalog{entries: []entity.CatalogIngredient{
+		{ID: "flour", Name: "Flour", Allergens: []string{entity.AllergenGluten}, Diets: []string{entity.DietHalal, entity.DietVegan}},
+		{ID: "butter", Name: "Butter", Allergens: []string{entity.AllergenDairy}, Diets: []string{entity.DietHalal, entity.DietVegetarian}},
+		{ID: "sugar", Name: "Sugar", Diets: []string{entity.DietHalal, entity.DietVegan, entity.DietVegetarian}},
+	}}
+}
+
+func TestRecipeDietary(t *testing.T) {
+	t.Parallel()
+
+	uc := usecase.NewRecipeUseCase(&memRecipes{}, dietaryCatalog(), nil)
+
+	recipe, err := uc.Create(context.Background(), entity.Recipe{
+		Ingredients: []entity.Ingredient{
+			{Name: "flour"},
+			{Name: "butter"},
+			{Name: "sugar"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	d := recipe.Dietary
+	if d == nil {
+		t.Fatal("Dietary is nil")
+	}
+
+	if want := []string{entity.AllergenGluten, entity.AllergenDairy}; !reflect.DeepEqual(d.Allergens, want) {
+		t.Errorf("Allergens = %v, want %v", d.Allergens, want)
+	}
+
+	// Flour is vegan, so vegetarian too; butter isn't vegan.
+	if want := []string{entity.DietHalal, entity.DietVegetarian}; !reflect.DeepEqual(d.Diets, want) {
+		t.Errorf("Diets = %v, want %v", d.Diets, want)
+	}
+}
+
+func TestRecipeDietaryUnverified(t *testing.T) {
+	t.Parallel()
+
+	uc := usecase.NewRecipeUseCase(&memRecipes{}, dietaryCatalog(), nil)
+
+	recipe, err := uc.Create(context.Background(), entity.Recipe{
+		Ingredients: []entity.Ingredient{
+			{ID: "sugar", Name: "sugar"},
+			{ID: "mystery", Name: "secret spice"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	d := recipe.Dietary
+	if len(d.Diets) != 0 {
+		t.Errorf("Diets = %v, want none with unverified ingredients", d.Diets)
+	}
+
+	if !reflect.DeepEqual(d.Unverified, []string{"mystery"}) {
+		t.Errorf("Unverified = %v, want [mystery]", d.Unverified)
+	}
+}
+
+// overrideRecipes serves one recipe owned by "author".
+type overrideRecipes struct {
+	usecase.RecipeRepo
+	recipe entity.Recipe
+	saved  *entity.Recipe
+}
+
+func (m *overrideRecipes) Get(context.Context, string) (*entity.Recipe, error) {
+	recipe := m.recipe
+
+	return &recipe, nil
+}
+
+func (m *overrideRecipes) UpdateAnalysis(_ context.Context, recipe *entity.Recipe) error {
+	m.saved = recipe
+
+	return nil
+}
+
+func TestSetDietaryOverrides(t *testing.T) {
+	t.Parallel()
+
+	repo := &overrideRecipes{recipe: entity.Recipe{
+		ID:       "recipe",
+		AuthorID: "author",
+		Ingredients: []entity.Ingredient{
+			{ID: "flour", Name: "flour"},
+			{ID: "beef", Name: "beef"},
+		},
+	}}
+	uc := usecase.NewRecipeUseCase(repo, dietaryCatalog(), nil)
+
+	recipe, err := uc.SetDietaryOverrides(context.Background(), "author", "recipe", []entity.DietaryOverride{
+		{Kind: entity.OverrideDiet, Value: entity.DietHalal, Present: true, Reason: "certified halal beef"},
+		{Kind: entity.OverrideAllergen, Value: entity.AllergenGluten, Present: false, Reason: "gluten-free flour"},
+	})
+	if err != nil {
+		t.Fatalf("SetDietaryOverrides: %v", err)
+	}
+
+	if repo.saved == nil {
+		t.Fatal("analysis not saved")
+	}
+
+	d := recipe.Dietary
+	if !reflect.DeepEqual(d.DetectedAllergens, []string{entity.AllergenGluten}) || len(d.Allergens) != 0 {
+		t.Errorf("allergens detected %v, effective %v; want [gluten], []", d.DetectedAllergens, d.Allergens)
+	}
+
+	if len(d.DetectedDiets) != 0 || !reflect.DeepEqual(d.Diets, []string{entity.DietHalal}) {
+		t.Errorf("diets detected %v, effective %v; want [], [halal]", d.DetectedDiets, d.Diets)
+	}
+
+	_, err = uc.SetDietaryOverrides(context.Background(), "author", "recipe", []entity.DietaryOverride{
+		{Kind: entity.OverrideDiet, Value: "keto", Reason: "no such diet"},
+	})
+	if !errors.Is(err, entity.ErrInvalidOverride) {
+		t.Errorf("unknown diet error = %v, want ErrInvalidOverride", err)
+	}
+}
+
+// unanalyzedRecipes serves recipes saved without analysis until they are
+// updated.
+type unanalyzedRecipes struct {
+	usecase.RecipeRepo
+	pending  []entity.Recipe
+	analyzed map[string]*entity.RecipeDietary
+}
+
+func (m *unanalyzedRecipes) ListUnanalyzed(_ context.Context, limit uint64) ([]entity.Recipe, error) {
+	var recipes []entity.Recipe
+
+	for _, r := range m.pending {
+		if _, ok := m.analyzed[r.ID]; !ok && uint64(len(recipes)) < limit {
+			recipes = append(recipes, r)
+		}
+	}
+
+	return recipes, nil
+}
+
+func (m *unanalyzedRecipes) UpdateAnalysis(_ context.Context, recipe *entity.Recipe) error {
+	m.analyzed[recipe.ID] = recipe.Dietary
+
+	return nil
+}
+
+func TestAnalyzeMissing(t *testing.T) {
+	t.Parallel()
+
+	repo := &unanalyzedRecipes{analyzed: make(map[string]*entity.RecipeDietary)}
+	for i := 0; i < 150; i++ {
+		repo.pending = append(repo.pending, entity.Recipe{
+			ID:          strconv.Itoa(i),
+			Ingredients: []entity.Ingredient{{ID: "flour", Name: "flour"}},
+		})
+	}
+
+	uc := usecase.NewRecipeUseCase(repo, dietaryCatalog(), nil)
+
+	if err := uc.AnalyzeMissing(context.Background()); err != nil {
+		t.Fatalf("AnalyzeMissing: %v", err)
+	}
+
+	if len(repo.analyzed) != len(repo.pending) {
+		t.Fatalf("analyzed %d recipes, want %d", len(repo.analyzed), len(repo.pending))
+	}
+
+	if d := repo.analyzed["149"]; d == nil || !reflect.DeepEqual(d.Allergens, []string{entity.AllergenGluten}) {
+		t.Errorf("last recipe dietary = %+v, want gluten", d)
+	}
+}
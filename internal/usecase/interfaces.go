@@ -3,23 +3,42 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/ical"
 )
 
 //go:generate mockgen -source=interfaces.go -destination=./mocks_test.go -package=usecase_test
 
 type (
 	Auth interface {
-		Register(context.Context, *entity.User) error
+		Register(context.Context, *entity.User, entity.ChallengeSolution) error
 		Verify(context.Context, entity.VerifyUser) (*entity.User, error)
-		ForgotPassword(context.Context, string) error
-		ResetPassword(context.Context, string, string, string) error
+		RequestPasswordReset(context.Context, string, entity.ChallengeSolution) error
+		VerifyPasswordReset(context.Context, string, string) (*entity.PasswordResetToken, error)
+		ResetPassword(context.Context, string, string) error
 		Login(context.Context, entity.LoginRequest) (*entity.LoginResponse, error)
 	}
 
+	// Sessions tracks the devices a user is signed in on. Access tokens are
+	// only accepted while their session is alive.
+	Sessions interface {
+		Start(context.Context, string, entity.ClientInfo) (*entity.Session, error)
+		Validate(context.Context, string, string) error
+		List(context.Context, string) ([]entity.Session, error)
+		Revoke(context.Context, string, string) error
+		RevokeAll(context.Context, string, ...string) error
+	}
+
+	// TokenIssuer signs access tokens; the session ID becomes the jti.
+	TokenIssuer interface {
+		Issue(string, string, string) (string, error)
+	}
+
 	AuthRepo interface {
-		Create(context.Context, *entity.User) (*entity.User, error)
+		Create(context.Context, *entity.User, ...entity.OutboxEvent) (*entity.User, error)
 		CheckField(context.Context, string, string) (bool, error)
 		UpdatePassword(context.Context, string, string) error
 		GetUserByNickName(context.Context, string) (*entity.User, error)
@@ -29,5 +48,304 @@ type (
 	AuthWebAPI interface {
 		SendSMS(context.Context, string, string) error
 		SendSMSWithAndroid(context.Context, string, string, string) error
+		SendWelcomeSMS(context.Context, string, string) error
+	}
+
+	// BotGuard decides whether a request that sends an SMS has to prove it
+	// isn't automated, and checks the proof.
+	BotGuard interface {
+		IssueChallenge(context.Context) (*entity.Challenge, error)
+		Check(context.Context, string, entity.ChallengeSolution) error
+	}
+
+	// ChallengeVerifier checks one kind of solution: proof-of-work or a
+	// captcha provider.
+	ChallengeVerifier interface {
+		Verify(context.Context, entity.ChallengeSolution) (bool, error)
+	}
+
+	Admin interface {
+		SearchUsers(context.Context, entity.UserFilter) ([]entity.User, error)
+		BanUser(context.Context, string, string, entity.UserBan) error
+		UnbanUser(context.Context, string, string) error
+		SetRole(context.Context, string, string, string) error
+		ForceLogout(context.Context, string, string) error
+	}
+
+	AdminRepo interface {
+		SearchUsers(context.Context, entity.UserFilter) ([]entity.User, error)
+		GetUser(context.Context, string) (*entity.User, error)
+		UpdateRole(context.Context, string, string) error
+		UpdateBan(context.Context, string, *entity.UserBan) error
+	}
+
+	// AuditHook is called by use cases after security-sensitive actions.
+	AuditHook interface {
+		Record(context.Context, entity.AuditEntry)
+	}
+
+	Audit interface {
+		AuditHook
+		List(context.Context, entity.AuditFilter) (*entity.AuditList, error)
+	}
+
+	AuditRepo interface {
+		Insert(context.Context, *entity.AuditEntry) error
+		List(context.Context, entity.AuditFilter) ([]entity.AuditEntry, error)
+		DeleteBefore(context.Context, time.Time) (int64, error)
+	}
+
+	Moderation interface {
+		Report(context.Context, entity.Report) (*entity.Report, error)
+		List(context.Context, entity.ReportFilter) (*entity.ReportList, error)
+		Get(context.Context, string) (*entity.Report, error)
+		Triage(context.Context, string, string) error
+		Action(context.Context, string, string, entity.ModerationDecision) error
+		Dismiss(context.Context, string, string, string) error
+	}
+
+	// ModerationTarget applies moderation to one kind of reported content.
+	// Author returns ErrNotFound for missing content; actions that make no
+	// sense for the kind return ErrUnsupportedAction.
+	ModerationTarget interface {
+		Author(context.Context, string) (string, error)
+		Hide(context.Context, string) error
+		Delete(context.Context, string) error
+	}
+
+	ReportRepo interface {
+		Create(context.Context, *entity.Report) error
+		Get(context.Context, string) (*entity.Report, error)
+		List(context.Context, entity.ReportFilter) ([]entity.Report, error)
+		Triage(context.Context, string, string) error
+		Resolve(context.Context, *entity.Report) ([]entity.Report, error)
+		Reopen(context.Context, []entity.Report) error
+	}
+
+	// ResourceAccessor tells the authorizer who owns the resource with the
+	// given ID and whether others may open it.
+	ResourceAccessor interface {
+		Access(context.Context, string) (*entity.ResourceAccess, error)
+	}
+
+	Recipe interface {
+		ResourceAccessor
+		Create(context.Context, entity.Recipe) (*entity.Recipe, error)
+		Get(context.Context, string) (*entity.Recipe, error)
+		Update(context.Context, string, entity.Recipe) (*entity.Recipe, error)
+		SetStatus(context.Context, string, string, string, *time.Time) (*entity.Recipe, error)
+		Delete(context.Context, string, string) error
+		List(context.Context, entity.RecipeFilter) (*entity.RecipeList, error)
+		ListByAuthor(context.Context, entity.RecipeFilter) (*entity.RecipeList, error)
+		Revisions(context.Context, string) ([]entity.RecipeRevision, error)
+		Revision(context.Context, string, int) (*entity.RecipeRevision, error)
+		Diff(context.Context, string, int, int) (*entity.RecipeDiff, error)
+		Restore(context.Context, string, string, int) (*entity.Recipe, error)
+		SetDietaryOverrides(context.Context, string, string, []entity.DietaryOverride) (*entity.Recipe, error)
+	}
+
+	// RecipeImport creates drafts from recipes published elsewhere.
+	RecipeImport interface {
+		FromURL(context.Context, string, string) (*entity.Recipe, error)
+		FromDocument(context.Context, string, string, string) (*entity.Recipe, error)
+	}
+
+	// RecipePageWebAPI fetches the HTML page at a URL given by a user.
+	RecipePageWebAPI interface {
+		Fetch(context.Context, string) (string, error)
+	}
+
+	RecipeRepo interface {
+		Create(context.Context, *entity.Recipe) error
+		Get(context.Context, string) (*entity.Recipe, error)
+		ListPublished(context.Context, entity.RecipeFilter) ([]entity.Recipe, error)
+		ListByAuthor(context.Context, entity.RecipeFilter) ([]entity.Recipe, error)
+		Update(context.Context, *entity.Recipe) error
+		UpdateStatus(context.Context, *entity.Recipe) error
+		UpdateAnalysis(context.Context, *entity.Recipe) error
+		ListUnanalyzed(context.Context, uint64) ([]entity.Recipe, error)
+		SetHidden(context.Context, string, bool) error
+		Delete(context.Context, string) error
+		PublishDue(context.Context, time.Time, int) ([]string, error)
+		ListRevisions(context.Context, string) ([]entity.RecipeRevision, error)
+		GetRevision(context.Context, string, int) (*entity.RecipeRevision, error)
+	}
+
+	Nutrition interface {
+		Search(context.Context, entity.CatalogFilter) (*entity.CatalogList, error)
+		Import(context.Context, io.Reader) (*entity.CatalogImport, error)
+	}
+
+	// CatalogRepo upserts catalog entries by name. Match finds the entries
+	// with the given IDs or with the given lowercase names or aliases.
+	CatalogRepo interface {
+		Search(context.Context, entity.CatalogFilter) ([]entity.CatalogIngredient, error)
+		Upsert(context.Context, []entity.CatalogIngredient) (*entity.CatalogImport, error)
+		Match(context.Context, []string, []string) ([]entity.CatalogIngredient, error)
+	}
+
+	Dietary interface {
+		Preferences(context.Context, string) (*entity.DietaryPreferences, error)
+		SetPreferences(context.Context, string, entity.DietaryPreferences) (*entity.DietaryPreferences, error)
+	}
+
+	// PreferenceRepo returns empty preferences for users who never set any.
+	PreferenceRepo interface {
+		Get(context.Context, string) (*entity.DietaryPreferences, error)
+		Save(context.Context, string, *entity.DietaryPreferences) error
+	}
+
+	Shopping interface {
+		Create(context.Context, string, string) (*entity.ShoppingList, error)
+		List(context.Context, string) ([]entity.ShoppingList, error)
+		Get(context.Context, string, string) (*entity.ShoppingList, error)
+		Delete(context.Context, string, string) error
+		AddRecipe(context.Context, string, string, string, int) (*entity.ShoppingList, error)
+		RemoveRecipe(context.Context, string, string, string) (*entity.ShoppingList, error)
+		CheckItem(context.Context, string, string, string, bool) (*entity.ShoppingList, error)
+		Share(context.Context, string, string) (*entity.ShoppingList, error)
+		Unshare(context.Context, string, string) (*entity.ShoppingList, error)
+		Join(context.Context, string, string) (*entity.ShoppingList, error)
+		RemoveMember(context.Context, string, string, string) error
+	}
+
+	// ShoppingRepo loads a list with its members, recipes and checked items.
+	// An empty share token revokes sharing.
+	ShoppingRepo interface {
+		Create(context.Context, *entity.ShoppingList) error
+		Get(context.Context, string) (*entity.ShoppingList, error)
+		GetByShareToken(context.Context, string) (*entity.ShoppingList, error)
+		ListByUser(context.Context, string) ([]entity.ShoppingList, error)
+		Delete(context.Context, string) error
+		AddRecipe(context.Context, string, entity.ShoppingListRecipe) error
+		RemoveRecipe(context.Context, string, string) (bool, error)
+		SetChecked(context.Context, string, string, bool) error
+		SetShareToken(context.Context, string, string) error
+		AddMember(context.Context, string, string) error
+		RemoveMember(context.Context, string, string) (bool, error)
+	}
+
+	MealPlan interface {
+		Create(context.Context, string, entity.MealPlan) (*entity.MealPlan, error)
+		List(context.Context, string) ([]entity.MealPlan, error)
+		Get(context.Context, string, string) (*entity.MealPlan, error)
+		Delete(context.Context, string, string) error
+		SetSlot(context.Context, string, string, entity.MealSlot) (*entity.MealPlan, error)
+		RemoveSlot(context.Context, string, string, int, string) (*entity.MealPlan, error)
+		Copy(context.Context, string, string, string, string) (*entity.MealPlan, error)
+		RotateFeedToken(context.Context, string, string) (*entity.MealPlan, error)
+		ShoppingList(context.Context, string, string, string, string, string) (*entity.ShoppingList, error)
+		Calendar(context.Context, string, string, string) (*ical.Calendar, error)
+	}
+
+	// MealPlanRepo loads plans with their slots, titled after the recipes.
+	// Create saves the plan's slots too.
+	MealPlanRepo interface {
+		Create(context.Context, *entity.MealPlan) error
+		Get(context.Context, string) (*entity.MealPlan, error)
+		ListByOwner(context.Context, string) ([]entity.MealPlan, error)
+		Delete(context.Context, string) error
+		SetSlot(context.Context, string, entity.MealSlot) error
+		RemoveSlot(context.Context, string, int, string) (bool, error)
+		SetFeedToken(context.Context, string, string) error
+	}
+
+	Pantry interface {
+		List(context.Context, string) ([]entity.PantryItem, error)
+		Add(context.Context, string, entity.PantryItem) (*entity.PantryItem, error)
+		Update(context.Context, string, entity.PantryItem) (*entity.PantryItem, error)
+		Delete(context.Context, string, string) error
+		Cookable(context.Context, string, entity.CookableFilter) ([]entity.RecipeMatch, error)
+	}
+
+	// PantryRepo scopes items to their user. ClaimExpiring marks items
+	// expiring on or before the date as reminded and returns them.
+	PantryRepo interface {
+		List(context.Context, string) ([]entity.PantryItem, error)
+		Count(context.Context, string) (int, error)
+		Create(context.Context, *entity.PantryItem) error
+		Update(context.Context, *entity.PantryItem) error
+		Delete(context.Context, string, string) error
+		ClaimExpiring(context.Context, string, int) ([]entity.PantryItem, error)
+	}
+
+	Cook interface {
+		Start(context.Context, string, string) (*entity.CookSession, error)
+		List(context.Context, string) ([]entity.CookSession, error)
+		Get(context.Context, string, string) (*entity.CookSession, error)
+		Update(context.Context, string, string, entity.CookProgress) (*entity.CookSession, error)
+		Complete(context.Context, string, string, bool) (*entity.CookSession, error)
+		Abandon(context.Context, string, string) error
+	}
+
+	CookRepo interface {
+		RecordCook(context.Context, entity.RecipeCook) error
+	}
+
+	// JobPublisher enqueues background jobs for the worker.
+	JobPublisher interface {
+		Publish(context.Context, string, interface{}) error
+	}
+
+	Jobs interface {
+		SendSMS(context.Context, entity.SendSMSJob) error
+		RenderAvatar(context.Context, entity.RenderAvatarJob) error
+		GenerateImageVariants(context.Context, entity.ImageVariantsJob) error
+		WelcomeUser(context.Context, entity.UserCreatedEvent) error
+	}
+
+	// EventBus delivers outbox events; the ID lets consumers drop duplicates.
+	// It must only return nil once the broker has stored the event, which
+	// the confirmed, persistent rmq_jobs publisher does and rmq_rpc, with its
+	// transient exchanges and no confirms, does not.
+	EventBus interface {
+		PublishWithID(context.Context, string, string, interface{}) error
+	}
+
+	OutboxRepo interface {
+		Claim(context.Context, int, time.Duration) ([]entity.OutboxEvent, error)
+		MarkPublished(context.Context, string) error
+		MarkFailed(context.Context, string, string) error
+	}
+
+	// NotificationProducer is what other use cases depend on to notify users.
+	NotificationProducer interface {
+		Notify(context.Context, entity.Notification) error
+	}
+
+	Notification interface {
+		NotificationProducer
+		List(context.Context, string, uint64, uint64) (*entity.NotificationList, error)
+		MarkRead(context.Context, string, string) error
+		MarkAllRead(context.Context, string) error
+		UnreadCount(context.Context, string) (int, error)
+	}
+
+	// EventPublisher fans events out to every app instance over Redis pub/sub.
+	EventPublisher interface {
+		PublishUserEvent(context.Context, string, entity.Event) error
+		PublishRecipeEvent(context.Context, string, entity.Event) error
+	}
+
+	Realtime interface {
+		EventPublisher
+		Subscribe(context.Context, string) (EventSubscription, error)
+	}
+
+	// EventSubscription delivers a user's own events plus events of the
+	// recipes the client watches.
+	EventSubscription interface {
+		Events() <-chan entity.Event
+		WatchRecipe(context.Context, string) error
+		UnwatchRecipe(context.Context, string) error
+		Close() error
+	}
+
+	NotificationRepo interface {
+		Create(context.Context, *entity.Notification) error
+		List(context.Context, string, uint64, uint64) ([]entity.Notification, error)
+		MarkRead(context.Context, string, string) (bool, error)
+		MarkAllRead(context.Context, string) error
+		CountUnread(context.Context, string) (int, error)
 	}
 )
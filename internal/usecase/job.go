@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"tarkib.uz/internal/entity"
+	avatargenerator "tarkib.uz/pkg/avatar-generator"
+	imagevariants "tarkib.uz/pkg/image-variants"
+)
+
+// JobUseCase runs the work the HTTP handlers hand off to the worker.
+type JobUseCase struct {
+	webAPI      AuthWebAPI
+	MinioClient *minio.Client
+}
+
+func NewJobUseCase(w AuthWebAPI, minioClient *minio.Client) *JobUseCase {
+	return &JobUseCase{
+		webAPI:      w,
+		MinioClient: minioClient,
+	}
+}
+
+func (uc *JobUseCase) SendSMS(ctx context.Context, job entity.SendSMSJob) error {
+	return uc.webAPI.SendSMSWithAndroid(ctx, job.PhoneNumber, job.Code, job.Type)
+}
+
+func (uc *JobUseCase) WelcomeUser(ctx context.Context, event entity.UserCreatedEvent) error {
+	return uc.webAPI.SendWelcomeSMS(ctx, event.PhoneNumber, event.FirstName)
+}
+
+func (uc *JobUseCase) RenderAvatar(ctx context.Context, job entity.RenderAvatarJob) error {
+	var buf bytes.Buffer
+
+	initials := avatargenerator.GetInitial(job.FirstName, job.LastName)
+	if err := avatargenerator.RenderProfileImage(initials, &buf); err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+
+	_, err := uc.MinioClient.PutObject(ctx, job.Bucket, job.ObjectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "image/png",
+	})
+	if err != nil {
+		return err
+	}
+
+	return uc.putVariants(ctx, job.Bucket, job.ObjectName, data)
+}
+
+func (uc *JobUseCase) GenerateImageVariants(ctx context.Context, job entity.ImageVariantsJob) error {
+	object, err := uc.MinioClient.GetObject(ctx, job.Bucket, job.ObjectName, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return err
+	}
+
+	return uc.putVariants(ctx, job.Bucket, job.ObjectName, data)
+}
+
+// putVariants overwrites existing variants, so retries are safe.
+func (uc *JobUseCase) putVariants(ctx context.Context, bucket, objectName string, data []byte) error {
+	variants, err := imagevariants.Generate(objectName, data)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range variants {
+		_, err = uc.MinioClient.PutObject(ctx, bucket, v.ObjectName, bytes.NewReader(v.Data), int64(len(v.Data)), minio.PutObjectOptions{
+			ContentType: "image/jpeg",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"tarkib.uz/internal/entity"
+)
+
+func loginFailuresKey(userID string) string {
+	return "login:failures:" + userID
+}
+
+func loginLockKey(userID string) string {
+	return "login:lock:" + userID
+}
+
+// checkLockout returns a LockedError while the account is locked.
+func (uc *AuthUseCase) checkLockout(ctx context.Context, userID string) error {
+	ttl, err := uc.RedisClient.PTTL(ctx, loginLockKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	if ttl > 0 {
+		return &entity.LockedError{RetryAfter: ttl}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login. From the configured threshold on,
+// every failure locks the account, doubling the lock each time.
+func (uc *AuthUseCase) recordLoginFailure(ctx context.Context, userID string) error {
+	lockout := uc.cfg.RateLimit.Lockout
+
+	failures, err := uc.RedisClient.Incr(ctx, loginFailuresKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	err = uc.RedisClient.Expire(ctx, loginFailuresKey(userID), time.Duration(lockout.ResetAfter)*time.Second).Err()
+	if err != nil {
+		return err
+	}
+
+	if lockout.Threshold <= 0 || failures < int64(lockout.Threshold) {
+		return nil
+	}
+
+	duration := time.Duration(lockout.BaseDuration) * time.Second
+	maxDuration := time.Duration(lockout.MaxDuration) * time.Second
+
+	for i := int64(lockout.Threshold); i < failures && duration < maxDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+
+	if err = uc.RedisClient.Set(ctx, loginLockKey(userID), 1, duration).Err(); err != nil {
+		return err
+	}
+
+	return &entity.LockedError{RetryAfter: duration}
+}
+
+func (uc *AuthUseCase) resetLoginFailures(ctx context.Context, userID string) {
+	uc.RedisClient.Del(ctx, loginFailuresKey(userID))
+}
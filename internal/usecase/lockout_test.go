@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"tarkib.uz/config"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+	"tarkib.uz/pkg/password"
+)
+
+// oneUser is an auth repo with a single account, "aziz".
+type oneUser struct {
+	usecase.AuthRepo
+	user entity.User
+}
+
+func (r *oneUser) GetUserByNickName(_ context.Context, nickName string) (*entity.User, error) {
+	if nickName != r.user.NickName {
+		return nil, entity.ErrNotFound
+	}
+
+	user := r.user
+
+	return &user, nil
+}
+
+type noAudit struct{}
+
+func (noAudit) Record(context.Context, entity.AuditEntry) {}
+
+func TestLoginLockout(t *testing.T) {
+	t.Parallel()
+
+	hash, err := password.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := miniredis.RunT(t)
+
+	cfg := &config.Config{}
+	cfg.RateLimit.Lockout = config.LoginLockout{Threshold: 3, BaseDuration: 60, MaxDuration: 200, ResetAfter: 3600}
+
+	uc := usecase.NewAuthUseCase(&oneUser{user: entity.User{ID: "user", NickName: "aziz", Password: hash}},
+		nil, nil, nil, nil, noAudit{}, cfg, redis.NewClient(&redis.Options{Addr: server.Addr()}), nil)
+
+	ctx := context.Background()
+	wrong := entity.LoginRequest{NickName: "aziz", Password: "wrong"}
+
+	for i := 1; i < 3; i++ {
+		if _, err = uc.Login(ctx, wrong); err == nil || errors.Is(err, entity.ErrAccountLocked) {
+			t.Fatalf("failure %d error = %v, want a wrong password below the threshold", i, err)
+		}
+	}
+
+	// From the threshold on, each failure locks for twice as long, capped.
+	for _, want := range []time.Duration{60 * time.Second, 120 * time.Second, 200 * time.Second, 200 * time.Second} {
+		_, err = uc.Login(ctx, wrong)
+
+		var locked *entity.LockedError
+		if !errors.As(err, &locked) || locked.RetryAfter != want {
+			t.Fatalf("error = %v, want locked for %v", err, want)
+		}
+
+		// The right password doesn't help while locked.
+		_, err = uc.Login(ctx, entity.LoginRequest{NickName: "aziz", Password: "correct horse"})
+		if !errors.As(err, &locked) || locked.RetryAfter <= 0 || locked.RetryAfter > want {
+			t.Fatalf("login while locked error = %v, want locked", err)
+		}
+
+		server.FastForward(want)
+	}
+
+	// Failures are forgotten after ResetAfter.
+	server.FastForward(time.Hour)
+
+	if _, err = uc.Login(ctx, wrong); err == nil || errors.Is(err, entity.ErrAccountLocked) {
+		t.Errorf("failure after reset error = %v, want a wrong password without a lock", err)
+	}
+}
@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/ical"
+)
+
+const (
+	_mealDuration = time.Hour
+	_icalProdID   = "-//Tarkib//Meal Plan//EN"
+)
+
+// _mealTimes is when meals show up in calendars, in the reader's local time.
+var _mealTimes = map[string]time.Duration{
+	entity.MealBreakfast: 8 * time.Hour,
+	entity.MealLunch:     13 * time.Hour,
+	entity.MealDinner:    19 * time.Hour,
+}
+
+// MealPlanUseCase manages the user's meal plans. Plans are private; the
+// calendar feed is also open to whoever has the plan's feed token.
+type MealPlanUseCase struct {
+	repo     MealPlanRepo
+	recipes  RecipeRepo
+	shopping Shopping
+}
+
+func NewMealPlanUseCase(r MealPlanRepo, recipes RecipeRepo, s Shopping) *MealPlanUseCase {
+	return &MealPlanUseCase{
+		repo:     r,
+		recipes:  recipes,
+		shopping: s,
+	}
+}
+
+// Create starts an empty plan. Templates last a week and have no start
+// date; dated plans need one.
+func (uc *MealPlanUseCase) Create(ctx context.Context, userID string, plan entity.MealPlan) (*entity.MealPlan, error) {
+	if plan.Template {
+		plan.Days = entity.WeekDays
+	}
+
+	if plan.Days == 0 {
+		plan.Days = entity.WeekDays
+	}
+
+	plan.ID = uuid.NewString()
+	plan.OwnerID = userID
+	plan.Slots = nil
+
+	return uc.create(ctx, plan)
+}
+
+func (uc *MealPlanUseCase) List(ctx context.Context, userID string) ([]entity.MealPlan, error) {
+	return uc.repo.ListByOwner(ctx, userID)
+}
+
+func (uc *MealPlanUseCase) Get(ctx context.Context, userID, planID string) (*entity.MealPlan, error) {
+	plan, err := uc.load(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dated(plan), nil
+}
+
+func (uc *MealPlanUseCase) Delete(ctx context.Context, userID, planID string) error {
+	if _, err := uc.load(ctx, userID, planID); err != nil {
+		return err
+	}
+
+	return uc.repo.Delete(ctx, planID)
+}
+
+// SetSlot puts a recipe on a meal, replacing what was planned. Servings
+// default to the recipe's own.
+func (uc *MealPlanUseCase) SetSlot(ctx context.Context, userID, planID string, slot entity.MealSlot) (*entity.MealPlan, error) {
+	plan, err := uc.load(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if slot.Day < 0 || slot.Day >= plan.Days || !entity.ValidMeal(slot.Meal) {
+		return nil, entity.ErrInvalidMealSlot
+	}
+
+	recipe, err := uc.recipes.Get(ctx, slot.RecipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !recipe.Public() && recipe.AuthorID != userID {
+		return nil, entity.ErrNotFound
+	}
+
+	if slot.Servings <= 0 {
+		slot.Servings = recipe.Servings
+	}
+
+	if slot.Servings <= 0 {
+		slot.Servings = 1
+	}
+
+	if err = uc.repo.SetSlot(ctx, planID, slot); err != nil {
+		return nil, err
+	}
+
+	return uc.Get(ctx, userID, planID)
+}
+
+func (uc *MealPlanUseCase) RemoveSlot(ctx context.Context, userID, planID string, day int, meal string) (*entity.MealPlan, error) {
+	if _, err := uc.load(ctx, userID, planID); err != nil {
+		return nil, err
+	}
+
+	removed, err := uc.repo.RemoveSlot(ctx, planID, day, meal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !removed {
+		return nil, entity.ErrNotFound
+	}
+
+	return uc.Get(ctx, userID, planID)
+}
+
+// Copy makes a new plan with the slots of the plan: a dated plan starting
+// on startDate, or a template when startDate is empty. A template copied
+// to next Monday is how a week gets planned again.
+func (uc *MealPlanUseCase) Copy(ctx context.Context, userID, planID, name, startDate string) (*entity.MealPlan, error) {
+	source, err := uc.load(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = source.Name
+	}
+
+	plan := entity.MealPlan{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		Name:      name,
+		Template:  startDate == "",
+		StartDate: startDate,
+		Days:      source.Days,
+		Slots:     source.Slots,
+	}
+
+	if plan.Template && plan.Days != entity.WeekDays {
+		return nil, entity.ErrInvalidMealPlan
+	}
+
+	return uc.create(ctx, plan)
+}
+
+// RotateFeedToken replaces the feed token, so calendars subscribed with
+// the old one stop updating.
+func (uc *MealPlanUseCase) RotateFeedToken(ctx context.Context, userID, planID string) (*entity.MealPlan, error) {
+	if _, err := uc.load(ctx, userID, planID); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.repo.SetFeedToken(ctx, planID, token); err != nil {
+		return nil, err
+	}
+
+	return uc.Get(ctx, userID, planID)
+}
+
+// ShoppingList creates a shopping list with the recipes planned from from
+// to to, both included. A recipe planned more than once is added once with
+// the servings added up.
+func (uc *MealPlanUseCase) ShoppingList(ctx context.Context, userID, planID, from, to, name string) (*entity.ShoppingList, error) {
+	plan, err := uc.load(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Template {
+		return nil, entity.ErrMealPlanTemplate
+	}
+
+	first, last, err := planDays(plan, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+
+	servings := make(map[string]int)
+
+	for _, slot := range dated(plan).Slots {
+		if slot.Day < first || slot.Day > last {
+			continue
+		}
+
+		if _, ok := servings[slot.RecipeID]; !ok {
+			order = append(order, slot.RecipeID)
+		}
+
+		servings[slot.RecipeID] += slot.Servings
+	}
+
+	if len(order) == 0 {
+		return nil, entity.ErrNoMeals
+	}
+
+	if name == "" {
+		name = plan.Name + " " + from + " – " + to
+	}
+
+	list, err := uc.shopping.Create(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recipeID := range order {
+		if list, err = uc.shopping.AddRecipe(ctx, userID, list.ID, recipeID, servings[recipeID]); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// Calendar returns the plan as an iCalendar feed. The owner may fetch it
+// signed in; calendar apps pass the feed token instead.
+func (uc *MealPlanUseCase) Calendar(ctx context.Context, userID, planID, token string) (*ical.Calendar, error) {
+	plan, err := uc.repo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.OwnerID != userID && (token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(plan.FeedToken)) != 1) {
+		return nil, entity.ErrNotFound
+	}
+
+	if plan.Template {
+		return nil, entity.ErrMealPlanTemplate
+	}
+
+	start, err := time.Parse(entity.DateLayout, plan.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{
+		ProdID: _icalProdID,
+		Name:   plan.Name,
+		Events: make([]ical.Event, 0, len(plan.Slots)),
+	}
+
+	for _, slot := range dated(plan).Slots {
+		begin := start.AddDate(0, 0, slot.Day).Add(_mealTimes[slot.Meal])
+
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         plan.ID + "-" + strconv.Itoa(slot.Day) + "-" + slot.Meal + "@tarkib.uz",
+			Stamp:       plan.UpdatedAt,
+			Start:       begin,
+			End:         begin.Add(_mealDuration),
+			Summary:     slot.Title,
+			Description: "Servings: " + strconv.Itoa(slot.Servings),
+		})
+	}
+
+	return calendar, nil
+}
+
+func (uc *MealPlanUseCase) create(ctx context.Context, plan entity.MealPlan) (*entity.MealPlan, error) {
+	if plan.Days < 1 || plan.Days > entity.MaxMealPlanDays {
+		return nil, entity.ErrInvalidMealPlan
+	}
+
+	if plan.Template != (plan.StartDate == "") {
+		return nil, entity.ErrInvalidMealPlan
+	}
+
+	if !plan.Template {
+		if _, err := time.Parse(entity.DateLayout, plan.StartDate); err != nil {
+			return nil, entity.ErrInvalidMealPlan
+		}
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	plan.FeedToken = token
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	if err = uc.repo.Create(ctx, &plan); err != nil {
+		return nil, err
+	}
+
+	return dated(&plan), nil
+}
+
+func (uc *MealPlanUseCase) load(ctx context.Context, userID, planID string) (*entity.MealPlan, error) {
+	plan, err := uc.repo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.OwnerID != userID {
+		return nil, entity.ErrNotFound
+	}
+
+	return plan, nil
+}
+
+// dated orders the slots and fills in their dates.
+func dated(plan *entity.MealPlan) *entity.MealPlan {
+	sort.SliceStable(plan.Slots, func(i, j int) bool {
+		if plan.Slots[i].Day != plan.Slots[j].Day {
+			return plan.Slots[i].Day < plan.Slots[j].Day
+		}
+
+		return mealIndex(plan.Slots[i].Meal) < mealIndex(plan.Slots[j].Meal)
+	})
+
+	start, err := time.Parse(entity.DateLayout, plan.StartDate)
+	if plan.Template || err != nil {
+		return plan
+	}
+
+	for i := range plan.Slots {
+		plan.Slots[i].Date = start.AddDate(0, 0, plan.Slots[i].Day).Format(entity.DateLayout)
+	}
+
+	return plan
+}
+
+// planDays converts a date range to plan days.
+func planDays(plan *entity.MealPlan, from, to string) (first, last int, err error) {
+	start, err := time.Parse(entity.DateLayout, plan.StartDate)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fromDate, err := time.Parse(entity.DateLayout, from)
+	if err != nil {
+		return 0, 0, entity.ErrInvalidDateRange
+	}
+
+	toDate, err := time.Parse(entity.DateLayout, to)
+	if err != nil || toDate.Before(fromDate) {
+		return 0, 0, entity.ErrInvalidDateRange
+	}
+
+	day := func(t time.Time) int {
+		return int(t.Sub(start).Hours() / 24)
+	}
+
+	return day(fromDate), day(toDate), nil
+}
+
+func mealIndex(meal string) int {
+	for i, m := range entity.Meals {
+		if m == meal {
+			return i
+		}
+	}
+
+	return len(entity.Meals)
+}
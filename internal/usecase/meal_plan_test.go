@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// memMealPlans keeps plans in memory; slot titles are the recipe IDs.
+type memMealPlans struct {
+	usecase.MealPlanRepo
+	plans map[string]*entity.MealPlan
+}
+
+func (m *memMealPlans) Create(_ context.Context, plan *entity.MealPlan) error {
+	saved := *plan
+	saved.Slots = append([]entity.MealSlot{}, plan.Slots...)
+	m.plans[plan.ID] = &saved
+
+	return nil
+}
+
+func (m *memMealPlans) Get(_ context.Context, planID string) (*entity.MealPlan, error) {
+	plan, ok := m.plans[planID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	copied := *plan
+	copied.Slots = append([]entity.MealSlot{}, plan.Slots...)
+
+	return &copied, nil
+}
+
+func (m *memMealPlans) SetSlot(_ context.Context, planID string, slot entity.MealSlot) error {
+	slot.Title = slot.RecipeID
+	m.plans[planID].Slots = append(m.plans[planID].Slots, slot)
+
+	return nil
+}
+
+// recordingShopping records the recipes added to the one list it creates.
+type recordingShopping struct {
+	usecase.Shopping
+	added map[string]int
+	order []string
+}
+
+func (s *recordingShopping) Create(_ context.Context, userID, name string) (*entity.ShoppingList, error) {
+	return &entity.ShoppingList{ID: "list", OwnerID: userID, Name: name}, nil
+}
+
+func (s *recordingShopping) AddRecipe(_ context.Context, _, listID, recipeID string, servings int) (*entity.ShoppingList, error) {
+	s.added[recipeID] = servings
+	s.order = append(s.order, recipeID)
+
+	return &entity.ShoppingList{ID: listID}, nil
+}
+
+func plannedWeek(t *testing.T) (*usecase.MealPlanUseCase, *recordingShopping, *entity.MealPlan) {
+	t.Helper()
+
+	recipes := &publishedRecipes{recipes: map[string]entity.Recipe{
+		"plov":  {ID: "plov", Status: entity.RecipePublished, Servings: 4},
+		"salad": {ID: "salad", Status: entity.RecipePublished, Servings: 2},
+		"draft": {ID: "draft", AuthorID: "someone", Status: entity.RecipeDraft},
+	}}
+	shopping := &recordingShopping{added: map[string]int{}}
+	uc := usecase.NewMealPlanUseCase(&memMealPlans{plans: map[string]*entity.MealPlan{}}, recipes, shopping)
+	ctx := context.Background()
+
+	plan, err := uc.Create(ctx, "owner", entity.MealPlan{Name: "Week", StartDate: "2024-08-05"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, slot := range []entity.MealSlot{
+		{Day: 0, Meal: entity.MealDinner, RecipeID: "plov"},
+		{Day: 0, Meal: entity.MealLunch, RecipeID: "salad", Servings: 1},
+		{Day: 2, Meal: entity.MealLunch, RecipeID: "plov", Servings: 2},
+		{Day: 6, Meal: entity.MealBreakfast, RecipeID: "salad"},
+	} {
+		if plan, err = uc.SetSlot(ctx, "owner", plan.ID, slot); err != nil {
+			t.Fatalf("SetSlot(%+v): %v", slot, err)
+		}
+	}
+
+	return uc, shopping, plan
+}
+
+func TestMealPlanSlots(t *testing.T) {
+	t.Parallel()
+
+	uc, _, plan := plannedWeek(t)
+	ctx := context.Background()
+
+	first := plan.Slots[0]
+	if first.RecipeID != "salad" || first.Meal != entity.MealLunch || first.Date != "2024-08-05" {
+		t.Errorf("first slot = %+v, want Monday's lunch salad", first)
+	}
+
+	if plan.Slots[1].Servings != 4 {
+		t.Errorf("plov servings = %d, want the recipe's 4", plan.Slots[1].Servings)
+	}
+
+	for _, slot := range []entity.MealSlot{
+		{Day: 7, Meal: entity.MealLunch, RecipeID: "plov"},
+		{Day: 1, Meal: "supper", RecipeID: "plov"},
+	} {
+		if _, err := uc.SetSlot(ctx, "owner", plan.ID, slot); !errors.Is(err, entity.ErrInvalidMealSlot) {
+			t.Errorf("SetSlot(%+v) error = %v, want ErrInvalidMealSlot", slot, err)
+		}
+	}
+
+	if _, err := uc.SetSlot(ctx, "owner", plan.ID, entity.MealSlot{Meal: entity.MealLunch, RecipeID: "draft"}); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("SetSlot with someone's draft error = %v, want ErrNotFound", err)
+	}
+
+	template, err := uc.Copy(ctx, "owner", plan.ID, "", "")
+	if err != nil {
+		t.Fatalf("Copy to template: %v", err)
+	}
+
+	if !template.Template || template.StartDate != "" || len(template.Slots) != 4 || template.Slots[0].Date != "" {
+		t.Errorf("template = %+v, want 4 undated slots", template)
+	}
+
+	next, err := uc.Copy(ctx, "owner", template.ID, "Next week", "2024-08-12")
+	if err != nil {
+		t.Fatalf("Copy to next week: %v", err)
+	}
+
+	if last := next.Slots[len(next.Slots)-1]; last.Date != "2024-08-18" {
+		t.Errorf("last slot of next week = %+v, want 2024-08-18", last)
+	}
+}
+
+func TestMealPlanShoppingList(t *testing.T) {
+	t.Parallel()
+
+	uc, shopping, plan := plannedWeek(t)
+
+	if _, err := uc.ShoppingList(context.Background(), "owner", plan.ID, "2024-08-05", "2024-08-07", ""); err != nil {
+		t.Fatalf("ShoppingList: %v", err)
+	}
+
+	if want := map[string]int{"salad": 1, "plov": 6}; !reflect.DeepEqual(shopping.added, want) {
+		t.Errorf("added %v, want %v", shopping.added, want)
+	}
+
+	if !reflect.DeepEqual(shopping.order, []string{"salad", "plov"}) {
+		t.Errorf("order = %v, want the order they are eaten", shopping.order)
+	}
+
+	_, err := uc.ShoppingList(context.Background(), "owner", plan.ID, "2024-08-08", "2024-08-10", "")
+	if !errors.Is(err, entity.ErrNoMeals) {
+		t.Errorf("empty range error = %v, want ErrNoMeals", err)
+	}
+
+	_, err = uc.ShoppingList(context.Background(), "owner", plan.ID, "2024-08-07", "2024-08-05", "")
+	if !errors.Is(err, entity.ErrInvalidDateRange) {
+		t.Errorf("reversed range error = %v, want ErrInvalidDateRange", err)
+	}
+}
+
+func TestMealPlanCalendar(t *testing.T) {
+	t.Parallel()
+
+	uc, _, plan := plannedWeek(t)
+	ctx := context.Background()
+
+	if _, err := uc.Calendar(ctx, "", plan.ID, "wrong"); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("Calendar with wrong token error = %v, want ErrNotFound", err)
+	}
+
+	calendar, err := uc.Calendar(ctx, "", plan.ID, plan.FeedToken)
+	if err != nil {
+		t.Fatalf("Calendar with feed token: %v", err)
+	}
+
+	if len(calendar.Events) != 4 {
+		t.Fatalf("%d events, want 4", len(calendar.Events))
+	}
+
+	dinner := calendar.Events[1]
+	if want := time.Date(2024, 8, 5, 19, 0, 0, 0, time.UTC); !dinner.Start.Equal(want) || dinner.Summary != "plov" {
+		t.Errorf("Monday dinner = %+v, want plov at %v", dinner, want)
+	}
+}
@@ -0,0 +1,314 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_defaultReportLimit = 50
+	_maxReportLimit     = 200
+)
+
+// ModerationUseCase runs the report queue. Reported content is reached
+// through the ModerationTarget registered for its type; types without one
+// can be reported and dismissed, and warned or banned once they have one.
+type ModerationUseCase struct {
+	repo          ReportRepo
+	admin         Admin
+	users         AdminRepo
+	notifications NotificationProducer
+	audit         AuditHook
+	targets       map[string]ModerationTarget
+}
+
+func NewModerationUseCase(r ReportRepo, a Admin, users AdminRepo, n NotificationProducer, h AuditHook, targets map[string]ModerationTarget) *ModerationUseCase {
+	return &ModerationUseCase{
+		repo:          r,
+		admin:         a,
+		users:         users,
+		notifications: n,
+		audit:         h,
+		targets:       targets,
+	}
+}
+
+// Report files a report for the moderation queue.
+func (uc *ModerationUseCase) Report(ctx context.Context, report entity.Report) (*entity.Report, error) {
+	if target, ok := uc.targets[report.TargetType]; ok {
+		author, err := target.Author(ctx, report.TargetID)
+		if err != nil {
+			return nil, err
+		}
+
+		if author == report.ReporterID {
+			return nil, entity.ErrSelfAction
+		}
+	}
+
+	report.ID = uuid.NewString()
+	report.Status = entity.ReportOpen
+	report.CreatedAt = time.Now().UTC()
+
+	if err := uc.repo.Create(ctx, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (uc *ModerationUseCase) List(ctx context.Context, filter entity.ReportFilter) (*entity.ReportList, error) {
+	if filter.Limit == 0 {
+		filter.Limit = _defaultReportLimit
+	}
+
+	if filter.Limit > _maxReportLimit {
+		filter.Limit = _maxReportLimit
+	}
+
+	reports, err := uc.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ReportList{
+		Reports: reports,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+func (uc *ModerationUseCase) Get(ctx context.Context, reportID string) (*entity.Report, error) {
+	return uc.repo.Get(ctx, reportID)
+}
+
+// Triage assigns an open report to the moderator.
+func (uc *ModerationUseCase) Triage(ctx context.Context, moderatorID, reportID string) error {
+	if _, err := uc.repo.Get(ctx, reportID); err != nil {
+		return err
+	}
+
+	return uc.repo.Triage(ctx, reportID, moderatorID)
+}
+
+// Action applies the decision to the reported target and closes every
+// pending report on it as actioned. The reports are closed first, so of two
+// moderators acting at once only one applies the decision; they are reopened
+// if applying it fails.
+func (uc *ModerationUseCase) Action(ctx context.Context, moderatorID, reportID string, decision entity.ModerationDecision) error {
+	report, err := uc.pendingReport(ctx, reportID)
+	if err != nil {
+		return err
+	}
+
+	report.Action = decision.Action
+	report.Note = decision.Note
+
+	closed, err := uc.claim(ctx, moderatorID, report, entity.ReportActioned)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.apply(ctx, moderatorID, report, decision); err != nil {
+		if reopenErr := uc.repo.Reopen(ctx, closed); reopenErr != nil {
+			return errors.Join(err, reopenErr)
+		}
+
+		return err
+	}
+
+	uc.notifyReporters(ctx, moderatorID, report, closed)
+
+	return nil
+}
+
+// Dismiss closes every pending report on the target without action.
+func (uc *ModerationUseCase) Dismiss(ctx context.Context, moderatorID, reportID, note string) error {
+	report, err := uc.pendingReport(ctx, reportID)
+	if err != nil {
+		return err
+	}
+
+	report.Action = ""
+	report.Note = note
+
+	closed, err := uc.claim(ctx, moderatorID, report, entity.ReportDismissed)
+	if err != nil {
+		return err
+	}
+
+	uc.notifyReporters(ctx, moderatorID, report, closed)
+
+	return nil
+}
+
+func (uc *ModerationUseCase) pendingReport(ctx context.Context, reportID string) (*entity.Report, error) {
+	report, err := uc.repo.Get(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Status != entity.ReportOpen && report.Status != entity.ReportTriaged {
+		return nil, entity.ErrReportClosed
+	}
+
+	return report, nil
+}
+
+func (uc *ModerationUseCase) apply(ctx context.Context, moderatorID string, report *entity.Report, decision entity.ModerationDecision) error {
+	target, ok := uc.targets[report.TargetType]
+	if !ok {
+		return entity.ErrUnsupportedAction
+	}
+
+	switch decision.Action {
+	case entity.ModerationHide:
+		return uc.takedown(ctx, moderatorID, report, decision, target.Hide)
+	case entity.ModerationDelete:
+		return uc.takedown(ctx, moderatorID, report, decision, target.Delete)
+	case entity.ModerationWarn:
+		author, err := target.Author(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+
+		return uc.notifications.Notify(ctx, entity.Notification{
+			UserID:   author,
+			ActorID:  moderatorID,
+			Type:     entity.NotificationWarning,
+			EntityID: report.TargetID,
+			Message:  decision.Note,
+		})
+	case entity.ModerationBan:
+		author, err := target.Author(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+
+		// Banning revokes every session, so a moderator must not be able to
+		// reach admins or fellow moderators through a report.
+		user, err := uc.users.GetUser(ctx, author)
+		if err != nil {
+			return err
+		}
+
+		if user.Role == entity.RoleAdmin || user.Role == entity.RoleModerator {
+			return entity.ErrForbidden
+		}
+
+		reason := decision.Note
+		if reason == "" {
+			reason = report.Reason
+		}
+
+		return uc.admin.BanUser(ctx, moderatorID, author, entity.UserBan{
+			Reason: reason,
+			Until:  decision.BanUntil,
+		})
+	default:
+		return entity.ErrUnsupportedAction
+	}
+}
+
+// takedown hides or deletes the target. Recipe takedowns are audited.
+func (uc *ModerationUseCase) takedown(ctx context.Context, moderatorID string, report *entity.Report, decision entity.ModerationDecision, remove func(context.Context, string) error) error {
+	if err := remove(ctx, report.TargetID); err != nil {
+		return err
+	}
+
+	if report.TargetType == entity.ReportTargetRecipe {
+		uc.audit.Record(ctx, entity.AuditEntry{
+			Action:     entity.AuditRecipeTakedown,
+			ActorID:    moderatorID,
+			TargetType: entity.ReportTargetRecipe,
+			TargetID:   report.TargetID,
+			Reason:     decision.Note,
+			Diff: map[string]entity.AuditChange{
+				"moderation": {Old: nil, New: decision.Action},
+			},
+		})
+	}
+
+	return nil
+}
+
+// claim closes the pending reports on the target and returns them as they
+// were. It returns ErrReportClosed if someone else closed report meanwhile.
+func (uc *ModerationUseCase) claim(ctx context.Context, moderatorID string, report *entity.Report, status string) ([]entity.Report, error) {
+	now := time.Now().UTC()
+
+	report.Status = status
+	report.ModeratorID = moderatorID
+	report.ResolvedAt = &now
+
+	closed, err := uc.repo.Resolve(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range closed {
+		if closed[i].ID == report.ID {
+			return closed, nil
+		}
+	}
+
+	// Reports filed after the other moderator closed ours stay pending.
+	if err = uc.repo.Reopen(ctx, closed); err != nil {
+		return nil, err
+	}
+
+	return nil, entity.ErrReportClosed
+}
+
+// notifyReporters tells the reporter of each closed report the outcome.
+func (uc *ModerationUseCase) notifyReporters(ctx context.Context, moderatorID string, report *entity.Report, closed []entity.Report) {
+	message := report.Status
+	if report.Action != "" {
+		message += ": " + report.Action
+	}
+
+	notifications := make([]entity.Notification, 0, len(closed))
+	for _, r := range closed {
+		notifications = append(notifications, entity.Notification{
+			UserID:   r.ReporterID,
+			ActorID:  moderatorID,
+			Type:     entity.NotificationReportResolved,
+			EntityID: r.ID,
+			Message:  message,
+		})
+	}
+
+	notifyAll(ctx, uc.notifications, notifications)
+}
+
+// profileTarget lets profiles be reported. Warn and ban reach the profile's
+// owner; there is nothing to hide or delete.
+type profileTarget struct {
+	users AdminRepo
+}
+
+// NewProfileTarget is the ModerationTarget for ReportTargetProfile.
+func NewProfileTarget(r AdminRepo) ModerationTarget {
+	return &profileTarget{users: r}
+}
+
+func (t *profileTarget) Author(ctx context.Context, userID string) (string, error) {
+	user, err := t.users.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
+
+func (t *profileTarget) Hide(context.Context, string) error {
+	return entity.ErrUnsupportedAction
+}
+
+func (t *profileTarget) Delete(context.Context, string) error {
+	return entity.ErrUnsupportedAction
+}
@@ -0,0 +1,195 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+type memReports struct {
+	usecase.ReportRepo
+	reports map[string]*entity.Report
+}
+
+func (m *memReports) Get(_ context.Context, reportID string) (*entity.Report, error) {
+	report, ok := m.reports[reportID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	copied := *report
+
+	return &copied, nil
+}
+
+func (m *memReports) Resolve(_ context.Context, resolved *entity.Report) ([]entity.Report, error) {
+	var closed []entity.Report
+
+	for id, report := range m.reports {
+		if report.TargetType != resolved.TargetType || report.TargetID != resolved.TargetID ||
+			(report.Status != entity.ReportOpen && report.Status != entity.ReportTriaged) {
+			continue
+		}
+
+		closed = append(closed, *report)
+
+		updated := *report
+		updated.Status = resolved.Status
+		updated.ModeratorID = resolved.ModeratorID
+		updated.Action = resolved.Action
+		updated.Note = resolved.Note
+		updated.ResolvedAt = resolved.ResolvedAt
+		m.reports[id] = &updated
+	}
+
+	return closed, nil
+}
+
+func (m *memReports) Reopen(_ context.Context, reports []entity.Report) error {
+	for i := range reports {
+		report := reports[i]
+		m.reports[report.ID] = &report
+	}
+
+	return nil
+}
+
+// memUsers serves users by ID for role checks.
+type memUsers struct {
+	usecase.AdminRepo
+	users map[string]entity.User
+}
+
+func (m *memUsers) GetUser(_ context.Context, userID string) (*entity.User, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+// recordingAdmin records who was banned. during runs inside BanUser, while
+// the ban is in progress.
+type recordingAdmin struct {
+	usecase.Admin
+	banned []string
+	during func()
+}
+
+func (a *recordingAdmin) BanUser(_ context.Context, _, userID string, _ entity.UserBan) error {
+	if a.during != nil {
+		a.during()
+	}
+
+	a.banned = append(a.banned, userID)
+
+	return nil
+}
+
+// profiles are reported by user ID, so the author is the target itself.
+type profiles struct {
+	usecase.ModerationTarget
+}
+
+func (profiles) Author(_ context.Context, userID string) (string, error) {
+	return userID, nil
+}
+
+func TestModerationBan(t *testing.T) {
+	t.Parallel()
+
+	reports := &memReports{reports: map[string]*entity.Report{}}
+	for _, target := range []string{"admin", "moderator", "user"} {
+		reports.reports[target] = &entity.Report{
+			ID:         target,
+			ReporterID: "reporter",
+			TargetType: entity.ReportTargetProfile,
+			TargetID:   target,
+			Status:     entity.ReportOpen,
+		}
+	}
+
+	users := &memUsers{users: map[string]entity.User{
+		"admin":     {ID: "admin", Role: entity.RoleAdmin},
+		"moderator": {ID: "moderator", Role: entity.RoleModerator},
+		"user":      {ID: "user", Role: entity.RoleUser},
+	}}
+	admin := &recordingAdmin{}
+	uc := usecase.NewModerationUseCase(reports, admin, users, &sentNotifications{}, nil, map[string]usecase.ModerationTarget{
+		entity.ReportTargetProfile: profiles{},
+	})
+	ctx := context.Background()
+	ban := entity.ModerationDecision{Action: entity.ModerationBan}
+
+	for _, staff := range []string{"admin", "moderator"} {
+		if err := uc.Action(ctx, "mod", staff, ban); !errors.Is(err, entity.ErrForbidden) {
+			t.Errorf("banning the %s error = %v, want ErrForbidden", staff, err)
+		}
+
+		if reports.reports[staff].Status != entity.ReportOpen {
+			t.Errorf("report on the %s = %s, want it left open", staff, reports.reports[staff].Status)
+		}
+	}
+
+	if err := uc.Action(ctx, "mod", "user", ban); err != nil {
+		t.Fatalf("banning a user: %v", err)
+	}
+
+	if len(admin.banned) != 1 || admin.banned[0] != "user" {
+		t.Errorf("banned %v, want only the user", admin.banned)
+	}
+}
+
+func TestModerationActionOnce(t *testing.T) {
+	t.Parallel()
+
+	reports := &memReports{reports: map[string]*entity.Report{}}
+	for _, id := range []string{"first", "second"} {
+		reports.reports[id] = &entity.Report{
+			ID:         id,
+			ReporterID: "reporter-" + id,
+			TargetType: entity.ReportTargetProfile,
+			TargetID:   "user",
+			Status:     entity.ReportOpen,
+		}
+	}
+
+	users := &memUsers{users: map[string]entity.User{
+		"user": {ID: "user", Role: entity.RoleUser},
+	}}
+	admin := &recordingAdmin{}
+	uc := usecase.NewModerationUseCase(reports, admin, users, &sentNotifications{}, nil, map[string]usecase.ModerationTarget{
+		entity.ReportTargetProfile: profiles{},
+	})
+	ctx := context.Background()
+	ban := entity.ModerationDecision{Action: entity.ModerationBan}
+
+	// A second moderator acts on the other report while the first ban runs.
+	var concurrent error
+	admin.during = func() {
+		admin.during = nil
+		concurrent = uc.Action(ctx, "other-mod", "second", ban)
+	}
+
+	if err := uc.Action(ctx, "mod", "first", ban); err != nil {
+		t.Fatalf("first action: %v", err)
+	}
+
+	if !errors.Is(concurrent, entity.ErrReportClosed) {
+		t.Errorf("concurrent action error = %v, want ErrReportClosed", concurrent)
+	}
+
+	if len(admin.banned) != 1 {
+		t.Errorf("banned %v, want one ban", admin.banned)
+	}
+
+	for id, report := range reports.reports {
+		if report.Status != entity.ReportActioned || report.ModeratorID != "mod" {
+			t.Errorf("report %s = %s by %q, want actioned by mod", id, report.Status, report.ModeratorID)
+		}
+	}
+}
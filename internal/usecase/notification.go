@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_defaultNotificationLimit = 20
+	_maxNotificationLimit     = 100
+	_unreadCountTTL           = 10 * time.Minute
+)
+
+type NotificationUseCase struct {
+	repo        NotificationRepo
+	events      EventPublisher
+	RedisClient *redis.Client
+}
+
+func NewNotificationUseCase(r NotificationRepo, e EventPublisher, RedisClient *redis.Client) *NotificationUseCase {
+	return &NotificationUseCase{
+		repo:        r,
+		events:      e,
+		RedisClient: RedisClient,
+	}
+}
+
+func unreadCountKey(userID string) string {
+	return "notifications:unread:" + userID
+}
+
+// Notify stores a notification for n.UserID. Users are never notified about
+// their own actions.
+func (uc *NotificationUseCase) Notify(ctx context.Context, n entity.Notification) error {
+	if n.UserID == "" || n.UserID == n.ActorID {
+		return nil
+	}
+
+	n.ID = uuid.NewString()
+	n.IsRead = false
+	n.CreatedAt = time.Now().UTC()
+
+	if err := uc.repo.Create(ctx, &n); err != nil {
+		return err
+	}
+
+	uc.invalidateUnreadCount(ctx, n.UserID)
+
+	pushEvent(ctx, uc.events, entity.Event{Type: entity.EventNotification}, n, n.UserID)
+
+	return nil
+}
+
+// pushEvent sends event with payload as its data to each of userIDs. The
+// change is already saved, so this is best effort: clients that miss the
+// event catch up when they next load it.
+func pushEvent(ctx context.Context, events EventPublisher, event entity.Event, payload interface{}, userIDs ...string) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	event.Data = data
+
+	for _, userID := range userIDs {
+		_ = events.PublishUserEvent(ctx, userID, event) //nolint:errcheck // best effort
+	}
+}
+
+// notifyAll sends each notification, best effort like pushEvent: what they
+// report is already saved, and a lost one only costs the recipient the news.
+func notifyAll(ctx context.Context, producer NotificationProducer, notifications []entity.Notification) {
+	for _, n := range notifications {
+		_ = producer.Notify(ctx, n) //nolint:errcheck // best effort
+	}
+}
+
+func (uc *NotificationUseCase) List(ctx context.Context, userID string, limit, offset uint64) (*entity.NotificationList, error) {
+	if limit == 0 {
+		limit = _defaultNotificationLimit
+	}
+	if limit > _maxNotificationLimit {
+		limit = _maxNotificationLimit
+	}
+
+	notifications, err := uc.repo.List(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.NotificationList{
+		Notifications: notifications,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+func (uc *NotificationUseCase) MarkRead(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrNotFound
+	}
+
+	found, err := uc.repo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return entity.ErrNotFound
+	}
+
+	uc.invalidateUnreadCount(ctx, userID)
+
+	return nil
+}
+
+func (uc *NotificationUseCase) MarkAllRead(ctx context.Context, userID string) error {
+	if err := uc.repo.MarkAllRead(ctx, userID); err != nil {
+		return err
+	}
+
+	uc.invalidateUnreadCount(ctx, userID)
+
+	return nil
+}
+
+// UnreadCount is served from Redis and falls back to Postgres on a cache miss.
+func (uc *NotificationUseCase) UnreadCount(ctx context.Context, userID string) (int, error) {
+	cached, err := uc.RedisClient.Get(ctx, unreadCountKey(userID)).Result()
+	if err == nil {
+		if count, convErr := strconv.Atoi(cached); convErr == nil {
+			return count, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	count, err := uc.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := uc.RedisClient.Set(ctx, unreadCountKey(userID), count, _unreadCountTTL).Err(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// invalidateUnreadCount drops the cached counter; the next read repopulates it.
+func (uc *NotificationUseCase) invalidateUnreadCount(ctx context.Context, userID string) {
+	uc.RedisClient.Del(ctx, unreadCountKey(userID))
+}
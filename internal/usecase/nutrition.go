@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/units"
+)
+
+const (
+	_defaultCatalogLimit = 20
+	_maxCatalogLimit     = 100
+	_maxCatalogRows      = 10000
+	// _assumedDensity weighs volumes of ingredients without a known density
+	// as water.
+	_assumedDensity = 1.0
+)
+
+// NutritionUseCase keeps the ingredient catalog recipe nutrition is
+// computed from. Recipes pick up catalog changes the next time they are saved.
+type NutritionUseCase struct {
+	repo CatalogRepo
+}
+
+func NewNutritionUseCase(r CatalogRepo) *NutritionUseCase {
+	return &NutritionUseCase{
+		repo: r,
+	}
+}
+
+func (uc *NutritionUseCase) Search(ctx context.Context, filter entity.CatalogFilter) (*entity.CatalogList, error) {
+	if filter.Limit == 0 {
+		filter.Limit = _defaultCatalogLimit
+	}
+	if filter.Limit > _maxCatalogLimit {
+		filter.Limit = _maxCatalogLimit
+	}
+
+	ingredients, err := uc.repo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.CatalogList{
+		Ingredients: ingredients,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+	}, nil
+}
+
+// Import reads catalog entries from CSV and saves them, matching existing
+// entries by name. Nothing is saved if any line is invalid.
+//
+// The first line names the columns: name is required; calories, protein,
+// fat, carbohydrates and fiber are per 100 g; density is in g/ml and
+// piece_weight in grams; aisle is one of entity.Aisles, "other" if empty.
+// aliases, allergens (contained) and diets (fitted) are separated by "|".
+// Unknown columns are ignored.
+func (uc *NutritionUseCase) Import(ctx context.Context, r io.Reader) (*entity.CatalogImport, error) {
+	ingredients, err := parseCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.Upsert(ctx, ingredients)
+}
+
+func parseCatalog(r io.Reader) ([]entity.CatalogIngredient, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &entity.CatalogImportError{Line: 1, Reason: "missing header"}
+	}
+
+	if err != nil {
+		return nil, catalogReadError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, &entity.CatalogImportError{Line: 1, Reason: "missing name column"}
+	}
+
+	var (
+		ingredients []entity.CatalogIngredient
+		seen        = make(map[string]int)
+		now         = time.Now().UTC()
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, catalogReadError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if len(ingredients) == _maxCatalogRows {
+			return nil, &entity.CatalogImportError{Line: line, Reason: "too many lines"}
+		}
+
+		ingredient, err := parseCatalogRecord(columns, record)
+		if err != nil {
+			return nil, &entity.CatalogImportError{Line: line, Reason: err.Error()}
+		}
+
+		key := strings.ToLower(ingredient.Name)
+		if first, ok := seen[key]; ok {
+			return nil, &entity.CatalogImportError{Line: line, Reason: "duplicate of line " + strconv.Itoa(first)}
+		}
+
+		seen[key] = line
+		ingredient.ID = uuid.NewString()
+		ingredient.UpdatedAt = now
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, nil
+}
+
+func catalogReadError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &entity.CatalogImportError{Line: parseErr.Line, Reason: parseErr.Err.Error()}
+	}
+
+	return err
+}
+
+func parseCatalogRecord(columns map[string]int, record []string) (entity.CatalogIngredient, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	ingredient := entity.CatalogIngredient{
+		Name:    field("name"),
+		Aliases: catalogList(field("aliases")),
+		Aisle:   strings.ToLower(field("aisle")),
+	}
+
+	if ingredient.Name == "" {
+		return ingredient, errors.New("name is empty")
+	}
+
+	if ingredient.Aisle == "" {
+		ingredient.Aisle = entity.AisleOther
+	}
+
+	if !entity.ValidAisle(ingredient.Aisle) {
+		return ingredient, errors.New("unknown aisle " + ingredient.Aisle)
+	}
+
+	numbers := []struct {
+		column string
+		value  *float64
+	}{
+		{"calories", &ingredient.Per100g.Calories},
+		{"protein", &ingredient.Per100g.Protein},
+		{"fat", &ingredient.Per100g.Fat},
+		{"carbohydrates", &ingredient.Per100g.Carbohydrates},
+		{"fiber", &ingredient.Per100g.Fiber},
+	}
+
+	for _, n := range numbers {
+		value, err := catalogNumber(n.column, field(n.column))
+		if err != nil {
+			return ingredient, err
+		}
+
+		if value != nil {
+			*n.value = *value
+		}
+	}
+
+	var err error
+	if ingredient.Density, err = catalogNumber("density", field("density")); err != nil {
+		return ingredient, err
+	}
+
+	if ingredient.PieceWeight, err = catalogNumber("piece_weight", field("piece_weight")); err != nil {
+		return ingredient, err
+	}
+
+	ingredient.Allergens = catalogList(field("allergens"))
+	for _, allergen := range ingredient.Allergens {
+		if !entity.ValidAllergen(allergen) {
+			return ingredient, errors.New("unknown allergen " + allergen)
+		}
+	}
+
+	ingredient.Diets = catalogList(field("diets"))
+	for _, diet := range ingredient.Diets {
+		if !entity.ValidDiet(diet) {
+			return ingredient, errors.New("unknown diet " + diet)
+		}
+	}
+
+	return ingredient, nil
+}
+
+// catalogList splits a "|" separated field into lowercase values.
+func catalogList(value string) []string {
+	list := []string{}
+
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// catalogNumber parses a non-negative number; empty fields give nil.
+func catalogNumber(column, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return nil, errors.New(column + " must be a non-negative number")
+	}
+
+	return &n, nil
+}
+
+// catalogIndex finds the catalog entry of a recipe line: by CatalogID when
+// the line has one, otherwise by name or alias.
+type catalogIndex struct {
+	byID   map[string]*entity.CatalogIngredient
+	byName map[string]*entity.CatalogIngredient
+}
+
+func newCatalogIndex(catalog []entity.CatalogIngredient) *catalogIndex {
+	index := &catalogIndex{
+		byID:   make(map[string]*entity.CatalogIngredient, len(catalog)),
+		byName: make(map[string]*entity.CatalogIngredient, len(catalog)),
+	}
+
+	for i := range catalog {
+		index.byID[catalog[i].ID] = &catalog[i]
+		index.byName[strings.ToLower(catalog[i].Name)] = &catalog[i]
+	}
+
+	// Names win over aliases of other entries.
+	for i := range catalog {
+		for _, alias := range catalog[i].Aliases {
+			if _, ok := index.byName[alias]; !ok {
+				index.byName[alias] = &catalog[i]
+			}
+		}
+	}
+
+	return index
+}
+
+func (x *catalogIndex) entry(ingredient entity.Ingredient) *entity.CatalogIngredient {
+	if ingredient.CatalogID != "" {
+		return x.byID[ingredient.CatalogID]
+	}
+
+	return x.byName[catalogName(ingredient.Name)]
+}
+
+func catalogName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func computeNutrition(recipe *entity.Recipe, catalog *catalogIndex) *entity.RecipeNutrition {
+	result := &entity.RecipeNutrition{ComputedAt: time.Now().UTC()}
+
+	for _, ingredient := range recipe.Ingredients {
+		entry := catalog.entry(ingredient)
+
+		grams, estimated, ok := weigh(ingredient, entry)
+		if !ok {
+			result.Missing = append(result.Missing, ingredient.ID)
+			continue
+		}
+
+		result.Total = result.Total.Add(entry.Per100g.Scale(grams / 100))
+		result.Estimated = result.Estimated || estimated
+	}
+
+	result.Estimated = result.Estimated || len(result.Missing) > 0
+
+	if recipe.Servings > 0 {
+		perServing := result.Total.Scale(1 / float64(recipe.Servings))
+		result.PerServing = &perServing
+	}
+
+	return result
+}
+
+// weigh returns the weight of the line in grams. estimated is set when a
+// volume had to be weighed as water.
+func weigh(ingredient entity.Ingredient, entry *entity.CatalogIngredient) (grams float64, estimated, ok bool) {
+	if entry == nil || ingredient.Quantity <= 0 {
+		return 0, false, false
+	}
+
+	amount, dimension, ok := units.Normalize(ingredient.Quantity, ingredient.Unit)
+	if !ok {
+		return 0, false, false
+	}
+
+	switch dimension {
+	case units.Mass:
+		return amount, false, true
+	case units.Volume:
+		if entry.Density == nil {
+			return amount * _assumedDensity, true, true
+		}
+
+		return amount * *entry.Density, false, true
+	case units.Count:
+		if entry.PieceWeight == nil {
+			return 0, false, false
+		}
+
+		return amount * *entry.PieceWeight, false, true
+	default:
+		return 0, false, false
+	}
+}
@@ -0,0 +1,170 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// memCatalog matches by ID and lowercase name only.
+type memCatalog struct {
+	usecase.CatalogRepo
+	entries  []entity.CatalogIngredient
+	imported []entity.CatalogIngredient
+}
+
+func (m *memCatalog) Match(_ context.Context, ids, names []string) ([]entity.CatalogIngredient, error) {
+	var found []entity.CatalogIngredient
+
+	for _, e := range m.entries {
+		for _, key := range append(append([]string{}, ids...), names...) {
+			if key == e.ID || key == strings.ToLower(e.Name) {
+				found = append(found, e)
+				break
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func (m *memCatalog) Upsert(_ context.Context, entries []entity.CatalogIngredient) (*entity.CatalogImport, error) {
+	m.imported = entries
+
+	return &entity.CatalogImport{Created: len(entries)}, nil
+}
+
+// memRecipes stores created recipes; other repo methods are not used.
+type memRecipes struct {
+	usecase.RecipeRepo
+	created *entity.Recipe
+}
+
+func (m *memRecipes) Create(_ context.Context, recipe *entity.Recipe) error {
+	m.created = recipe
+
+	return nil
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestRecipeNutrition(t *testing.T) {
+	t.Parallel()
+
+	catalog := &memCatalog{entries: []entity.CatalogIngredient{
+		{ID: "11111111-1111-1111-1111-111111111111", Name: "Rice", Per100g: entity.Nutrition{Calories: 360, Carbohydrates: 80}},
+		{ID: "22222222-2222-2222-2222-222222222222", Name: "Egg", Per100g: entity.Nutrition{Calories: 150, Protein: 12}, PieceWeight: float(50)},
+		{ID: "33333333-3333-3333-3333-333333333333", Name: "Oil", Per100g: entity.Nutrition{Calories: 900, Fat: 100}},
+	}}
+
+	uc := usecase.NewRecipeUseCase(&memRecipes{}, catalog, nil)
+
+	recipe, err := uc.Create(context.Background(), entity.Recipe{
+		Servings: 2,
+		Ingredients: []entity.Ingredient{
+			{ID: "rice", Name: "rice", Quantity: 0.5, Unit: "kg"},
+			{ID: "egg", CatalogID: "22222222-2222-2222-2222-222222222222", Name: "eggs", Quantity: 2},
+			{ID: "oil", Name: "oil", Quantity: 2, Unit: "tbsp"},
+			{ID: "salt", Name: "salt", Quantity: 1, Unit: "pinch"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	n := recipe.Nutrition
+	if n == nil {
+		t.Fatal("Nutrition is nil")
+	}
+
+	// 500 g rice + 100 g egg + 30 ml oil weighed as water.
+	if want := 1800.0 + 150 + 270; math.Abs(n.Total.Calories-want) > 1e-9 {
+		t.Errorf("Total.Calories = %v, want %v", n.Total.Calories, want)
+	}
+
+	if n.PerServing == nil || math.Abs(n.PerServing.Protein-6) > 1e-9 {
+		t.Errorf("PerServing = %+v, want 6 g protein", n.PerServing)
+	}
+
+	if !n.Estimated || len(n.Missing) != 1 || n.Missing[0] != "salt" {
+		t.Errorf("Estimated = %v, Missing = %v; want true, [salt]", n.Estimated, n.Missing)
+	}
+}
+
+func TestCatalogImport(t *testing.T) {
+	t.Parallel()
+
+	const file = `name,calories,protein,fat,carbohydrates,fiber,density,piece_weight,aliases
+Milk,64,3.2,3.6,"4,8",0,1.03,,sut|молоко
+Egg,155,13,11,1.1,0,,50,
+`
+
+	catalog := &memCatalog{}
+	uc := usecase.NewNutritionUseCase(catalog)
+
+	result, err := uc.Import(context.Background(), strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	if result.Created != 2 || len(catalog.imported) != 2 {
+		t.Fatalf("Import = %+v, want 2 entries", result)
+	}
+
+	milk := catalog.imported[0]
+	if milk.Per100g.Carbohydrates != 4.8 || milk.Density == nil || *milk.Density != 1.03 || milk.PieceWeight != nil {
+		t.Errorf("milk = %+v", milk)
+	}
+
+	if len(milk.Aliases) != 2 || milk.Aliases[1] != "молоко" {
+		t.Errorf("milk aliases = %v", milk.Aliases)
+	}
+}
+
+func TestCatalogImportInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		file string
+		line int
+	}{
+		{"no name column", "title,calories\nMilk,64\n", 1},
+		{"negative value", "name,calories\nMilk,64\nEgg,-1\n", 3},
+		{"not a number", "name,protein\nMilk,lots\n", 2},
+		{"duplicate name", "name\nMilk\nmilk\n", 3},
+		{"empty name", "name,calories\n,64\n", 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog := &memCatalog{}
+
+			_, err := usecase.NewNutritionUseCase(catalog).Import(context.Background(), strings.NewReader(tt.file))
+
+			var importErr *entity.CatalogImportError
+			if !errors.As(err, &importErr) || importErr.Line != tt.line {
+				t.Fatalf("Import() error = %v, want error on line %d", err, tt.line)
+			}
+
+			if !errors.Is(err, entity.ErrInvalidCatalog) {
+				t.Errorf("error does not match ErrInvalidCatalog")
+			}
+
+			if catalog.imported != nil {
+				t.Errorf("entries saved despite the error")
+			}
+		})
+	}
+}
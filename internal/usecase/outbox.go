@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/logger"
+)
+
+const (
+	_defaultOutboxBatch = 100
+	_defaultOutboxLease = 30 * time.Second
+	_defaultOutboxPoll  = time.Second
+)
+
+// newOutboxEvent builds an event for a repo to store with its domain change.
+// The idempotency key must be stable for the same logical event.
+func newOutboxEvent(eventType, idempotencyKey string, payload interface{}) (entity.OutboxEvent, error) {
+	byteData, err := json.Marshal(payload)
+	if err != nil {
+		return entity.OutboxEvent{}, err
+	}
+
+	return entity.OutboxEvent{
+		ID:             uuid.NewString(),
+		Type:           eventType,
+		IdempotencyKey: idempotencyKey,
+		Payload:        byteData,
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// OutboxRelay publishes stored events with at-least-once delivery: a row is
+// marked published only after the bus accepted it.
+type OutboxRelay struct {
+	repo  OutboxRepo
+	bus   EventBus
+	l     logger.Interface
+	batch int
+	lease time.Duration
+	poll  time.Duration
+}
+
+func NewOutboxRelay(r OutboxRepo, b EventBus, l logger.Interface, batch int, lease, poll time.Duration) *OutboxRelay {
+	if batch <= 0 {
+		batch = _defaultOutboxBatch
+	}
+
+	if lease <= 0 {
+		lease = _defaultOutboxLease
+	}
+
+	if poll <= 0 {
+		poll = _defaultOutboxPoll
+	}
+
+	return &OutboxRelay{
+		repo:  r,
+		bus:   b,
+		l:     l,
+		batch: batch,
+		lease: lease,
+		poll:  poll,
+	}
+}
+
+// Run relays until ctx is cancelled.
+func (uc *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.poll)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while batches come back full.
+		for {
+			n, err := uc.RelayOnce(ctx)
+			if err != nil {
+				uc.l.Error(err, "usecase - OutboxRelay - Run - RelayOnce")
+			}
+
+			if err != nil || n < uc.batch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes one batch and returns the number of claimed events.
+func (uc *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := uc.repo.Claim(ctx, uc.batch, uc.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		if err := uc.bus.PublishWithID(ctx, e.IdempotencyKey, e.Type, e.Payload); err != nil {
+			uc.l.Error(err, "usecase - OutboxRelay - RelayOnce - PublishWithID "+e.Type)
+
+			if err := uc.repo.MarkFailed(ctx, e.ID, err.Error()); err != nil {
+				return len(events), err
+			}
+
+			continue
+		}
+
+		if err := uc.repo.MarkPublished(ctx, e.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/units"
+)
+
+const (
+	// _cookableCandidates caps the recipes ranked per request. The repo
+	// returns those using the pantry for most of their lines first.
+	_cookableCandidates    = 500
+	_defaultCookableLimit  = 20
+	_maxCookableLimit      = 50
+	_defaultReminderPeriod = time.Hour
+	_defaultExpiryNotice   = 2
+	_reminderBatch         = 100
+)
+
+// PantryUseCase keeps what users have at home and finds recipes they can
+// cook with it.
+type PantryUseCase struct {
+	repo    PantryRepo
+	recipes RecipeRepo
+	catalog CatalogRepo
+}
+
+func NewPantryUseCase(r PantryRepo, recipes RecipeRepo, c CatalogRepo) *PantryUseCase {
+	return &PantryUseCase{
+		repo:    r,
+		recipes: recipes,
+		catalog: c,
+	}
+}
+
+func (uc *PantryUseCase) List(ctx context.Context, userID string) ([]entity.PantryItem, error) {
+	return uc.repo.List(ctx, userID)
+}
+
+func (uc *PantryUseCase) Add(ctx context.Context, userID string, item entity.PantryItem) (*entity.PantryItem, error) {
+	count, err := uc.repo.Count(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= entity.MaxPantryItems {
+		return nil, entity.ErrPantryFull
+	}
+
+	if err = uc.prepare(ctx, &item); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	item.ID = uuid.NewString()
+	item.UserID = userID
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	if err = uc.repo.Create(ctx, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// Update replaces the item. A new expiry date gets a new reminder.
+func (uc *PantryUseCase) Update(ctx context.Context, userID string, item entity.PantryItem) (*entity.PantryItem, error) {
+	if err := uc.prepare(ctx, &item); err != nil {
+		return nil, err
+	}
+
+	item.UserID = userID
+	item.UpdatedAt = time.Now().UTC()
+
+	if err := uc.repo.Update(ctx, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (uc *PantryUseCase) Delete(ctx context.Context, userID, itemID string) error {
+	return uc.repo.Delete(ctx, userID, itemID)
+}
+
+// prepare checks the catalog link; linked items are named after the entry
+// unless the user named them.
+func (uc *PantryUseCase) prepare(ctx context.Context, item *entity.PantryItem) error {
+	item.Name = strings.TrimSpace(item.Name)
+
+	if item.CatalogID != "" {
+		entries, err := uc.catalog.Match(ctx, []string{item.CatalogID}, nil)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			return entity.ErrInvalidPantryItem
+		}
+
+		if item.Name == "" {
+			item.Name = entries[0].Name
+		}
+	}
+
+	if item.Name == "" {
+		return entity.ErrInvalidPantryItem
+	}
+
+	if item.ExpiresOn != "" {
+		if _, err := time.Parse(entity.DateLayout, item.ExpiresOn); err != nil {
+			return entity.ErrInvalidPantryItem
+		}
+	}
+
+	return nil
+}
+
+// pantryStock is what the pantry holds of one ingredient. amounts are in
+// the base unit of each dimension; some is set when an item has no
+// comparable quantity, which covers any amount.
+type pantryStock struct {
+	amounts map[units.Dimension]float64
+	some    bool
+}
+
+// Cookable ranks published recipes by how many of their required
+// ingredients the pantry covers, leaving out those missing more than
+// filter.MaxMissing. Items are matched through the catalog, so "eggs" in
+// the pantry covers a recipe's "egg".
+func (uc *PantryUseCase) Cookable(ctx context.Context, userID string, filter entity.CookableFilter) ([]entity.RecipeMatch, error) {
+	if filter.MaxMissing < 0 || filter.MaxMissing > entity.MaxMissingIngredients {
+		filter.MaxMissing = entity.MaxMissingIngredients
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = _defaultCookableLimit
+	}
+
+	if filter.Limit > _maxCookableLimit {
+		filter.Limit = _maxCookableLimit
+	}
+
+	items, err := uc.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Format(entity.DateLayout)
+
+	var ids, names []string
+
+	for i := range items {
+		if items[i].Expired(today) {
+			continue
+		}
+
+		if items[i].CatalogID != "" {
+			ids = append(ids, items[i].CatalogID)
+		}
+
+		names = append(names, catalogName(items[i].Name))
+	}
+
+	matches := []entity.RecipeMatch{}
+	if len(names) == 0 {
+		return matches, nil
+	}
+
+	catalog, err := uc.catalog.Match(ctx, ids, names)
+	if err != nil {
+		return nil, err
+	}
+
+	index := newCatalogIndex(catalog)
+	stock := make(map[string]*pantryStock)
+
+	for i := range items {
+		if items[i].Expired(today) {
+			continue
+		}
+
+		key := ingredientKey(index, entity.Ingredient{Name: items[i].Name, CatalogID: items[i].CatalogID})
+		if stock[key] == nil {
+			stock[key] = &pantryStock{amounts: make(map[units.Dimension]float64)}
+		}
+
+		amount, dimension, ok := units.Normalize(items[i].Quantity, items[i].Unit)
+		if !ok || items[i].Quantity <= 0 {
+			stock[key].some = true
+			continue
+		}
+
+		stock[key].amounts[dimension] += amount
+	}
+
+	// Recipes may name an ingredient by any of its catalog names.
+	for i := range catalog {
+		ids = append(ids, catalog[i].ID)
+		names = append(names, strings.ToLower(catalog[i].Name))
+		names = append(names, catalog[i].Aliases...)
+	}
+
+	recipes, err := uc.recipes.ListPublished(ctx, entity.RecipeFilter{
+		IngredientIDs:   ids,
+		IngredientNames: names,
+		Limit:           _cookableCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range recipes {
+		match := matchRecipe(&recipes[i], index, stock)
+		if len(match.Missing) <= filter.MaxMissing {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Coverage != matches[j].Coverage {
+			return matches[i].Coverage > matches[j].Coverage
+		}
+
+		return len(matches[i].Missing) < len(matches[j].Missing)
+	})
+
+	if uint64(len(matches)) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+
+	return matches, nil
+}
+
+// matchRecipe counts the required lines the stock covers. A line is
+// covered when the pantry has the ingredient and, if both quantities are
+// comparable, enough of it.
+func matchRecipe(recipe *entity.Recipe, index *catalogIndex, stock map[string]*pantryStock) entity.RecipeMatch {
+	match := entity.RecipeMatch{Recipe: *recipe, Missing: []string{}}
+
+	required := make([]entity.Ingredient, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.Quantity > 0 {
+			required = append(required, ingredient)
+		}
+	}
+
+	if len(required) == 0 {
+		required = recipe.Ingredients
+	}
+
+	for _, ingredient := range required {
+		if covers(stock[ingredientKey(index, ingredient)], ingredient) {
+			match.Have++
+		} else {
+			match.Missing = append(match.Missing, ingredient.Name)
+		}
+	}
+
+	match.Required = len(required)
+	if match.Required > 0 {
+		match.Coverage = float64(match.Have) / float64(match.Required)
+	}
+
+	return match
+}
+
+func covers(s *pantryStock, ingredient entity.Ingredient) bool {
+	if s == nil {
+		return false
+	}
+
+	need, dimension, ok := units.Normalize(ingredient.Quantity, ingredient.Unit)
+	if s.some || !ok || need <= 0 {
+		return true
+	}
+
+	have, comparable := s.amounts[dimension]
+	if !comparable {
+		// Counted in other units, such as pieces against grams.
+		return true
+	}
+
+	return have >= need
+}
+
+// ingredientKey identifies an ingredient by its catalog entry, or by name
+// outside the catalog.
+func ingredientKey(index *catalogIndex, ingredient entity.Ingredient) string {
+	if entry := index.entry(ingredient); entry != nil {
+		return entry.ID
+	}
+
+	if ingredient.CatalogID != "" {
+		return ingredient.CatalogID
+	}
+
+	return catalogName(ingredient.Name)
+}
+
+// PantryReminder notifies users of pantry items expiring within a few
+// days. Each expiry date is reminded of once, even with several app
+// instances running it.
+type PantryReminder struct {
+	repo          PantryRepo
+	notifications NotificationProducer
+	l             logger.Interface
+	interval      time.Duration
+	days          int
+}
+
+func NewPantryReminder(r PantryRepo, n NotificationProducer, l logger.Interface, interval time.Duration, days int) *PantryReminder {
+	if interval <= 0 {
+		interval = _defaultReminderPeriod
+	}
+
+	if days <= 0 {
+		days = _defaultExpiryNotice
+	}
+
+	return &PantryReminder{
+		repo:          r,
+		notifications: n,
+		l:             l,
+		interval:      interval,
+		days:          days,
+	}
+}
+
+// Run sends reminders until ctx is cancelled.
+func (uc *PantryReminder) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := uc.remind(ctx, time.Now().UTC())
+			if err != nil {
+				uc.l.Error(err, "usecase - PantryReminder - Run - remind")
+			}
+
+			if err != nil || n < _reminderBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// remind claims a batch of expiring items and sends each user one
+// notification listing theirs. It returns the number of items claimed.
+func (uc *PantryReminder) remind(ctx context.Context, now time.Time) (int, error) {
+	until := now.AddDate(0, 0, uc.days).Format(entity.DateLayout)
+
+	items, err := uc.repo.ClaimExpiring(ctx, until, _reminderBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var users []string
+
+	byUser := make(map[string][]string)
+
+	for _, item := range items {
+		if _, ok := byUser[item.UserID]; !ok {
+			users = append(users, item.UserID)
+		}
+
+		byUser[item.UserID] = append(byUser[item.UserID], item.Name+" ("+item.ExpiresOn+")")
+	}
+
+	notifications := make([]entity.Notification, 0, len(users))
+	for _, userID := range users {
+		notifications = append(notifications, entity.Notification{
+			UserID:  userID,
+			ActorID: entity.SystemActorID,
+			Type:    entity.NotificationPantryExpiry,
+			Message: "Expiring soon: " + strings.Join(byUser[userID], ", "),
+		})
+	}
+
+	notifyAll(ctx, uc.notifications, notifications)
+
+	return len(items), nil
+}
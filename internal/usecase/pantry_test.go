@@ -0,0 +1,172 @@
+package usecase_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// memPantry serves fixed items; ClaimExpiring hands out the unclaimed ones
+// due by the date.
+type memPantry struct {
+	usecase.PantryRepo
+	items   []entity.PantryItem
+	claimed map[string]bool
+}
+
+func (m *memPantry) List(context.Context, string) ([]entity.PantryItem, error) {
+	return m.items, nil
+}
+
+func (m *memPantry) ClaimExpiring(_ context.Context, until string, _ int) ([]entity.PantryItem, error) {
+	var due []entity.PantryItem
+
+	for _, item := range m.items {
+		if item.ExpiresOn != "" && item.ExpiresOn <= until && !m.claimed[item.ID] {
+			m.claimed[item.ID] = true
+			due = append(due, item)
+		}
+	}
+
+	return due, nil
+}
+
+// listedRecipes returns its recipes for any published list.
+type listedRecipes struct {
+	usecase.RecipeRepo
+	recipes []entity.Recipe
+	filter  entity.RecipeFilter
+}
+
+func (m *listedRecipes) ListPublished(_ context.Context, filter entity.RecipeFilter) ([]entity.Recipe, error) {
+	m.filter = filter
+
+	return m.recipes, nil
+}
+
+type sentNotifications struct {
+	usecase.NotificationProducer
+	sent []entity.Notification
+}
+
+func (s *sentNotifications) Notify(_ context.Context, n entity.Notification) error {
+	s.sent = append(s.sent, n)
+
+	return nil
+}
+
+func TestCookable(t *testing.T) {
+	t.Parallel()
+
+	catalog := &memCatalog{entries: []entity.CatalogIngredient{
+		{ID: "egg", Name: "Egg"},
+		{ID: "rice", Name: "Rice"},
+	}}
+	pantry := &memPantry{items: []entity.PantryItem{
+		{Name: "egg", CatalogID: "egg", Quantity: 6},
+		{Name: "Rice", Quantity: 1, Unit: "kg"},
+		{Name: "tomato"},
+		{Name: "milk", ExpiresOn: "2000-01-01"},
+	}}
+	recipes := &listedRecipes{recipes: []entity.Recipe{
+		{ID: "pancakes", Ingredients: []entity.Ingredient{
+			{Name: "egg", Quantity: 2},
+			{Name: "milk", Quantity: 500, Unit: "ml"},
+			{Name: "flour", Quantity: 200, Unit: "g"},
+			{Name: "sugar", Quantity: 1, Unit: "tbsp"},
+		}},
+		{ID: "omelette", Ingredients: []entity.Ingredient{
+			{Name: "eggs", CatalogID: "egg", Quantity: 3},
+			{Name: "tomato", Quantity: 1},
+			{Name: "salt"},
+		}},
+		{ID: "plov", Ingredients: []entity.Ingredient{
+			{Name: "rice", Quantity: 1.5, Unit: "kg"},
+			{Name: "carrot", Quantity: 1, Unit: "kg"},
+		}},
+	}}
+	uc := usecase.NewPantryUseCase(pantry, recipes, catalog)
+
+	matches, err := uc.Cookable(context.Background(), "user", entity.CookableFilter{MaxMissing: 1})
+	if err != nil {
+		t.Fatalf("Cookable: %v", err)
+	}
+
+	if len(recipes.filter.IngredientIDs) == 0 || !containsName(recipes.filter.IngredientNames, "tomato") || containsName(recipes.filter.IngredientNames, "milk") {
+		t.Errorf("candidate filter = %+v, want pantry ingredients without expired milk", recipes.filter)
+	}
+
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.Recipe.ID)
+	}
+
+	// Pancakes miss milk, which expired, flour and sugar; plov misses the
+	// carrots and needs more rice than there is.
+	if !reflect.DeepEqual(got, []string{"omelette"}) {
+		t.Fatalf("matches = %v, want [omelette]", got)
+	}
+
+	if m := matches[0]; m.Coverage != 1 || m.Required != 2 || len(m.Missing) != 0 {
+		t.Errorf("omelette match = %+v, want 2 of 2 required covered", m)
+	}
+
+	pantry.items[1].Quantity = 2
+
+	matches, err = uc.Cookable(context.Background(), "user", entity.CookableFilter{MaxMissing: 1})
+	if err != nil {
+		t.Fatalf("Cookable: %v", err)
+	}
+
+	if len(matches) != 2 || matches[1].Recipe.ID != "plov" || !reflect.DeepEqual(matches[1].Missing, []string{"carrot"}) {
+		t.Errorf("matches = %+v, want omelette then plov missing carrot", matches)
+	}
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestPantryReminder(t *testing.T) {
+	t.Parallel()
+
+	soon := time.Now().UTC().AddDate(0, 0, 1).Format(entity.DateLayout)
+	later := time.Now().UTC().AddDate(0, 0, 10).Format(entity.DateLayout)
+
+	pantry := &memPantry{
+		claimed: map[string]bool{},
+		items: []entity.PantryItem{
+			{ID: "1", UserID: "ann", Name: "milk", ExpiresOn: soon},
+			{ID: "2", UserID: "ann", Name: "yogurt", ExpiresOn: soon},
+			{ID: "3", UserID: "bob", Name: "cheese", ExpiresOn: later},
+			{ID: "4", UserID: "bob", Name: "rice"},
+		},
+	}
+	notifications := &sentNotifications{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	usecase.NewPantryReminder(pantry, notifications, nil, time.Hour, 2).Run(ctx)
+
+	if len(notifications.sent) != 1 {
+		t.Fatalf("sent %+v, want one reminder for ann", notifications.sent)
+	}
+
+	n := notifications.sent[0]
+	if n.UserID != "ann" || n.Type != entity.NotificationPantryExpiry || n.ActorID != entity.SystemActorID ||
+		!strings.Contains(n.Message, "milk") || !strings.Contains(n.Message, "yogurt") {
+		t.Errorf("reminder = %+v", n)
+	}
+}
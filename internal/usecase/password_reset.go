@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/password"
+)
+
+const (
+	_passwordResetTTL      = 10 * time.Minute
+	_passwordResetTokenTTL = 5 * time.Minute
+	_maxResetAttempts      = 5
+)
+
+func passwordResetKey(phoneNumber string) string {
+	return "password_reset:" + phoneNumber
+}
+
+func passwordResetTokenKey(token string) string {
+	return "password_reset_token:" + token
+}
+
+// passwordResetClaimKey holds a token while a reset redeems it.
+func passwordResetClaimKey(token string) string {
+	return "password_reset_token:claimed:" + token
+}
+
+type passwordResetGrant struct {
+	UserID      string `json:"user_id"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// RequestPasswordReset sends a reset code if the number is registered. The
+// result is the same either way, so it can't be used to probe for accounts.
+func (uc *AuthUseCase) RequestPasswordReset(ctx context.Context, phoneNumber string, solution entity.ChallengeSolution) error {
+	phoneNumber, err := normalizePhone(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.guard.Check(ctx, phoneNumber, solution); err != nil {
+		return err
+	}
+
+	user, err := uc.repo.GetUserByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, entity.ErrNotFound) || (err == nil && user == nil) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return err
+	}
+
+	byteData, err := json.Marshal(entity.PasswordReset{
+		UserID: user.ID,
+		Code:   code,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = uc.RedisClient.Set(ctx, passwordResetKey(phoneNumber), byteData, _passwordResetTTL).Err(); err != nil {
+		return err
+	}
+
+	err = uc.jobs.Publish(ctx, entity.JobSendSMS, entity.SendSMSJob{
+		PhoneNumber: phoneNumber,
+		Code:        code,
+		Type:        entity.SMSVerify,
+	})
+	if err != nil {
+		uc.RedisClient.Del(ctx, passwordResetKey(phoneNumber))
+		return err
+	}
+
+	return nil
+}
+
+// VerifyPasswordReset exchanges a correct code for a short-lived reset
+// token. The code works once; too many wrong codes drop the reset.
+func (uc *AuthUseCase) VerifyPasswordReset(ctx context.Context, phoneNumber, code string) (*entity.PasswordResetToken, error) {
+	phoneNumber, err := normalizePhone(phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	key := passwordResetKey(phoneNumber)
+
+	raw, err := uc.RedisClient.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, entity.ErrInvalidResetCode
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reset entity.PasswordReset
+	if err = json.Unmarshal([]byte(raw), &reset); err != nil {
+		return nil, err
+	}
+
+	if reset.Code != code {
+		return nil, uc.rejectResetCode(ctx, key, raw, reset)
+	}
+
+	deleted, err := _compareAndDelete.Run(ctx, uc.RedisClient, []string{key}, raw).Int()
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted == 0 {
+		// A concurrent request used or replaced the code first.
+		return nil, entity.ErrInvalidResetCode
+	}
+
+	return uc.issueResetToken(ctx, passwordResetGrant{
+		UserID:      reset.UserID,
+		PhoneNumber: phoneNumber,
+	})
+}
+
+func (uc *AuthUseCase) rejectResetCode(ctx context.Context, key, raw string, reset entity.PasswordReset) error {
+	reset.Attempts++
+	if reset.Attempts >= _maxResetAttempts {
+		if err := _compareAndDelete.Run(ctx, uc.RedisClient, []string{key}, raw).Err(); err != nil {
+			return err
+		}
+
+		return entity.ErrInvalidResetCode
+	}
+
+	byteData, err := json.Marshal(reset)
+	if err != nil {
+		return err
+	}
+
+	if err = _compareAndSwap.Run(ctx, uc.RedisClient, []string{key}, raw, byteData).Err(); err != nil {
+		return err
+	}
+
+	return entity.ErrInvalidResetCode
+}
+
+func (uc *AuthUseCase) issueResetToken(ctx context.Context, grant passwordResetGrant) (*entity.PasswordResetToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	token := hex.EncodeToString(b)
+
+	byteData, err := json.Marshal(grant)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.RedisClient.Set(ctx, passwordResetTokenKey(token), byteData, _passwordResetTokenTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &entity.PasswordResetToken{
+		Token:     token,
+		ExpiresIn: int(_passwordResetTokenTTL.Seconds()),
+	}, nil
+}
+
+// ResetPassword redeems the reset token, sets the new password and signs the
+// user out everywhere. The token is claimed before the password is saved,
+// so only one request can redeem it, and put back if the save fails so it
+// can be retried.
+func (uc *AuthUseCase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	key := passwordResetTokenKey(token)
+	claimed := passwordResetClaimKey(token)
+
+	raw, err := _claimKey.Run(ctx, uc.RedisClient, []string{key, claimed}).Text()
+	if errors.Is(err, redis.Nil) {
+		return entity.ErrInvalidResetToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var grant passwordResetGrant
+	if err = json.Unmarshal([]byte(raw), &grant); err != nil {
+		return err
+	}
+
+	if err = uc.savePassword(ctx, grant, newPassword); err != nil {
+		uc.RedisClient.Rename(ctx, claimed, key)
+		return err
+	}
+
+	uc.RedisClient.Del(ctx, claimed)
+
+	uc.resetLoginFailures(ctx, grant.UserID)
+
+	uc.audit.Record(ctx, entity.AuditEntry{
+		Action:     entity.AuditPasswordReset,
+		ActorID:    grant.UserID,
+		TargetType: _auditTargetUser,
+		TargetID:   grant.UserID,
+	})
+
+	return uc.sessions.RevokeAll(ctx, grant.UserID)
+}
+
+// savePassword sets the new password of the user the grant is for.
+func (uc *AuthUseCase) savePassword(ctx context.Context, grant passwordResetGrant, newPassword string) error {
+	hashedPassword, err := password.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return uc.repo.UpdatePassword(ctx, grant.PhoneNumber, hashedPassword)
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"tarkib.uz/config"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// flakyPasswords fails the first password update.
+type flakyPasswords struct {
+	usecase.AuthRepo
+	calls int
+}
+
+func (r *flakyPasswords) UpdatePassword(context.Context, string, string) error {
+	r.calls++
+	if r.calls == 1 {
+		return errors.New("connection reset")
+	}
+
+	return nil
+}
+
+type revokedSessions struct {
+	usecase.Sessions
+	revoked []string
+}
+
+func (s *revokedSessions) RevokeAll(_ context.Context, userID string, _ ...string) error {
+	s.revoked = append(s.revoked, userID)
+
+	return nil
+}
+
+func TestResetPasswordRetry(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	if err := server.Set("password_reset_token:token", `{"user_id":"user","phone_number":"+998901234567"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &flakyPasswords{}
+	sessions := &revokedSessions{}
+	uc := usecase.NewAuthUseCase(repo, nil, nil, sessions, nil, noAudit{}, &config.Config{},
+		redis.NewClient(&redis.Options{Addr: server.Addr()}), nil)
+
+	ctx := context.Background()
+
+	if err := uc.ResetPassword(ctx, "token", "secret12"); err == nil {
+		t.Fatal("first reset succeeded, want the update error")
+	}
+
+	if err := uc.ResetPassword(ctx, "token", "secret12"); err != nil {
+		t.Fatalf("retry with the same token: %v", err)
+	}
+
+	if len(sessions.revoked) != 1 || sessions.revoked[0] != "user" {
+		t.Errorf("revoked sessions of %v, want [user]", sessions.revoked)
+	}
+
+	if err := uc.ResetPassword(ctx, "token", "secret12"); !errors.Is(err, entity.ErrInvalidResetToken) {
+		t.Errorf("third reset error = %v, want the token spent", err)
+	}
+}
+
+// slowPasswords holds each password update until release is closed.
+type slowPasswords struct {
+	usecase.AuthRepo
+	started chan struct{}
+	release chan struct{}
+	calls   int32
+}
+
+func (r *slowPasswords) UpdatePassword(context.Context, string, string) error {
+	atomic.AddInt32(&r.calls, 1)
+	r.started <- struct{}{}
+	<-r.release
+
+	return nil
+}
+
+func TestResetPasswordOnce(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	if err := server.Set("password_reset_token:token", `{"user_id":"user","phone_number":"+998901234567"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &slowPasswords{started: make(chan struct{}, 2), release: make(chan struct{})}
+	uc := usecase.NewAuthUseCase(repo, nil, nil, &revokedSessions{}, nil, noAudit{}, &config.Config{},
+		redis.NewClient(&redis.Options{Addr: server.Addr()}), nil)
+
+	ctx := context.Background()
+	first := make(chan error, 1)
+
+	go func() { first <- uc.ResetPassword(ctx, "token", "secret12") }()
+
+	<-repo.started
+
+	// A second redemption while the first one saves finds the token taken.
+	if err := uc.ResetPassword(ctx, "token", "other123"); !errors.Is(err, entity.ErrInvalidResetToken) {
+		t.Errorf("concurrent reset error = %v, want ErrInvalidResetToken", err)
+	}
+
+	close(repo.release)
+
+	if err := <-first; err != nil {
+		t.Fatalf("first reset: %v", err)
+	}
+
+	if err := uc.ResetPassword(ctx, "token", "other123"); !errors.Is(err, entity.ErrInvalidResetToken) {
+		t.Errorf("replayed reset error = %v, want ErrInvalidResetToken", err)
+	}
+
+	if calls := atomic.LoadInt32(&repo.calls); calls != 1 {
+		t.Errorf("password saved %d times, want once", calls)
+	}
+}
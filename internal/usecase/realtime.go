@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_userChannelPrefix   = "events:user:"
+	_recipeChannelPrefix = "events:recipe:"
+
+	_maxWatchedRecipes  = 50
+	_subscriptionBuffer = 64
+)
+
+type RealtimeUseCase struct {
+	RedisClient *redis.Client
+	recipes     ResourceAccessor
+}
+
+// NewRealtimeUseCase -. recipes decides which recipes a subscriber may watch.
+func NewRealtimeUseCase(RedisClient *redis.Client, recipes ResourceAccessor) *RealtimeUseCase {
+	return &RealtimeUseCase{
+		RedisClient: RedisClient,
+		recipes:     recipes,
+	}
+}
+
+func (uc *RealtimeUseCase) PublishUserEvent(ctx context.Context, userID string, event entity.Event) error {
+	return uc.publish(ctx, _userChannelPrefix+userID, event)
+}
+
+func (uc *RealtimeUseCase) PublishRecipeEvent(ctx context.Context, recipeID string, event entity.Event) error {
+	event.RecipeID = recipeID
+
+	return uc.publish(ctx, _recipeChannelPrefix+recipeID, event)
+}
+
+func (uc *RealtimeUseCase) publish(ctx context.Context, channel string, event entity.Event) error {
+	byteData, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return uc.RedisClient.Publish(ctx, channel, byteData).Err()
+}
+
+// Subscribe listens to the user's personal channel. Recipe channels are added
+// later through WatchRecipe.
+func (uc *RealtimeUseCase) Subscribe(ctx context.Context, userID string) (EventSubscription, error) {
+	pubsub := uc.RedisClient.Subscribe(ctx, _userChannelPrefix+userID)
+
+	// Wait for the subscription confirmation so errors surface here.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	s := &redisSubscription{
+		userID:  userID,
+		access:  uc.recipes,
+		pubsub:  pubsub,
+		events:  make(chan entity.Event, _subscriptionBuffer),
+		recipes: make(map[string]struct{}),
+	}
+
+	go s.forward()
+
+	return s, nil
+}
+
+type redisSubscription struct {
+	userID string
+	access ResourceAccessor
+	pubsub *redis.PubSub
+	events chan entity.Event
+
+	mu      sync.Mutex
+	recipes map[string]struct{}
+}
+
+func (s *redisSubscription) forward() {
+	defer close(s.events)
+
+	for msg := range s.pubsub.Channel() {
+		var event entity.Event
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			continue
+		}
+
+		if event.RecipeID == "" && strings.HasPrefix(msg.Channel, _recipeChannelPrefix) {
+			event.RecipeID = strings.TrimPrefix(msg.Channel, _recipeChannelPrefix)
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			// Slow consumer: drop rather than block the Redis connection.
+		}
+	}
+}
+
+func (s *redisSubscription) Events() <-chan entity.Event {
+	return s.events
+}
+
+func (s *redisSubscription) WatchRecipe(ctx context.Context, recipeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.recipes[recipeID]; ok {
+		return nil
+	}
+
+	if len(s.recipes) >= _maxWatchedRecipes {
+		return entity.ErrTooManyWatchedRecipes
+	}
+
+	// Recipes that aren't public can only be watched by their author.
+	access, err := s.access.Access(ctx, recipeID)
+	if err != nil {
+		return err
+	}
+
+	if access.OwnerID != s.userID && !access.Public {
+		return entity.ErrNotFound
+	}
+
+	if err = s.pubsub.Subscribe(ctx, _recipeChannelPrefix+recipeID); err != nil {
+		return err
+	}
+
+	s.recipes[recipeID] = struct{}{}
+
+	return nil
+}
+
+func (s *redisSubscription) UnwatchRecipe(ctx context.Context, recipeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.recipes[recipeID]; !ok {
+		return nil
+	}
+
+	if err := s.pubsub.Unsubscribe(ctx, _recipeChannelPrefix+recipeID); err != nil {
+		return err
+	}
+
+	delete(s.recipes, recipeID)
+
+	return nil
+}
+
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+type recipeAccess map[string]entity.ResourceAccess
+
+func (r recipeAccess) Access(_ context.Context, recipeID string) (*entity.ResourceAccess, error) {
+	access, ok := r[recipeID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	return &access, nil
+}
+
+func TestWatchRecipeAccess(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	uc := usecase.NewRealtimeUseCase(redis.NewClient(&redis.Options{Addr: server.Addr()}), recipeAccess{
+		"public": {OwnerID: "author", Public: true},
+		"draft":  {OwnerID: "author"},
+	})
+
+	ctx := context.Background()
+
+	reader, err := uc.Subscribe(ctx, "reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if err = reader.WatchRecipe(ctx, "public"); err != nil {
+		t.Fatalf("watch public recipe: %v", err)
+	}
+
+	for _, recipeID := range []string{"draft", "missing"} {
+		if err = reader.WatchRecipe(ctx, recipeID); !errors.Is(err, entity.ErrNotFound) {
+			t.Fatalf("watch %s: got %v, want ErrNotFound", recipeID, err)
+		}
+	}
+
+	author, err := uc.Subscribe(ctx, "author")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer author.Close()
+
+	if err = author.WatchRecipe(ctx, "draft"); err != nil {
+		t.Fatalf("author watch draft: %v", err)
+	}
+}
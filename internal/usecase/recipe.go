@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_defaultRecipeLimit = 20
+	_maxRecipeLimit     = 100
+)
+
+// RecipeUseCase manages recipes through their draft, published, unlisted
+// and archived states. Who may open a recipe is decided by the authorizer
+// through Access; mutations check the author again.
+type RecipeUseCase struct {
+	repo        RecipeRepo
+	catalog     CatalogRepo
+	preferences PreferenceRepo
+}
+
+func NewRecipeUseCase(r RecipeRepo, c CatalogRepo, p PreferenceRepo) *RecipeUseCase {
+	return &RecipeUseCase{
+		repo:        r,
+		catalog:     c,
+		preferences: p,
+	}
+}
+
+// Create starts a new draft. Drafts may be incomplete.
+func (uc *RecipeUseCase) Create(ctx context.Context, recipe entity.Recipe) (*entity.Recipe, error) {
+	now := time.Now().UTC()
+
+	recipe.ID = uuid.NewString()
+	recipe.Status = entity.RecipeDraft
+	recipe.Version = 1
+	recipe.Hidden = false
+	recipe.PublishAt = nil
+	recipe.PublishedAt = nil
+	recipe.CreatedAt = now
+	recipe.UpdatedAt = now
+
+	if err := prepareContent(&recipe); err != nil {
+		return nil, err
+	}
+
+	if err := uc.analyze(ctx, &recipe); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, &recipe); err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func (uc *RecipeUseCase) Get(ctx context.Context, recipeID string) (*entity.Recipe, error) {
+	return uc.repo.Get(ctx, recipeID)
+}
+
+// Access tells the authorizer who owns the recipe and whether others may see it.
+func (uc *RecipeUseCase) Access(ctx context.Context, recipeID string) (*entity.ResourceAccess, error) {
+	recipe, err := uc.repo.Get(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ResourceAccess{
+		OwnerID: recipe.AuthorID,
+		Public:  recipe.Public(),
+	}, nil
+}
+
+// Update saves the content of recipe, which must carry the version it was
+// loaded with. It serves both explicit saves and autosave.
+func (uc *RecipeUseCase) Update(ctx context.Context, userID string, recipe entity.Recipe) (*entity.Recipe, error) {
+	current, err := uc.ownRecipe(ctx, userID, recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	current.Title = recipe.Title
+	current.Description = recipe.Description
+	current.Servings = recipe.Servings
+	current.Ingredients = recipe.Ingredients
+	current.Sections = recipe.Sections
+	current.Version = recipe.Version
+
+	if err = prepareContent(current); err != nil {
+		return nil, err
+	}
+
+	if err = uc.analyze(ctx, current); err != nil {
+		return nil, err
+	}
+
+	// Visible recipes, and drafts the scheduler will publish, must stay
+	// publishable.
+	visible := current.Status != entity.RecipeDraft && current.Status != entity.RecipeArchived
+	if (visible || current.PublishAt != nil) && !current.Complete() {
+		return nil, entity.ErrRecipeIncomplete
+	}
+
+	if err = uc.repo.Update(ctx, current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// SetStatus moves the recipe to status. Publishing with publishAt in the
+// future schedules it: the recipe stays a draft until the scheduler
+// publishes it. Moving to any other state cancels the schedule.
+func (uc *RecipeUseCase) SetStatus(ctx context.Context, userID, recipeID, status string, publishAt *time.Time) (*entity.Recipe, error) {
+	recipe, err := uc.ownRecipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	recipe.PublishAt = nil
+
+	switch status {
+	case entity.RecipePublished, entity.RecipeUnlisted:
+		if !recipe.Complete() {
+			return nil, entity.ErrRecipeIncomplete
+		}
+
+		if status == entity.RecipePublished && publishAt != nil && publishAt.After(now) {
+			status = entity.RecipeDraft
+			recipe.PublishAt = publishAt
+		} else if status == entity.RecipePublished && recipe.PublishedAt == nil {
+			recipe.PublishedAt = &now
+		}
+	case entity.RecipeDraft, entity.RecipeArchived:
+	default:
+		return nil, entity.ErrInvalidStatus
+	}
+
+	recipe.Status = status
+
+	if err = uc.repo.UpdateStatus(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+func (uc *RecipeUseCase) Delete(ctx context.Context, userID, recipeID string) error {
+	if _, err := uc.ownRecipe(ctx, userID, recipeID); err != nil {
+		return err
+	}
+
+	return uc.repo.Delete(ctx, recipeID)
+}
+
+// List returns published recipes only, filtered by the viewer's dietary
+// preferences.
+func (uc *RecipeUseCase) List(ctx context.Context, filter entity.RecipeFilter) (*entity.RecipeList, error) {
+	filter.Limit = recipeLimit(filter.Limit)
+
+	filter, err := uc.withPreferences(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes, err := uc.repo.ListPublished(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.RecipeList{
+		Recipes: recipes,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// ListByAuthor returns the author's own recipes in every state.
+func (uc *RecipeUseCase) ListByAuthor(ctx context.Context, filter entity.RecipeFilter) (*entity.RecipeList, error) {
+	filter.Limit = recipeLimit(filter.Limit)
+
+	recipes, err := uc.repo.ListByAuthor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.RecipeList{
+		Recipes: recipes,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+func (uc *RecipeUseCase) ownRecipe(ctx context.Context, userID, recipeID string) (*entity.Recipe, error) {
+	recipe, err := uc.repo.Get(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipe.AuthorID != userID {
+		return nil, entity.ErrNotFound
+	}
+
+	return recipe, nil
+}
+
+// prepareContent gives new ingredient lines an ID, numbers the steps and
+// validates the sections. Empty sections are allowed until publishing.
+func prepareContent(recipe *entity.Recipe) error {
+	ids := make(map[string]struct{}, len(recipe.Ingredients))
+
+	for i := range recipe.Ingredients {
+		if recipe.Ingredients[i].ID == "" {
+			recipe.Ingredients[i].ID = uuid.NewString()
+		}
+
+		if _, ok := ids[recipe.Ingredients[i].ID]; ok {
+			return entity.ErrDuplicateIngredient
+		}
+
+		ids[recipe.Ingredients[i].ID] = struct{}{}
+	}
+
+	entity.NumberSteps(recipe.Sections)
+
+	return entity.ValidateSections(recipe.Sections, recipe.Ingredients)
+}
+
+// analyze works out the nutrition and dietary flags of the recipe from the
+// catalog entries of its ingredients, keeping the author's overrides.
+func (uc *RecipeUseCase) analyze(ctx context.Context, recipe *entity.Recipe) error {
+	var ids, names []string
+
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.CatalogID != "" {
+			ids = append(ids, ingredient.CatalogID)
+		} else {
+			names = append(names, catalogName(ingredient.Name))
+		}
+	}
+
+	catalog, err := uc.catalog.Match(ctx, ids, names)
+	if err != nil {
+		return err
+	}
+
+	index := newCatalogIndex(catalog)
+
+	var overrides []entity.DietaryOverride
+	if recipe.Dietary != nil {
+		overrides = recipe.Dietary.Overrides
+	}
+
+	recipe.Nutrition = computeNutrition(recipe, index)
+	recipe.Dietary = computeDietary(recipe, index, overrides)
+
+	return nil
+}
+
+func recipeLimit(limit uint64) uint64 {
+	if limit == 0 {
+		return _defaultRecipeLimit
+	}
+
+	if limit > _maxRecipeLimit {
+		return _maxRecipeLimit
+	}
+
+	return limit
+}
+
+// recipeTarget lets moderators take recipes down.
+type recipeTarget struct {
+	repo RecipeRepo
+}
+
+// NewRecipeTarget is the ModerationTarget for ReportTargetRecipe.
+func NewRecipeTarget(r RecipeRepo) ModerationTarget {
+	return &recipeTarget{repo: r}
+}
+
+func (t *recipeTarget) Author(ctx context.Context, recipeID string) (string, error) {
+	recipe, err := t.repo.Get(ctx, recipeID)
+	if err != nil {
+		return "", err
+	}
+
+	return recipe.AuthorID, nil
+}
+
+func (t *recipeTarget) Hide(ctx context.Context, recipeID string) error {
+	return t.repo.SetHidden(ctx, recipeID, true)
+}
+
+func (t *recipeTarget) Delete(ctx context.Context, recipeID string) error {
+	return t.repo.Delete(ctx, recipeID)
+}
@@ -0,0 +1,373 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/units"
+)
+
+// Imported recipes are cut to what the recipe editor accepts, so the draft
+// can be saved again as it is.
+const (
+	_maxImportTitle       = 200
+	_maxImportDescription = 5000
+	_maxImportLines       = 100
+	_maxImportName        = 100
+	_maxImportUnit        = 20
+	_maxImportNote        = 200
+	_maxImportContent     = 5000
+	_maxImportServings    = 100
+)
+
+// _measureWords are units the converter doesn't know that still belong in
+// the unit field rather than the ingredient name.
+var _measureWords = map[string]bool{
+	"pinch": true, "pinches": true, "clove": true, "cloves": true,
+	"can": true, "cans": true, "bunch": true, "bunches": true,
+	"handful": true, "handfuls": true, "slice": true, "slices": true,
+	"sprig": true, "sprigs": true, "stick": true, "sticks": true,
+	"щепотка": true, "щепотки": true, "зубчик": true, "зубчика": true,
+	"зубчиков": true, "пучок": true, "банка": true, "ломтик": true,
+	"chimdim": true, "bog'": true,
+}
+
+var _vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4,
+	'⅕': 1.0 / 5, '⅙': 1.0 / 6, '⅛': 1.0 / 8,
+}
+
+// RecipeImportUseCase turns recipes published elsewhere, on blogs with
+// schema.org markup or in Markdown files, into drafts the author reviews
+// before publishing.
+type RecipeImportUseCase struct {
+	recipes Recipe
+	pages   RecipePageWebAPI
+}
+
+func NewRecipeImportUseCase(r Recipe, p RecipePageWebAPI) *RecipeImportUseCase {
+	return &RecipeImportUseCase{
+		recipes: r,
+		pages:   p,
+	}
+}
+
+// FromURL imports the recipe on the page at rawURL.
+func (uc *RecipeImportUseCase) FromURL(ctx context.Context, userID, rawURL string) (*entity.Recipe, error) {
+	page, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (page.Scheme != "http" && page.Scheme != "https") || page.Hostname() == "" {
+		return nil, entity.ErrInvalidImportURL
+	}
+
+	document, err := uc.pages.Fetch(ctx, page.String())
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := parseHTMLRecipe(document, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.create(ctx, userID, imported)
+}
+
+// FromDocument imports the recipe in an HTML or Markdown document the
+// author uploaded.
+func (uc *RecipeImportUseCase) FromDocument(ctx context.Context, userID, format, document string) (*entity.Recipe, error) {
+	var (
+		imported *importedRecipe
+		err      error
+	)
+
+	switch format {
+	case entity.ImportHTML:
+		imported, err = parseHTMLRecipe(document, nil)
+	case entity.ImportMarkdown:
+		imported, err = parseMarkdownRecipe(document)
+	default:
+		err = entity.ErrNoRecipeFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.create(ctx, userID, imported)
+}
+
+func (uc *RecipeImportUseCase) create(ctx context.Context, userID string, imported *importedRecipe) (*entity.Recipe, error) {
+	recipe := imported.recipe()
+	if recipe.Title == "" && len(recipe.Ingredients) == 0 {
+		return nil, entity.ErrNoRecipeFound
+	}
+
+	recipe.AuthorID = userID
+
+	return uc.recipes.Create(ctx, recipe)
+}
+
+// importedRecipe is what the parsers find in a document, as plain text.
+type importedRecipe struct {
+	title       string
+	description string
+	yield       string
+	image       string
+	ingredients []string
+	sections    []entity.Section
+}
+
+func (r *importedRecipe) addStep(text string) {
+	if text = cleanText(text); text != "" {
+		r.sections = append(r.sections, entity.Section{Type: entity.SectionStep, Content: text})
+	}
+}
+
+func (r *importedRecipe) addText(text string) {
+	if text = cleanText(text); text != "" {
+		r.sections = append(r.sections, entity.Section{Type: entity.SectionText, Content: text})
+	}
+}
+
+// recipe builds the draft: the image first, then the sections, with the
+// ingredient lines parsed into quantities and units.
+func (r *importedRecipe) recipe() entity.Recipe {
+	recipe := entity.Recipe{
+		Title:       truncate(cleanText(r.title), _maxImportTitle),
+		Description: truncate(cleanText(r.description), _maxImportDescription),
+		Servings:    parseYield(r.yield),
+		Ingredients: []entity.Ingredient{},
+		Sections:    []entity.Section{},
+	}
+
+	for _, line := range r.ingredients {
+		if len(recipe.Ingredients) == _maxImportLines {
+			break
+		}
+
+		if ingredient, ok := parseIngredientLine(line); ok {
+			recipe.Ingredients = append(recipe.Ingredients, ingredient)
+		}
+	}
+
+	if r.image != "" {
+		recipe.Sections = append(recipe.Sections, entity.Section{Type: entity.SectionImage, URL: r.image})
+	}
+
+	for _, section := range r.sections {
+		if len(recipe.Sections) == _maxImportLines {
+			break
+		}
+
+		section.Content = truncate(section.Content, _maxImportContent)
+		recipe.Sections = append(recipe.Sections, section)
+	}
+
+	return recipe
+}
+
+// parseYield takes the first number in yields such as "4 servings" or
+// "Serves 4-6".
+func parseYield(yield string) int {
+	start := strings.IndexFunc(yield, unicode.IsDigit)
+	if start < 0 {
+		return 0
+	}
+
+	end := start + digits(yield[start:])
+
+	servings, err := strconv.Atoi(yield[start:end])
+	if err != nil || servings > _maxImportServings {
+		return 0
+	}
+
+	return servings
+}
+
+// parseIngredientLine splits lines such as "1 1/2 cups flour, sifted" or
+// "Мука — 200 г" into quantity, unit, name and note. Lines without a
+// quantity, such as "salt to taste", are kept as the name.
+func parseIngredientLine(line string) (entity.Ingredient, bool) {
+	line = strings.TrimLeft(cleanText(line), "-*•–—·+ ")
+	if line == "" {
+		return entity.Ingredient{}, false
+	}
+
+	ingredient := entity.Ingredient{Name: line}
+
+	if quantity, rest := parseQuantity(line); quantity > 0 {
+		unitName, rest := splitUnit(rest)
+
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(strings.ToLower(rest), "of ") {
+			rest = strings.TrimSpace(rest[len("of "):])
+		}
+
+		name, note, _ := strings.Cut(rest, ",")
+		if name = strings.TrimSpace(name); name != "" {
+			ingredient = entity.Ingredient{Name: name, Quantity: quantity, Unit: unitName, Note: strings.TrimSpace(note)}
+		}
+	} else {
+		// The name first, as Russian and Uzbek recipes list them.
+		for _, separator := range []string{" — ", " – ", " - ", ": "} {
+			name, amount, found := strings.Cut(line, separator)
+			if !found {
+				continue
+			}
+
+			if quantity, rest := parseQuantity(strings.TrimSpace(amount)); quantity > 0 {
+				unitName, note := splitUnit(rest)
+				ingredient = entity.Ingredient{Name: strings.TrimSpace(name), Quantity: quantity, Unit: unitName, Note: strings.Trim(note, " ,()")}
+			}
+
+			break
+		}
+	}
+
+	ingredient.Name = truncate(ingredient.Name, _maxImportName)
+	ingredient.Unit = truncate(ingredient.Unit, _maxImportUnit)
+	ingredient.Note = truncate(ingredient.Note, _maxImportNote)
+
+	return ingredient, true
+}
+
+// parseQuantity reads a quantity at the start of s: 2, 1.5, 1,5, 1/2,
+// 1 1/2, ½ or 1½. Of a range such as 2-3 the upper bound is taken, so
+// shopping lists have enough.
+func parseQuantity(s string) (float64, string) {
+	quantity, rest, ok := parseAmount(s)
+	if !ok {
+		return 0, s
+	}
+
+	trimmed := strings.TrimLeft(rest, " ")
+	for _, dash := range []string{"-", "–", "—", "to "} {
+		if !strings.HasPrefix(trimmed, dash) {
+			continue
+		}
+
+		if upper, after, ok := parseAmount(strings.TrimLeft(trimmed[len(dash):], " ")); ok {
+			quantity, rest = upper, after
+		}
+
+		break
+	}
+
+	return quantity, rest
+}
+
+func parseAmount(s string) (float64, string, bool) {
+	if fraction, rest, ok := parseFraction(s); ok {
+		return fraction, rest, true
+	}
+
+	n := digits(s)
+	if n == 0 {
+		return 0, s, false
+	}
+
+	whole, err := strconv.ParseFloat(s[:n], 64)
+	if err != nil {
+		return 0, s, false
+	}
+
+	rest := s[n:]
+
+	// Decimal point or, as in Russian and Uzbek recipes, comma.
+	if len(rest) > 1 && (rest[0] == '.' || rest[0] == ',') {
+		if m := digits(rest[1:]); m > 0 {
+			decimal, _ := strconv.ParseFloat("0."+rest[1:1+m], 64)
+
+			return whole + decimal, rest[1+m:], true
+		}
+	}
+
+	if fraction, after, ok := parseFraction(strings.TrimLeft(rest, " ")); ok {
+		return whole + fraction, after, true
+	}
+
+	return whole, rest, true
+}
+
+// parseFraction reads ½ or 1/2 at the start of s.
+func parseFraction(s string) (float64, string, bool) {
+	r, size := utf8.DecodeRuneInString(s)
+	if value, ok := _vulgarFractions[r]; ok {
+		return value, s[size:], true
+	}
+
+	n := digits(s)
+	if n == 0 || n+1 >= len(s) || s[n] != '/' {
+		return 0, s, false
+	}
+
+	m := digits(s[n+1:])
+	if m == 0 {
+		return 0, s, false
+	}
+
+	numerator, _ := strconv.Atoi(s[:n])
+	denominator, _ := strconv.Atoi(s[n+1 : n+1+m])
+
+	if denominator == 0 {
+		return 0, s, false
+	}
+
+	return float64(numerator) / float64(denominator), s[n+1+m:], true
+}
+
+// splitUnit takes a unit of one or two words, such as "g" or "osh qoshiq",
+// off the start of s.
+func splitUnit(s string) (string, string) {
+	fields := strings.Fields(s)
+
+	for n := min(2, len(fields)); n > 0; n-- {
+		candidate := strings.ToLower(strings.Join(fields[:n], " "))
+
+		for _, unitName := range []string{candidate, strings.TrimSuffix(candidate, ".")} {
+			if units.DimensionOf(unitName) != units.Unknown || _measureWords[unitName] {
+				return unitName, strings.Join(fields[n:], " ")
+			}
+		}
+	}
+
+	return "", s
+}
+
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+
+	return n
+}
+
+// cleanText collapses runs of spaces; line breaks between paragraphs are
+// kept.
+func cleanText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+
+	var paragraphs []string
+
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	return strings.TrimSpace(string([]rune(s)[:limit]))
+}
@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"tarkib.uz/internal/entity"
+)
+
+// parseHTMLRecipe finds a schema.org Recipe in the page, in JSON-LD or
+// else in microdata. Relative image URLs are resolved against base, which
+// is nil for uploaded documents.
+func parseHTMLRecipe(document string, base *url.URL) (*importedRecipe, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return nil, entity.ErrNoRecipeFound
+	}
+
+	var (
+		scripts []string
+		scope   *html.Node
+	)
+
+	walk(root, func(n *html.Node) bool {
+		switch {
+		case n.DataAtom == atom.Script && strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json"):
+			scripts = append(scripts, textContent(n))
+		case scope == nil && hasAttr(n, "itemscope") && isRecipeType(attr(n, "itemtype")):
+			scope = n
+		}
+
+		return true
+	})
+
+	var imported *importedRecipe
+
+	for _, script := range scripts {
+		var data interface{}
+		if json.Unmarshal([]byte(script), &data) != nil {
+			continue
+		}
+
+		if recipe := findJSONLDRecipe(data); recipe != nil {
+			imported = fromJSONLD(recipe)
+			break
+		}
+	}
+
+	if imported == nil && scope != nil {
+		imported = fromMicrodata(scope)
+	}
+
+	if imported == nil {
+		return nil, entity.ErrNoRecipeFound
+	}
+
+	imported.image = resolveImage(imported.image, base)
+
+	return imported, nil
+}
+
+// findJSONLDRecipe looks for an object typed Recipe at the top, in arrays
+// and in @graph.
+func findJSONLDRecipe(data interface{}) map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if recipe := findJSONLDRecipe(item); recipe != nil {
+				return recipe
+			}
+		}
+	case map[string]interface{}:
+		for _, t := range jsonStrings(v["@type"]) {
+			if isRecipeType(t) {
+				return v
+			}
+		}
+
+		if graph, ok := v["@graph"]; ok {
+			return findJSONLDRecipe(graph)
+		}
+	}
+
+	return nil
+}
+
+func fromJSONLD(recipe map[string]interface{}) *importedRecipe {
+	imported := &importedRecipe{
+		title:       htmlText(jsonString(recipe["name"])),
+		description: htmlText(jsonString(recipe["description"])),
+		yield:       jsonString(recipe["recipeYield"]),
+		image:       jsonImage(recipe["image"]),
+	}
+
+	ingredients, ok := recipe["recipeIngredient"]
+	if !ok {
+		ingredients = recipe["ingredients"]
+	}
+
+	for _, line := range jsonStrings(ingredients) {
+		imported.ingredients = append(imported.ingredients, htmlText(line))
+	}
+
+	addJSONLDInstructions(imported, recipe["recipeInstructions"])
+
+	return imported
+}
+
+// addJSONLDInstructions adds steps from text, lists of text, HowToStep and
+// HowToSection, whose name becomes a text section before its steps.
+func addJSONLDInstructions(imported *importedRecipe, instructions interface{}) {
+	switch v := instructions.(type) {
+	case string:
+		for _, line := range strings.Split(htmlText(v), "\n") {
+			imported.addStep(line)
+		}
+	case []interface{}:
+		for _, item := range v {
+			addJSONLDInstructions(imported, item)
+		}
+	case map[string]interface{}:
+		if elements, ok := v["itemListElement"]; ok {
+			imported.addText(htmlText(jsonString(v["name"])))
+			addJSONLDInstructions(imported, elements)
+
+			return
+		}
+
+		text := jsonString(v["text"])
+		if text == "" {
+			text = jsonString(v["name"])
+		}
+
+		imported.addStep(htmlText(text))
+	}
+}
+
+// jsonString returns a string or number value, or the first of a list.
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonString(v[0])
+		}
+	}
+
+	return ""
+}
+
+// jsonStrings returns a string value as one item and the strings of a list.
+func jsonStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+// jsonImage returns the URL of an image given as a URL, an ImageObject or a
+// list of either.
+func jsonImage(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonImage(v[0])
+		}
+	case map[string]interface{}:
+		return jsonString(v["url"])
+	}
+
+	return ""
+}
+
+// fromMicrodata reads the properties of a Recipe item. Nested items, such
+// as the author, are skipped except for instruction steps.
+func fromMicrodata(scope *html.Node) *importedRecipe {
+	imported := &importedRecipe{}
+
+	for n := scope.FirstChild; n != nil; n = n.NextSibling {
+		walk(n, func(n *html.Node) bool {
+			props := strings.Fields(attr(n, "itemprop"))
+			nested := hasAttr(n, "itemscope")
+
+			for _, prop := range props {
+				switch prop {
+				case "name":
+					if imported.title == "" && !nested {
+						imported.title = itemValue(n)
+					}
+				case "description":
+					if imported.description == "" && !nested {
+						imported.description = itemValue(n)
+					}
+				case "recipeYield":
+					if imported.yield == "" {
+						imported.yield = itemValue(n)
+					}
+				case "image":
+					if imported.image == "" && !nested {
+						imported.image = itemValue(n)
+					}
+				case "recipeIngredient", "ingredients":
+					imported.ingredients = append(imported.ingredients, itemValue(n))
+				case "recipeInstructions":
+					addMicrodataInstructions(imported, n)
+
+					return false
+				}
+			}
+
+			return !nested
+		})
+	}
+
+	return imported
+}
+
+// addMicrodataInstructions adds a HowToStep item, or each list item or
+// paragraph of the element as a step.
+func addMicrodataInstructions(imported *importedRecipe, n *html.Node) {
+	if hasAttr(n, "itemscope") {
+		var text string
+
+		walk(n, func(child *html.Node) bool {
+			if text == "" && child != n && strings.Contains(" "+attr(child, "itemprop")+" ", " text ") {
+				text = itemValue(child)
+			}
+
+			return true
+		})
+
+		if text == "" {
+			text = textContent(n)
+		}
+
+		imported.addStep(text)
+
+		return
+	}
+
+	var items []*html.Node
+
+	walk(n, func(child *html.Node) bool {
+		if child.DataAtom == atom.Li || child.DataAtom == atom.P {
+			items = append(items, child)
+			return false
+		}
+
+		return true
+	})
+
+	if len(items) == 0 {
+		for _, line := range strings.Split(textContent(n), "\n") {
+			imported.addStep(line)
+		}
+
+		return
+	}
+
+	for _, item := range items {
+		imported.addStep(textContent(item))
+	}
+}
+
+// itemValue is the microdata value of a property element.
+func itemValue(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Meta:
+		return attr(n, "content")
+	case atom.Img, atom.Source:
+		return attr(n, "src")
+	case atom.A, atom.Link:
+		return attr(n, "href")
+	case atom.Time, atom.Data, atom.Meter:
+		if value := attr(n, "datetime"); value != "" {
+			return value
+		}
+
+		if value := attr(n, "value"); value != "" {
+			return value
+		}
+	}
+
+	if value := attr(n, "content"); value != "" {
+		return value
+	}
+
+	return textContent(n)
+}
+
+func isRecipeType(t string) bool {
+	t = strings.TrimSpace(t)
+
+	return t == "Recipe" || strings.HasSuffix(t, "schema.org/Recipe")
+}
+
+// resolveImage makes image absolute against base. Only http(s) URLs are
+// kept.
+func resolveImage(image string, base *url.URL) string {
+	ref, err := url.Parse(strings.TrimSpace(image))
+	if err != nil || image == "" {
+		return ""
+	}
+
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+
+	if (ref.Scheme != "http" && ref.Scheme != "https") || ref.Host == "" {
+		return ""
+	}
+
+	return ref.String()
+}
+
+// walk visits n and its descendants depth first; visit returns false to
+// skip the children of a node.
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// textContent returns the text of n, with line breaks between blocks.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+
+	walk(n, func(n *html.Node) bool {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case n.Type == html.ElementNode && isBlock(n.DataAtom):
+			b.WriteString("\n")
+		}
+
+		return true
+	})
+
+	return cleanText(b.String())
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Li, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Tr, atom.Section:
+		return true
+	default:
+		return false
+	}
+}
+
+// htmlText turns JSON-LD values, which sites often fill with escaped HTML,
+// into plain text.
+func htmlText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return cleanText(s)
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return cleanText(html.UnescapeString(s))
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	return textContent(root)
+}
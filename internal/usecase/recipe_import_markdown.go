@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+
+	"tarkib.uz/internal/entity"
+)
+
+// Headings that start the ingredient list and the steps, in English,
+// Russian and Uzbek.
+var (
+	_ingredientHeadings = []string{"ingredients", "ингредиенты", "masalliqlar", "kerakli masalliqlar"}
+	_stepHeadings       = []string{
+		"instructions", "directions", "method", "steps", "preparation",
+		"приготовление", "способ приготовления", "tayyorlash", "tayyorlanishi",
+	}
+	_yieldPrefixes = []string{"servings", "serves", "yield", "порции", "порций", "porsiya"}
+)
+
+var (
+	_markdownImage = regexp.MustCompile(`^!\[[^\]]*\]\(([^)\s]+)[^)]*\)$`)
+	_markdownLink  = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	_markdownItem  = regexp.MustCompile(`^(?:[-*+•]|\d+[.)])\s+`)
+	_markdownStyle = strings.NewReplacer("**", "", "__", "", "`", "")
+)
+
+// _markdownLabel is the level of headings written as plain labels.
+const _markdownLabel = 7
+
+type markdownPart int
+
+const (
+	_markdownIntro markdownPart = iota
+	_markdownIngredients
+	_markdownSteps
+	_markdownOther
+)
+
+// parseMarkdownRecipe reads a recipe written as a Markdown file or a
+// Telegram post: the title heading, an introduction, then the ingredients
+// and steps under their headings. List items and paragraphs under the step
+// heading become steps; other headings start text sections.
+func parseMarkdownRecipe(document string) (*importedRecipe, error) {
+	imported := &importedRecipe{}
+
+	var (
+		part      = _markdownIntro
+		partLevel int
+		paragraph []string
+		intro     []string
+		other     []string
+		fromList  bool
+	)
+
+	flush := func() {
+		text := strings.Join(paragraph, " ")
+		paragraph = nil
+
+		if text == "" {
+			return
+		}
+
+		switch part {
+		case _markdownIntro:
+			if !fromList && markdownYield(imported, text) {
+				return
+			}
+
+			intro = append(intro, text)
+		case _markdownIngredients:
+			imported.ingredients = append(imported.ingredients, text)
+		case _markdownSteps:
+			imported.addStep(text)
+		case _markdownOther:
+			other = append(other, text)
+		}
+	}
+
+	flushOther := func() {
+		if len(other) > 0 {
+			imported.addText(strings.Join(other, "\n"))
+			other = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case markdownHeadingLine(trimmed) > 0:
+			flush()
+
+			level := markdownHeadingLine(trimmed)
+			heading := strings.TrimRight(markdownInline(strings.Trim(trimmed, "# ")), ":")
+
+			if imported.title == "" && part == _markdownIntro && len(intro) == 0 && level < _markdownLabel &&
+				!markdownHeading(heading, _ingredientHeadings) && !markdownHeading(heading, _stepHeadings) {
+				imported.title = heading
+
+				continue
+			}
+
+			// Subheadings such as "For the sauce" stay in their part.
+			if (part == _markdownIngredients || part == _markdownSteps) && level > partLevel {
+				if part == _markdownSteps {
+					imported.addText(heading)
+				}
+
+				continue
+			}
+
+			flushOther()
+
+			switch {
+			case markdownHeading(heading, _ingredientHeadings):
+				part = _markdownIngredients
+			case markdownHeading(heading, _stepHeadings):
+				part = _markdownSteps
+			default:
+				part = _markdownOther
+				other = append(other, heading)
+			}
+
+			partLevel = level
+		case _markdownImage.MatchString(trimmed):
+			flush()
+
+			image := resolveImage(_markdownImage.FindStringSubmatch(trimmed)[1], nil)
+
+			switch {
+			case image == "":
+			case imported.image == "":
+				imported.image = image
+			default:
+				imported.sections = append(imported.sections, entity.Section{Type: entity.SectionImage, URL: image})
+			}
+		case _markdownItem.MatchString(trimmed):
+			flush()
+
+			paragraph = []string{markdownInline(_markdownItem.ReplaceAllString(trimmed, ""))}
+			fromList = true
+		default:
+			// Ingredients are one per line, with or without a list marker;
+			// an unindented line after a list item starts a paragraph.
+			if part == _markdownIngredients || (len(paragraph) > 0 && fromList && line == trimmed) {
+				flush()
+			}
+
+			if len(paragraph) == 0 {
+				fromList = false
+			}
+
+			paragraph = append(paragraph, markdownInline(trimmed))
+		}
+	}
+
+	flush()
+	flushOther()
+
+	if len(imported.ingredients) == 0 && len(imported.sections) == 0 {
+		return nil, entity.ErrNoRecipeFound
+	}
+
+	// Telegram posts have no heading; their first line is the title.
+	if imported.title == "" && len(intro) > 0 {
+		imported.title, intro = intro[0], intro[1:]
+	}
+
+	imported.description = strings.Join(intro, "\n")
+
+	return imported, nil
+}
+
+// markdownYield takes "Servings: 4" and the like as the yield.
+func markdownYield(imported *importedRecipe, text string) bool {
+	lower := strings.ToLower(text)
+
+	for _, prefix := range _yieldPrefixes {
+		if strings.HasPrefix(lower, prefix) && parseYield(text) > 0 {
+			imported.yield = text
+			return true
+		}
+	}
+
+	return false
+}
+
+// markdownHeadingLine returns the level of a heading line, or 0. Lines
+// such as "**Ingredients:**" in Telegram posts count as headings of the
+// lowest level.
+func markdownHeadingLine(line string) int {
+	if strings.HasPrefix(line, "#") {
+		return min(len(line)-len(strings.TrimLeft(line, "#")), _markdownLabel-1)
+	}
+
+	label := markdownInline(line)
+	if markdownHeading(label, _ingredientHeadings) || markdownHeading(label, _stepHeadings) {
+		return _markdownLabel
+	}
+
+	return 0
+}
+
+func markdownHeading(heading string, names []string) bool {
+	heading = strings.ToLower(strings.TrimRight(heading, ": "))
+
+	for _, name := range names {
+		if heading == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markdownInline drops emphasis and code marks and keeps the text of
+// links.
+func markdownInline(s string) string {
+	s = _markdownLink.ReplaceAllString(s, "$1")
+
+	return strings.TrimSpace(_markdownStyle.Replace(s))
+}
@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// fixturePages serves pages from testdata by URL.
+type fixturePages struct {
+	pages map[string]string
+}
+
+func (p *fixturePages) Fetch(_ context.Context, pageURL string) (string, error) {
+	page, ok := p.pages[pageURL]
+	if !ok {
+		return "", entity.ErrImportFetch
+	}
+
+	return page, nil
+}
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func importer(t *testing.T) *usecase.RecipeImportUseCase {
+	t.Helper()
+
+	recipes := usecase.NewRecipeUseCase(&memRecipes{}, &memCatalog{}, nil)
+	pages := &fixturePages{pages: map[string]string{
+		"https://kitchen.example/plov":  fixture(t, "recipe_jsonld.html"),
+		"https://kitchen.example/samsa": fixture(t, "recipe_microdata.html"),
+		"https://kitchen.example/about": "<html><body><p>About us</p></body></html>",
+	}}
+
+	return usecase.NewRecipeImportUseCase(recipes, pages)
+}
+
+func sectionTypes(sections []entity.Section) []string {
+	types := make([]string, 0, len(sections))
+	for _, s := range sections {
+		types = append(types, s.Type)
+	}
+
+	return types
+}
+
+func TestImportJSONLD(t *testing.T) {
+	t.Parallel()
+
+	recipe, err := importer(t).FromURL(context.Background(), "author", "https://kitchen.example/plov")
+	if err != nil {
+		t.Fatalf("FromURL: %v", err)
+	}
+
+	if recipe.Title != "Weekend Plov" || recipe.Description != "Rice, lamb & carrots cooked the Tashkent way." ||
+		recipe.Servings != 6 || recipe.AuthorID != "author" || recipe.Status != entity.RecipeDraft {
+		t.Errorf("recipe = %q %q serves %d by %s, %s", recipe.Title, recipe.Description, recipe.Servings, recipe.AuthorID, recipe.Status)
+	}
+
+	want := []entity.Ingredient{
+		{Name: "lamb shoulder", Quantity: 1, Unit: "kg", Note: "cut into chunks"},
+		{Name: "rice", Quantity: 1.5, Unit: "cups"},
+		{Name: "carrots", Quantity: 3},
+		{Name: "oil", Quantity: 200, Unit: "ml"},
+		{Name: "cumin", Quantity: 1, Unit: "tbsp"},
+		{Name: "garlic", Quantity: 2, Unit: "cloves"},
+		{Name: "salt to taste"},
+	}
+
+	for i := range recipe.Ingredients {
+		recipe.Ingredients[i].ID = ""
+	}
+
+	if !reflect.DeepEqual(recipe.Ingredients, want) {
+		t.Errorf("ingredients = %+v, want %+v", recipe.Ingredients, want)
+	}
+
+	types := []string{entity.SectionImage, entity.SectionText, entity.SectionStep, entity.SectionStep, entity.SectionStep}
+	if got := sectionTypes(recipe.Sections); !reflect.DeepEqual(got, types) {
+		t.Fatalf("sections = %v, want %v", got, types)
+	}
+
+	if recipe.Sections[0].URL != "https://kitchen.example/images/plov.jpg" {
+		t.Errorf("image = %q, want it resolved against the page", recipe.Sections[0].URL)
+	}
+
+	if last := recipe.Sections[4]; last.Step == nil || last.Step.Number != 3 || last.Content != "Cover with rice and water, then steam for 40 minutes." {
+		t.Errorf("last step = %+v", last)
+	}
+}
+
+func TestImportMicrodata(t *testing.T) {
+	t.Parallel()
+
+	recipe, err := importer(t).FromURL(context.Background(), "author", "https://kitchen.example/samsa")
+	if err != nil {
+		t.Fatalf("FromURL: %v", err)
+	}
+
+	if recipe.Title != "Самса с тыквой" || recipe.Servings != 12 {
+		t.Errorf("recipe = %q serves %d, want the recipe's name, not the author's", recipe.Title, recipe.Servings)
+	}
+
+	if first := recipe.Ingredients[0]; first.Name != "Тыква" || first.Quantity != 500 || first.Unit != "г" {
+		t.Errorf("first ingredient = %+v, want 500 г of Тыква", first)
+	}
+
+	if spice := recipe.Ingredients[3]; spice.Unit != "ч.л." {
+		t.Errorf("spice = %+v, want ч.л.", spice)
+	}
+
+	types := []string{entity.SectionImage, entity.SectionStep, entity.SectionStep, entity.SectionStep}
+	if got := sectionTypes(recipe.Sections); !reflect.DeepEqual(got, types) {
+		t.Errorf("sections = %v, want %v", got, types)
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	t.Parallel()
+
+	uc := importer(t)
+
+	recipe, err := uc.FromDocument(context.Background(), "author", entity.ImportMarkdown, fixture(t, "recipe.md"))
+	if err != nil {
+		t.Fatalf("FromDocument: %v", err)
+	}
+
+	if recipe.Title != "Shakarob salad" || recipe.Servings != 4 ||
+		recipe.Description != "A fresh tomato and onion salad served with plov." {
+		t.Errorf("recipe = %q %q serves %d", recipe.Title, recipe.Description, recipe.Servings)
+	}
+
+	if len(recipe.Ingredients) != 4 || recipe.Ingredients[2].Quantity != 0.5 || recipe.Ingredients[2].Unit != "tsp" ||
+		recipe.Ingredients[3].Quantity != 0.25 || recipe.Ingredients[3].Unit != "bunch" {
+		t.Errorf("ingredients = %+v", recipe.Ingredients)
+	}
+
+	types := []string{entity.SectionImage, entity.SectionStep, entity.SectionStep, entity.SectionText}
+	if got := sectionTypes(recipe.Sections); !reflect.DeepEqual(got, types) {
+		t.Fatalf("sections = %v, want %v", got, types)
+	}
+
+	if step := recipe.Sections[1].Content; step != "Slice the tomatoes and the onion into thin rings." {
+		t.Errorf("first step = %q, want the wrapped line joined", step)
+	}
+
+	telegram, err := uc.FromDocument(context.Background(), "author", entity.ImportMarkdown, fixture(t, "recipe_telegram.md"))
+	if err != nil {
+		t.Fatalf("FromDocument(telegram): %v", err)
+	}
+
+	if telegram.Title != "Qovoq somsa 🥟" || len(telegram.Ingredients) != 3 || len(telegram.Sections) != 2 {
+		t.Fatalf("telegram post = %+v", telegram)
+	}
+
+	if zira := telegram.Ingredients[2]; zira.Name != "Zira" || zira.Quantity != 1 || zira.Unit != "choy qoshiq" {
+		t.Errorf("zira = %+v", zira)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	t.Parallel()
+
+	uc := importer(t)
+	ctx := context.Background()
+
+	for _, rawURL := range []string{"ftp://kitchen.example/plov", "file:///etc/passwd", "kitchen.example/plov"} {
+		if _, err := uc.FromURL(ctx, "author", rawURL); !errors.Is(err, entity.ErrInvalidImportURL) {
+			t.Errorf("FromURL(%q) error = %v, want ErrInvalidImportURL", rawURL, err)
+		}
+	}
+
+	if _, err := uc.FromURL(ctx, "author", "https://kitchen.example/about"); !errors.Is(err, entity.ErrNoRecipeFound) {
+		t.Errorf("page without a recipe error = %v, want ErrNoRecipeFound", err)
+	}
+
+	if _, err := uc.FromDocument(ctx, "author", entity.ImportMarkdown, "Just a note."); !errors.Is(err, entity.ErrNoRecipeFound) {
+		t.Errorf("note error = %v, want ErrNoRecipeFound", err)
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/diff"
+)
+
+// Revisions lists the saved versions of the recipe, newest first.
+func (uc *RecipeUseCase) Revisions(ctx context.Context, recipeID string) ([]entity.RecipeRevision, error) {
+	if _, err := uc.repo.Get(ctx, recipeID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListRevisions(ctx, recipeID)
+}
+
+func (uc *RecipeUseCase) Revision(ctx context.Context, recipeID string, version int) (*entity.RecipeRevision, error) {
+	return uc.repo.GetRevision(ctx, recipeID, version)
+}
+
+// Diff compares two revisions of the recipe.
+func (uc *RecipeUseCase) Diff(ctx context.Context, recipeID string, from, to int) (*entity.RecipeDiff, error) {
+	before, err := uc.repo.GetRevision(ctx, recipeID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := uc.repo.GetRevision(ctx, recipeID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.RecipeDiff{
+		From:        from,
+		To:          to,
+		Title:       fieldChange(before.Title, after.Title),
+		Description: fieldChange(before.Description, after.Description),
+		Servings:    fieldChange(before.Servings, after.Servings),
+		Ingredients: listChanges(before.Ingredients, after.Ingredients),
+		Sections:    listChanges(before.Sections, after.Sections),
+	}, nil
+}
+
+// Restore saves the content of an old revision as the newest version.
+func (uc *RecipeUseCase) Restore(ctx context.Context, userID, recipeID string, version int) (*entity.Recipe, error) {
+	current, err := uc.ownRecipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := uc.repo.GetRevision(ctx, recipeID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.Update(ctx, userID, entity.Recipe{
+		ID:          recipeID,
+		Title:       revision.Title,
+		Description: revision.Description,
+		Servings:    revision.Servings,
+		Ingredients: revision.Ingredients,
+		Sections:    revision.Sections,
+		Version:     current.Version,
+	})
+}
+
+func fieldChange[T comparable](before, after T) *entity.FieldChange {
+	if before == after {
+		return nil
+	}
+
+	return &entity.FieldChange{Old: before, New: after}
+}
+
+// listChanges diffs two ordered lists. A run of removals directly followed
+// by additions is reported as changes, pairwise, as edits in place.
+func listChanges[T any](before, after []T) []entity.ListChange[T] {
+	edits := diff.Slices(len(before), len(after), func(i, j int) bool {
+		return reflect.DeepEqual(before[i], after[j])
+	})
+
+	var changes []entity.ListChange[T]
+
+	for k := 0; k < len(edits); {
+		if edits[k].Op == diff.Equal {
+			k++
+			continue
+		}
+
+		var removed, added []int
+		for ; k < len(edits) && edits[k].Op == diff.Delete; k++ {
+			removed = append(removed, edits[k].OldIndex)
+		}
+
+		for ; k < len(edits) && edits[k].Op == diff.Insert; k++ {
+			added = append(added, edits[k].NewIndex)
+		}
+
+		for n := 0; n < len(removed) || n < len(added); n++ {
+			change := entity.ListChange[T]{}
+
+			if n < len(removed) {
+				i := removed[n]
+				change.Change = entity.ChangeRemoved
+				change.OldIndex = &i
+				change.Old = &before[i]
+			}
+
+			if n < len(added) {
+				j := added[n]
+				change.Change = entity.ChangeAdded
+				change.NewIndex = &j
+				change.New = &after[j]
+			}
+
+			if change.Old != nil && change.New != nil {
+				change.Change = entity.ChangeChanged
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
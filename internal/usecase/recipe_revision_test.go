@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// memRevisions serves revisions only; other repo methods are not used.
+type memRevisions struct {
+	usecase.RecipeRepo
+	revisions map[int]entity.RecipeRevision
+}
+
+func (m *memRevisions) GetRevision(_ context.Context, _ string, version int) (*entity.RecipeRevision, error) {
+	revision, ok := m.revisions[version]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	return &revision, nil
+}
+
+func TestRecipeDiff(t *testing.T) {
+	t.Parallel()
+
+	repo := &memRevisions{revisions: map[int]entity.RecipeRevision{
+		1: {
+			Version:  1,
+			Title:    "Plov",
+			Servings: 4,
+			Ingredients: []entity.Ingredient{
+				{Name: "rice", Quantity: 500, Unit: "g"},
+				{Name: "carrot", Quantity: 300, Unit: "g"},
+				{Name: "salt"},
+			},
+			Sections: []entity.Section{
+				{Type: "step", Content: "Fry the meat"},
+				{Type: "step", Content: "Add carrots"},
+			},
+		},
+		2: {
+			Version:  2,
+			Title:    "Tashkent plov",
+			Servings: 4,
+			Ingredients: []entity.Ingredient{
+				{Name: "rice", Quantity: 500, Unit: "g"},
+				{Name: "carrot", Quantity: 500, Unit: "g"},
+				{Name: "salt"},
+				{Name: "cumin"},
+			},
+			Sections: []entity.Section{
+				{Type: "step", Content: "Add carrots"},
+			},
+		},
+	}}
+
+	uc := usecase.NewRecipeUseCase(repo, nil, nil)
+
+	d, err := uc.Diff(context.Background(), "recipe", 1, 2)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	if d.Title == nil || d.Title.Old != "Plov" || d.Title.New != "Tashkent plov" {
+		t.Errorf("Title = %+v", d.Title)
+	}
+
+	if d.Servings != nil || d.Description != nil {
+		t.Errorf("unchanged fields reported: servings %+v, description %+v", d.Servings, d.Description)
+	}
+
+	if len(d.Ingredients) != 2 {
+		t.Fatalf("Ingredients = %+v, want 2 changes", d.Ingredients)
+	}
+
+	carrot := d.Ingredients[0]
+	if carrot.Change != entity.ChangeChanged || *carrot.OldIndex != 1 || *carrot.NewIndex != 1 || carrot.New.Quantity != 500 {
+		t.Errorf("carrot change = %+v", carrot)
+	}
+
+	cumin := d.Ingredients[1]
+	if cumin.Change != entity.ChangeAdded || cumin.OldIndex != nil || *cumin.NewIndex != 3 || cumin.New.Name != "cumin" {
+		t.Errorf("cumin change = %+v", cumin)
+	}
+
+	if len(d.Sections) != 1 || d.Sections[0].Change != entity.ChangeRemoved || *d.Sections[0].OldIndex != 0 {
+		t.Errorf("Sections = %+v, want the first step removed", d.Sections)
+	}
+}
+
+func TestRecipeDiffMissingRevision(t *testing.T) {
+	t.Parallel()
+
+	uc := usecase.NewRecipeUseCase(&memRevisions{revisions: map[int]entity.RecipeRevision{}}, nil, nil)
+
+	if _, err := uc.Diff(context.Background(), "recipe", 1, 2); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("Diff error = %v, want ErrNotFound", err)
+	}
+}
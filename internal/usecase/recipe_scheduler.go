@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"tarkib.uz/pkg/logger"
+)
+
+const (
+	_defaultScheduleInterval = 30 * time.Second
+	_scheduleBatch           = 100
+)
+
+// RecipeScheduler publishes scheduled drafts once their publish time passes.
+// Several app instances may run it; each recipe is published once.
+type RecipeScheduler struct {
+	repo     RecipeRepo
+	l        logger.Interface
+	interval time.Duration
+}
+
+func NewRecipeScheduler(r RecipeRepo, l logger.Interface, interval time.Duration) *RecipeScheduler {
+	if interval <= 0 {
+		interval = _defaultScheduleInterval
+	}
+
+	return &RecipeScheduler{
+		repo:     r,
+		l:        l,
+		interval: interval,
+	}
+}
+
+// Run publishes due recipes until ctx is cancelled.
+func (uc *RecipeScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			ids, err := uc.repo.PublishDue(ctx, time.Now(), _scheduleBatch)
+			if err != nil {
+				uc.l.Error(err, "usecase - RecipeScheduler - Run - PublishDue")
+			}
+
+			if err != nil || len(ids) < _scheduleBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// savedRecipes serves one recipe owned by "author" and records updates.
+type savedRecipes struct {
+	usecase.RecipeRepo
+	recipe entity.Recipe
+	saved  *entity.Recipe
+}
+
+func (m *savedRecipes) Get(context.Context, string) (*entity.Recipe, error) {
+	recipe := m.recipe
+
+	return &recipe, nil
+}
+
+func (m *savedRecipes) Update(_ context.Context, recipe *entity.Recipe) error {
+	m.saved = recipe
+
+	return nil
+}
+
+func TestUpdateScheduledDraft(t *testing.T) {
+	t.Parallel()
+
+	publishAt := time.Now().Add(time.Hour)
+	draft := entity.Recipe{
+		ID:          "recipe",
+		AuthorID:    "author",
+		Title:       "Plov",
+		Status:      entity.RecipeDraft,
+		Ingredients: []entity.Ingredient{{ID: "rice", Name: "rice"}},
+		Sections:    []entity.Section{{Type: entity.SectionStep, Content: "Cook the rice"}},
+	}
+
+	tests := []struct {
+		name      string
+		publishAt *time.Time
+		wantErr   error
+	}{
+		{name: "draft", wantErr: nil},
+		{name: "scheduled draft", publishAt: &publishAt, wantErr: entity.ErrRecipeIncomplete},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current := draft
+			current.PublishAt = tt.publishAt
+
+			repo := &savedRecipes{recipe: current}
+			uc := usecase.NewRecipeUseCase(repo, &memCatalog{}, nil)
+
+			_, err := uc.Update(context.Background(), "author", entity.Recipe{ID: "recipe", Title: "Plov"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update error = %v, want %v", err, tt.wantErr)
+			}
+
+			if saved := repo.saved != nil; saved != (tt.wantErr == nil) {
+				t.Errorf("saved = %v, want %v", saved, tt.wantErr == nil)
+			}
+		})
+	}
+}
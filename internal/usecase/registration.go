@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_registrationTTL         = 10 * time.Minute
+	_maxVerificationAttempts = 5
+)
+
+// Transitions of codes kept in Redis compare the stored value with what the
+// caller read and swap it in one step, so only one request can win a state.
+var (
+	_compareAndSwap = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+	return 1
+end
+return 0`)
+
+	_compareAndDelete = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+	// _claimKey moves KEYS[1] to KEYS[2], keeping its TTL, and returns its
+	// value, or nil when another request got there first.
+	_claimKey = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if not value then
+	return false
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+return value`)
+)
+
+func registrationKey(phoneNumber string) string {
+	return "registration:" + phoneNumber
+}
+
+// generateCode returns a random six digit verification code.
+func generateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func (uc *AuthUseCase) savePendingRegistration(ctx context.Context, pending entity.UserForRedis) error {
+	byteData, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+
+	return uc.RedisClient.Set(ctx, registrationKey(pending.PhoneNumber), byteData, _registrationTTL).Err()
+}
+
+// loadRegistration returns the decoded registration together with the raw
+// value that later transitions compare against.
+func (uc *AuthUseCase) loadRegistration(ctx context.Context, phoneNumber string) (entity.UserForRedis, string, error) {
+	var pending entity.UserForRedis
+
+	raw, err := uc.RedisClient.Get(ctx, registrationKey(phoneNumber)).Result()
+	if errors.Is(err, redis.Nil) {
+		return pending, "", entity.ErrRegistrationNotFound
+	}
+
+	if err != nil {
+		return pending, "", err
+	}
+
+	if err = json.Unmarshal([]byte(raw), &pending); err != nil {
+		return pending, "", err
+	}
+
+	return pending, raw, nil
+}
+
+// swapRegistration moves the registration from the raw state the caller read
+// to next. It returns the new raw value, or ErrRegistrationInProgress when
+// someone else changed it first.
+func (uc *AuthUseCase) swapRegistration(ctx context.Context, raw string, next entity.UserForRedis) (string, error) {
+	byteData, err := json.Marshal(next)
+	if err != nil {
+		return "", err
+	}
+
+	swapped, err := _compareAndSwap.Run(ctx, uc.RedisClient, []string{registrationKey(next.PhoneNumber)}, raw, byteData).Int()
+	if err != nil {
+		return "", err
+	}
+
+	if swapped == 0 {
+		return "", entity.ErrRegistrationInProgress
+	}
+
+	return string(byteData), nil
+}
+
+// consumeRegistration deletes the registration if it still holds raw.
+func (uc *AuthUseCase) consumeRegistration(ctx context.Context, phoneNumber, raw string) error {
+	return _compareAndDelete.Run(ctx, uc.RedisClient, []string{registrationKey(phoneNumber)}, raw).Err()
+}
+
+// rejectCode counts a wrong code and drops the registration after too many.
+func (uc *AuthUseCase) rejectCode(ctx context.Context, pending entity.UserForRedis, raw string) error {
+	pending.Attempts++
+	if pending.Attempts >= _maxVerificationAttempts {
+		if err := uc.consumeRegistration(ctx, pending.PhoneNumber, raw); err != nil {
+			return err
+		}
+
+		return entity.ErrRegistrationNotFound
+	}
+
+	if _, err := uc.swapRegistration(ctx, raw, pending); err != nil {
+		return err
+	}
+
+	return entity.ErrInvalidCode
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type AdminRepo struct {
+	*postgres.Postgres
+}
+
+func NewAdminRepo(pg *postgres.Postgres) *AdminRepo {
+	return &AdminRepo{pg}
+}
+
+func (a *AdminRepo) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	query := a.Builder.
+		Select(_userColumns).
+		From("users").
+		OrderBy("nickname").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.PhoneNumber != "" {
+		query = query.Where(squirrel.Like{"phone_number": "%" + escapeLike(filter.PhoneNumber) + "%"})
+	}
+
+	if filter.NickName != "" {
+		query = query.Where("lower(nickname) LIKE lower(?)", escapeLike(filter.NickName)+"%")
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0, filter.Limit)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+func (a *AdminRepo) GetUser(ctx context.Context, userID string) (*entity.User, error) {
+	sql, args, err := a.Builder.
+		Select(_userColumns).
+		From("users").
+		Where(squirrel.Eq{
+			"id": userID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := scanUser(a.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	return user, err
+}
+
+func (a *AdminRepo) UpdateRole(ctx context.Context, userID, role string) error {
+	return a.updateUser(ctx, userID, map[string]interface{}{
+		"role": role,
+	})
+}
+
+// UpdateBan bans the user, or lifts the ban when ban is nil.
+func (a *AdminRepo) UpdateBan(ctx context.Context, userID string, ban *entity.UserBan) error {
+	values := map[string]interface{}{
+		"banned_at":    nil,
+		"banned_until": nil,
+		"ban_reason":   "",
+	}
+
+	if ban != nil {
+		values["banned_at"] = ban.BannedAt
+		values["banned_until"] = ban.Until
+		values["ban_reason"] = ban.Reason
+	}
+
+	return a.updateUser(ctx, userID, values)
+}
+
+func (a *AdminRepo) updateUser(ctx context.Context, userID string, values map[string]interface{}) error {
+	sql, args, err := a.Builder.
+		Update("users").
+		SetMap(values).
+		Where(squirrel.Eq{
+			"id": userID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := a.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// escapeLike makes user input match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type AuditRepo struct {
+	*postgres.Postgres
+}
+
+func NewAuditRepo(pg *postgres.Postgres) *AuditRepo {
+	return &AuditRepo{pg}
+}
+
+func (a *AuditRepo) Insert(ctx context.Context, entry *entity.AuditEntry) error {
+	var diff []byte
+	if len(entry.Diff) > 0 {
+		var err error
+		if diff, err = json.Marshal(entry.Diff); err != nil {
+			return err
+		}
+	}
+
+	sql, args, err := a.Builder.
+		Insert("audit_log").
+		Columns("id, action, actor_id, target_type, target_id, ip, user_agent, reason, diff, created_at").
+		Values(
+			entry.ID,
+			entry.Action,
+			entry.ActorID,
+			entry.TargetType,
+			entry.TargetID,
+			entry.IP,
+			entry.UserAgent,
+			entry.Reason,
+			diff,
+			entry.CreatedAt,
+		).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
+
+func (a *AuditRepo) List(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	query := a.Builder.
+		Select("id, action, actor_id, target_type, target_id, ip, user_agent, reason, diff, created_at").
+		From("audit_log").
+		OrderBy("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.Action != "" {
+		query = query.Where(squirrel.Eq{"action": filter.Action})
+	}
+
+	if filter.ActorID != "" {
+		query = query.Where(squirrel.Eq{"actor_id": filter.ActorID})
+	}
+
+	if filter.TargetID != "" {
+		query = query.Where(squirrel.Eq{"target_id": filter.TargetID})
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where(squirrel.GtOrEq{"created_at": filter.From})
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where(squirrel.Lt{"created_at": filter.To})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]entity.AuditEntry, 0, filter.Limit)
+	for rows.Next() {
+		var (
+			entry entity.AuditEntry
+			diff  []byte
+		)
+
+		err = rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.ActorID,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.IP,
+			&entry.UserAgent,
+			&entry.Reason,
+			&diff,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diff) > 0 {
+			if err = json.Unmarshal(diff, &entry.Diff); err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteBefore purges entries older than t and returns how many were removed.
+func (a *AuditRepo) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	sql, args, err := a.Builder.
+		Delete("audit_log").
+		Where(squirrel.Lt{"created_at": t}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := a.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -2,12 +2,22 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"tarkib.uz/internal/entity"
 	"tarkib.uz/pkg/postgres"
 )
 
+const (
+	_uniqueViolationCode = "23505"
+
+	_userColumns = "id, first_name, last_name, phone_number, nickname, password, avatar, role, banned_at, banned_until, ban_reason"
+)
+
 type AuthRepo struct {
 	*postgres.Postgres
 }
@@ -16,24 +26,59 @@ func NewAuthRepo(pg *postgres.Postgres) *AuthRepo {
 	return &AuthRepo{pg}
 }
 
-func (a *AuthRepo) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
+// Create inserts the user and its outbox events in one transaction.
+func (a *AuthRepo) Create(ctx context.Context, user *entity.User, events ...entity.OutboxEvent) (*entity.User, error) {
 	sql, args, err := a.Builder.
 		Insert("users").
-		Columns("id, first_name, last_name, phone_number, nickname, password, avatar").
-		Values(user.ID, user.FirstName, user.LastName, user.PhoneNumber, user.NickName, user.Password, user.Avatar).
+		Columns("id, first_name, last_name, phone_number, nickname, password, avatar, role").
+		Values(user.ID, user.FirstName, user.LastName, user.PhoneNumber, user.NickName, user.Password, user.Avatar, user.Role).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = a.Pool.Exec(ctx, sql, args...)
+	tx, err := a.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	_, err = tx.Exec(ctx, sql, args...)
 	if err != nil {
+		return nil, uniqueViolation(err)
+	}
+
+	if err = insertOutboxEvents(ctx, tx, a.Builder, events); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
+// uniqueViolation maps violations of the users unique constraints to entity
+// errors and returns other errors unchanged.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != _uniqueViolationCode {
+		return err
+	}
+
+	switch pgErr.ConstraintName {
+	case "users_phone_number_key":
+		return entity.ErrPhoneTaken
+	case "users_nickname_key":
+		return entity.ErrNicknameTaken
+	case "reports_open_reporter_target_key":
+		return entity.ErrAlreadyReported
+	default:
+		return err
+	}
+}
+
 func (a *AuthRepo) CheckField(ctx context.Context, field string, value string) (bool, error) {
 	var count int
 
@@ -75,10 +120,8 @@ func (a *AuthRepo) UpdatePassword(ctx context.Context, phoneNumber, newPassword
 }
 
 func (a *AuthRepo) GetUserByNickName(ctx context.Context, nickname string) (*entity.User, error) {
-	var user entity.User
-
 	sql, args, err := a.Builder.
-		Select("id, first_name, last_name, phone_number, nickname, password, avatar").
+		Select(_userColumns).
 		From("users").
 		Where(squirrel.Eq{
 			"nickname": nickname,
@@ -87,20 +130,17 @@ func (a *AuthRepo) GetUserByNickName(ctx context.Context, nickname string) (*ent
 		return nil, err
 	}
 
-	err = a.Pool.QueryRow(ctx, sql, args...).
-		Scan(&user.ID, &user.FirstName, &user.LastName, &user.PhoneNumber, &user.NickName, &user.Password, &user.Avatar)
-	if err != nil {
-		return nil, err
+	user, err := scanUser(a.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
 	}
 
-	return &user, nil
+	return user, err
 }
 
 func (a *AuthRepo) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*entity.User, error) {
-	var user entity.User
-
 	sql, args, err := a.Builder.
-		Select("id, first_name, last_name, phone_number, nickname, password, avatar").
+		Select(_userColumns).
 		From("users").
 		Where(squirrel.Eq{
 			"phone_number": phoneNumber,
@@ -109,11 +149,47 @@ func (a *AuthRepo) GetUserByPhoneNumber(ctx context.Context, phoneNumber string)
 		return nil, err
 	}
 
-	err = a.Pool.QueryRow(ctx, sql, args...).
-		Scan(&user.ID, &user.FirstName, &user.LastName, &user.PhoneNumber, &user.NickName, &user.Password, &user.Avatar)
+	user, err := scanUser(a.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	return user, err
+}
+
+// scanUser reads a row selected with _userColumns.
+func scanUser(row pgx.Row) (*entity.User, error) {
+	var (
+		user        entity.User
+		bannedAt    *time.Time
+		bannedUntil *time.Time
+		banReason   string
+	)
+
+	err := row.Scan(
+		&user.ID,
+		&user.FirstName,
+		&user.LastName,
+		&user.PhoneNumber,
+		&user.NickName,
+		&user.Password,
+		&user.Avatar,
+		&user.Role,
+		&bannedAt,
+		&bannedUntil,
+		&banReason,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	if bannedAt != nil {
+		user.Ban = &entity.UserBan{
+			Reason:   banReason,
+			BannedAt: *bannedAt,
+			Until:    bannedUntil,
+		}
+	}
+
 	return &user, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _catalogColumns = "id, name, aliases, aisle, calories, protein, fat, carbohydrates, fiber, allergens, diets, density, piece_weight, updated_at"
+
+type CatalogRepo struct {
+	*postgres.Postgres
+}
+
+func NewCatalogRepo(pg *postgres.Postgres) *CatalogRepo {
+	return &CatalogRepo{pg}
+}
+
+// Search lists catalog entries by name, those starting with filter.Search
+// first.
+func (r *CatalogRepo) Search(ctx context.Context, filter entity.CatalogFilter) ([]entity.CatalogIngredient, error) {
+	query := r.Builder.
+		Select(_catalogColumns).
+		From("ingredient_catalog").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.Search != "" {
+		query = query.
+			Where(squirrel.ILike{"name": "%" + escapeLike(filter.Search) + "%"}).
+			OrderByClause("lower(name) LIKE lower(?) DESC", escapeLike(filter.Search)+"%")
+	}
+
+	return r.list(ctx, query.OrderBy("name"))
+}
+
+// Match finds entries by ID, or by lowercase name or alias.
+func (r *CatalogRepo) Match(ctx context.Context, ids, names []string) ([]entity.CatalogIngredient, error) {
+	if len(ids) == 0 && len(names) == 0 {
+		return nil, nil
+	}
+
+	query := r.Builder.
+		Select(_catalogColumns).
+		From("ingredient_catalog").
+		Where("id::text = ANY(?) OR lower(name) = ANY(?) OR aliases && ?", ids, names, names)
+
+	return r.list(ctx, query)
+}
+
+func (r *CatalogRepo) list(ctx context.Context, query squirrel.SelectBuilder) ([]entity.CatalogIngredient, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ingredients []entity.CatalogIngredient
+	for rows.Next() {
+		var ingredient entity.CatalogIngredient
+
+		err = rows.Scan(
+			&ingredient.ID,
+			&ingredient.Name,
+			&ingredient.Aliases,
+			&ingredient.Aisle,
+			&ingredient.Per100g.Calories,
+			&ingredient.Per100g.Protein,
+			&ingredient.Per100g.Fat,
+			&ingredient.Per100g.Carbohydrates,
+			&ingredient.Per100g.Fiber,
+			&ingredient.Allergens,
+			&ingredient.Diets,
+			&ingredient.Density,
+			&ingredient.PieceWeight,
+			&ingredient.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, rows.Err()
+}
+
+// Upsert saves the entries in one transaction. Entries whose name is
+// already in the catalog update it and keep its ID.
+func (r *CatalogRepo) Upsert(ctx context.Context, ingredients []entity.CatalogIngredient) (*entity.CatalogImport, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	var result entity.CatalogImport
+
+	for i := range ingredients {
+		created, err := r.upsert(ctx, tx, &ingredients[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *CatalogRepo) upsert(ctx context.Context, tx pgx.Tx, ingredient *entity.CatalogIngredient) (bool, error) {
+	sql, args, err := r.Builder.
+		Insert("ingredient_catalog").
+		Columns(_catalogColumns).
+		Values(
+			ingredient.ID,
+			ingredient.Name,
+			ingredient.Aliases,
+			ingredient.Aisle,
+			ingredient.Per100g.Calories,
+			ingredient.Per100g.Protein,
+			ingredient.Per100g.Fat,
+			ingredient.Per100g.Carbohydrates,
+			ingredient.Per100g.Fiber,
+			ingredient.Allergens,
+			ingredient.Diets,
+			ingredient.Density,
+			ingredient.PieceWeight,
+			ingredient.UpdatedAt,
+		).
+		Suffix(`ON CONFLICT ((lower(name))) DO UPDATE SET
+			name = EXCLUDED.name,
+			aliases = EXCLUDED.aliases,
+			aisle = EXCLUDED.aisle,
+			calories = EXCLUDED.calories,
+			protein = EXCLUDED.protein,
+			fat = EXCLUDED.fat,
+			carbohydrates = EXCLUDED.carbohydrates,
+			fiber = EXCLUDED.fiber,
+			allergens = EXCLUDED.allergens,
+			diets = EXCLUDED.diets,
+			density = EXCLUDED.density,
+			piece_weight = EXCLUDED.piece_weight,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, xmax = 0`).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var created bool
+	err = tx.QueryRow(ctx, sql, args...).Scan(&ingredient.ID, &created)
+
+	return created, err
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type CookRepo struct {
+	*postgres.Postgres
+}
+
+func NewCookRepo(pg *postgres.Postgres) *CookRepo {
+	return &CookRepo{pg}
+}
+
+// RecordCook stores the cook and bumps the recipe's cook count. A session
+// is counted once, so completing it again is a no-op.
+func (r *CookRepo) RecordCook(ctx context.Context, cook entity.RecipeCook) error {
+	sql, args, err := r.Builder.
+		Insert("recipe_cooks").
+		Columns("session_id, recipe_id, recipe_version, user_id, cooked_at").
+		Values(
+			cook.SessionID,
+			cook.RecipeID,
+			cook.RecipeVersion,
+			cook.UserID,
+			cook.CookedAt,
+		).
+		Suffix("ON CONFLICT (session_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	sql, args, err = r.Builder.
+		Update("recipes").
+		Set("cook_count", squirrel.Expr("cook_count + 1")).
+		Where(squirrel.Eq{
+			"id": cook.RecipeID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
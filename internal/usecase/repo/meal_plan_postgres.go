@@ -0,0 +1,302 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _mealPlanColumns = "id, owner_id, name, template, COALESCE(to_char(start_date, 'YYYY-MM-DD'), ''), days, feed_token, created_at, updated_at"
+
+type MealPlanRepo struct {
+	*postgres.Postgres
+}
+
+func NewMealPlanRepo(pg *postgres.Postgres) *MealPlanRepo {
+	return &MealPlanRepo{pg}
+}
+
+func (r *MealPlanRepo) Create(ctx context.Context, plan *entity.MealPlan) error {
+	var startDate interface{}
+	if plan.StartDate != "" {
+		startDate = plan.StartDate
+	}
+
+	sql, args, err := r.Builder.
+		Insert("meal_plans").
+		Columns("id, owner_id, name, template, start_date, days, feed_token, created_at, updated_at").
+		Values(
+			plan.ID,
+			plan.OwnerID,
+			plan.Name,
+			plan.Template,
+			startDate,
+			plan.Days,
+			plan.FeedToken,
+			plan.CreatedAt,
+			plan.UpdatedAt,
+		).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(plan.Slots) > 0 {
+		insert := r.Builder.
+			Insert("meal_plan_slots").
+			Columns("plan_id, day, meal, recipe_id, servings")
+
+		for _, slot := range plan.Slots {
+			insert = insert.Values(plan.ID, slot.Day, slot.Meal, slot.RecipeID, slot.Servings)
+		}
+
+		if sql, args, err = insert.ToSql(); err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Get loads the plan with its slots.
+func (r *MealPlanRepo) Get(ctx context.Context, planID string) (*entity.MealPlan, error) {
+	sql, args, err := r.Builder.
+		Select(_mealPlanColumns).
+		From("meal_plans").
+		Where(squirrel.Eq{
+			"id": planID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := scanMealPlan(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Slots, err = r.slots(ctx, planID); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (r *MealPlanRepo) slots(ctx context.Context, planID string) ([]entity.MealSlot, error) {
+	sql, args, err := r.Builder.
+		Select("s.day, s.meal, s.recipe_id, r.title, s.servings").
+		From("meal_plan_slots s").
+		Join("recipes r ON r.id = s.recipe_id").
+		Where(squirrel.Eq{
+			"s.plan_id": planID,
+		}).
+		OrderBy("s.day").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []entity.MealSlot{}
+
+	for rows.Next() {
+		var slot entity.MealSlot
+		if err = rows.Scan(&slot.Day, &slot.Meal, &slot.RecipeID, &slot.Title, &slot.Servings); err != nil {
+			return nil, err
+		}
+
+		slots = append(slots, slot)
+	}
+
+	return slots, rows.Err()
+}
+
+// ListByOwner lists the user's plans without slots, templates first, then
+// the latest plans.
+func (r *MealPlanRepo) ListByOwner(ctx context.Context, ownerID string) ([]entity.MealPlan, error) {
+	sql, args, err := r.Builder.
+		Select(_mealPlanColumns).
+		From("meal_plans").
+		Where(squirrel.Eq{
+			"owner_id": ownerID,
+		}).
+		OrderBy("template DESC", "start_date DESC", "created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []entity.MealPlan{}
+
+	for rows.Next() {
+		plan, err := scanMealPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		plans = append(plans, *plan)
+	}
+
+	return plans, rows.Err()
+}
+
+func (r *MealPlanRepo) Delete(ctx context.Context, planID string) error {
+	sql, args, err := r.Builder.
+		Delete("meal_plans").
+		Where(squirrel.Eq{
+			"id": planID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *MealPlanRepo) SetSlot(ctx context.Context, planID string, slot entity.MealSlot) error {
+	sql, args, err := r.Builder.
+		Insert("meal_plan_slots").
+		Columns("plan_id, day, meal, recipe_id, servings").
+		Values(planID, slot.Day, slot.Meal, slot.RecipeID, slot.Servings).
+		Suffix("ON CONFLICT (plan_id, day, meal) DO UPDATE SET recipe_id = EXCLUDED.recipe_id, servings = EXCLUDED.servings").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.execTouch(ctx, planID, sql, args)
+
+	return err
+}
+
+func (r *MealPlanRepo) RemoveSlot(ctx context.Context, planID string, day int, meal string) (bool, error) {
+	sql, args, err := r.Builder.
+		Delete("meal_plan_slots").
+		Where(squirrel.Eq{
+			"plan_id": planID,
+			"day":     day,
+			"meal":    meal,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	return r.execTouch(ctx, planID, sql, args)
+}
+
+func (r *MealPlanRepo) SetFeedToken(ctx context.Context, planID, token string) error {
+	sql, args, err := r.Builder.
+		Update("meal_plans").
+		Set("feed_token", token).
+		Where(squirrel.Eq{
+			"id": planID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// execTouch runs a change to the plan's slots and bumps its updated_at,
+// which calendar feeds use as the events' stamp.
+func (r *MealPlanRepo) execTouch(ctx context.Context, planID, sql string, args []interface{}) (bool, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	touch, touchArgs, err := r.Builder.
+		Update("meal_plans").
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{
+			"id": planID,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = tx.Exec(ctx, touch, touchArgs...); err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, tx.Commit(ctx)
+}
+
+func scanMealPlan(row pgx.Row) (*entity.MealPlan, error) {
+	var plan entity.MealPlan
+
+	err := row.Scan(
+		&plan.ID,
+		&plan.OwnerID,
+		&plan.Name,
+		&plan.Template,
+		&plan.StartDate,
+		&plan.Days,
+		&plan.FeedToken,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type NotificationRepo struct {
+	*postgres.Postgres
+}
+
+func NewNotificationRepo(pg *postgres.Postgres) *NotificationRepo {
+	return &NotificationRepo{pg}
+}
+
+func (n *NotificationRepo) Create(ctx context.Context, notification *entity.Notification) error {
+	sql, args, err := n.Builder.
+		Insert("notifications").
+		Columns("id, user_id, actor_id, type, entity_id, message, is_read, created_at").
+		Values(
+			notification.ID,
+			notification.UserID,
+			notification.ActorID,
+			notification.Type,
+			notification.EntityID,
+			notification.Message,
+			notification.IsRead,
+			notification.CreatedAt,
+		).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = n.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationRepo) List(ctx context.Context, userID string, limit, offset uint64) ([]entity.Notification, error) {
+	sql, args, err := n.Builder.
+		Select("id, user_id, actor_id, type, entity_id, message, is_read, created_at").
+		From("notifications").
+		Where(squirrel.Eq{
+			"user_id": userID,
+		}).
+		OrderBy("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := n.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]entity.Notification, 0, limit)
+	for rows.Next() {
+		var notification entity.Notification
+
+		err = rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.ActorID,
+			&notification.Type,
+			&notification.EntityID,
+			&notification.Message,
+			&notification.IsRead,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (n *NotificationRepo) MarkRead(ctx context.Context, userID, id string) (bool, error) {
+	sql, args, err := n.Builder.
+		Update("notifications").
+		Set("is_read", true).
+		Where(squirrel.Eq{
+			"id":      id,
+			"user_id": userID,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := n.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (n *NotificationRepo) MarkAllRead(ctx context.Context, userID string) error {
+	sql, args, err := n.Builder.
+		Update("notifications").
+		Set("is_read", true).
+		Where(squirrel.Eq{
+			"user_id": userID,
+			"is_read": false,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = n.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationRepo) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+
+	sql, args, err := n.Builder.
+		Select("count(id)").
+		From("notifications").
+		Where(squirrel.Eq{
+			"user_id": userID,
+			"is_read": false,
+		}).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	err = n.Pool.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
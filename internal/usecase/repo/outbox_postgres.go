@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type OutboxRepo struct {
+	*postgres.Postgres
+}
+
+func NewOutboxRepo(pg *postgres.Postgres) *OutboxRepo {
+	return &OutboxRepo{pg}
+}
+
+// insertOutboxEvents is called by other repos inside their transaction so the
+// events commit or roll back together with the domain change. Events with an
+// idempotency key that is already stored are skipped.
+func insertOutboxEvents(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, events []entity.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := builder.
+		Insert("outbox").
+		Columns("id, type, idempotency_key, payload, created_at")
+
+	for _, e := range events {
+		query = query.Values(e.ID, e.Type, e.IdempotencyKey, []byte(e.Payload), e.CreatedAt)
+	}
+
+	sql, args, err := query.Suffix("ON CONFLICT (idempotency_key) DO NOTHING").ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+
+	return err
+}
+
+// Claim leases up to limit pending events. Rows locked by another relay are
+// skipped, and a lease that expires (relay crashed) makes the row claimable again.
+func (o *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	const sql = `
+		UPDATE outbox SET locked_until = NOW() + make_interval(secs => $2), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, idempotency_key, payload, attempts, created_at`
+
+	rows, err := o.Pool.Query(ctx, sql, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]entity.OutboxEvent, 0, limit)
+	for rows.Next() {
+		var (
+			e       entity.OutboxEvent
+			payload []byte
+		)
+
+		err = rows.Scan(&e.ID, &e.Type, &e.IdempotencyKey, &payload, &e.Attempts, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Payload = payload
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func (o *OutboxRepo) MarkPublished(ctx context.Context, id string) error {
+	sql, args, err := o.Builder.
+		Update("outbox").
+		Set("published_at", time.Now().UTC()).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{
+			"id": id,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
+
+// MarkFailed records the error; the row is retried once its lease expires.
+func (o *OutboxRepo) MarkFailed(ctx context.Context, id, reason string) error {
+	sql, args, err := o.Builder.
+		Update("outbox").
+		Set("last_error", reason).
+		Where(squirrel.Eq{
+			"id": id,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _pantryColumns = "id, user_id, name, COALESCE(catalog_id::text, ''), quantity, unit, COALESCE(to_char(expires_on, 'YYYY-MM-DD'), ''), created_at, updated_at"
+
+type PantryRepo struct {
+	*postgres.Postgres
+}
+
+func NewPantryRepo(pg *postgres.Postgres) *PantryRepo {
+	return &PantryRepo{pg}
+}
+
+// List returns the user's items, those expiring first on top.
+func (r *PantryRepo) List(ctx context.Context, userID string) ([]entity.PantryItem, error) {
+	sql, args, err := r.Builder.
+		Select(_pantryColumns).
+		From("pantry_items").
+		Where(squirrel.Eq{
+			"user_id": userID,
+		}).
+		OrderBy("expires_on NULLS LAST", "lower(name)").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPantryItems(rows)
+}
+
+func (r *PantryRepo) Count(ctx context.Context, userID string) (int, error) {
+	sql, args, err := r.Builder.
+		Select("COUNT(*)").
+		From("pantry_items").
+		Where(squirrel.Eq{
+			"user_id": userID,
+		}).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&count)
+
+	return count, err
+}
+
+func (r *PantryRepo) Create(ctx context.Context, item *entity.PantryItem) error {
+	sql, args, err := r.Builder.
+		Insert("pantry_items").
+		Columns("id, user_id, name, catalog_id, quantity, unit, expires_on, created_at, updated_at").
+		Values(
+			item.ID,
+			item.UserID,
+			item.Name,
+			nullable(item.CatalogID),
+			item.Quantity,
+			item.Unit,
+			nullable(item.ExpiresOn),
+			item.CreatedAt,
+			item.UpdatedAt,
+		).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
+
+// Update saves the item and fills in CreatedAt. A changed expiry date
+// clears the reminder.
+func (r *PantryRepo) Update(ctx context.Context, item *entity.PantryItem) error {
+	sql, args, err := r.Builder.
+		Update("pantry_items").
+		Set("name", item.Name).
+		Set("catalog_id", nullable(item.CatalogID)).
+		Set("quantity", item.Quantity).
+		Set("unit", item.Unit).
+		Set("reminded_at", squirrel.Expr("CASE WHEN expires_on IS NOT DISTINCT FROM ?::date THEN reminded_at END", nullable(item.ExpiresOn))).
+		Set("expires_on", nullable(item.ExpiresOn)).
+		Set("updated_at", item.UpdatedAt).
+		Where(squirrel.Eq{
+			"id":      item.ID,
+			"user_id": item.UserID,
+		}).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&item.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+
+	return err
+}
+
+func (r *PantryRepo) Delete(ctx context.Context, userID, itemID string) error {
+	sql, args, err := r.Builder.
+		Delete("pantry_items").
+		Where(squirrel.Eq{
+			"id":      itemID,
+			"user_id": userID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimExpiring marks up to limit unreminded items expiring on or before
+// until as reminded. Locked rows are skipped, so concurrent reminders
+// don't claim the same items.
+func (r *PantryRepo) ClaimExpiring(ctx context.Context, until string, limit int) ([]entity.PantryItem, error) {
+	sql, args, err := r.Builder.
+		Update("pantry_items").
+		Set("reminded_at", time.Now().UTC()).
+		Where(`id IN (
+			SELECT id FROM pantry_items
+			WHERE reminded_at IS NULL AND expires_on <= ?::date
+			ORDER BY expires_on
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)`, until, limit).
+		Suffix("RETURNING " + _pantryColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPantryItems(rows)
+}
+
+func scanPantryItems(rows pgx.Rows) ([]entity.PantryItem, error) {
+	defer rows.Close()
+
+	items := []entity.PantryItem{}
+
+	for rows.Next() {
+		var item entity.PantryItem
+
+		err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.Name,
+			&item.CatalogID,
+			&item.Quantity,
+			&item.Unit,
+			&item.ExpiresOn,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// nullable stores empty strings as NULL.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
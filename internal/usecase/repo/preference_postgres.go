@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+type PreferenceRepo struct {
+	*postgres.Postgres
+}
+
+func NewPreferenceRepo(pg *postgres.Postgres) *PreferenceRepo {
+	return &PreferenceRepo{pg}
+}
+
+func (r *PreferenceRepo) Get(ctx context.Context, userID string) (*entity.DietaryPreferences, error) {
+	sql, args, err := r.Builder.
+		Select("allergens, diets, updated_at").
+		From("user_preferences").
+		Where(squirrel.Eq{
+			"user_id": userID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := entity.DietaryPreferences{
+		Allergens: []string{},
+		Diets:     []string{},
+	}
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&preferences.Allergens, &preferences.Diets, &preferences.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	return &preferences, nil
+}
+
+func (r *PreferenceRepo) Save(ctx context.Context, userID string, preferences *entity.DietaryPreferences) error {
+	sql, args, err := r.Builder.
+		Insert("user_preferences").
+		Columns("user_id, allergens, diets, updated_at").
+		Values(userID, preferences.Allergens, preferences.Diets, preferences.UpdatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET allergens = EXCLUDED.allergens, diets = EXCLUDED.diets, updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
@@ -0,0 +1,581 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _recipeColumns = "id, author_id, title, description, servings, ingredients, sections, status, version, hidden, publish_at, published_at, nutrition, dietary, cook_count, created_at, updated_at"
+
+type RecipeRepo struct {
+	*postgres.Postgres
+}
+
+func NewRecipeRepo(pg *postgres.Postgres) *RecipeRepo {
+	return &RecipeRepo{pg}
+}
+
+// Create inserts the recipe together with its first revision.
+func (r *RecipeRepo) Create(ctx context.Context, recipe *entity.Recipe) error {
+	ingredients, sections, err := marshalRecipeContent(recipe)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := marshalAnalysis(recipe)
+	if err != nil {
+		return err
+	}
+
+	sql, args, err := r.Builder.
+		Insert("recipes").
+		Columns("id, author_id, title, description, servings, ingredients, sections, nutrition, dietary, allergens, diets, status, version, created_at, updated_at").
+		Values(
+			recipe.ID,
+			recipe.AuthorID,
+			recipe.Title,
+			recipe.Description,
+			recipe.Servings,
+			ingredients,
+			sections,
+			analysis["nutrition"],
+			analysis["dietary"],
+			analysis["allergens"],
+			analysis["diets"],
+			recipe.Status,
+			recipe.Version,
+			recipe.CreatedAt,
+			recipe.UpdatedAt,
+		).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if err = r.insertRevision(ctx, tx, recipe, ingredients, sections); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *RecipeRepo) Get(ctx context.Context, recipeID string) (*entity.Recipe, error) {
+	sql, args, err := r.Builder.
+		Select(_recipeColumns).
+		From("recipes").
+		Where(squirrel.Eq{
+			"id": recipeID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := scanRecipe(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	return recipe, err
+}
+
+// ListPublished lists visible published recipes, newest first.
+func (r *RecipeRepo) ListPublished(ctx context.Context, filter entity.RecipeFilter) ([]entity.Recipe, error) {
+	query := r.Builder.
+		Select(_recipeColumns).
+		From("recipes").
+		Where(squirrel.Eq{
+			"status": entity.RecipePublished,
+			"hidden": false,
+		}).
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.AuthorID != "" {
+		query = query.Where(squirrel.Eq{"author_id": filter.AuthorID})
+	}
+
+	if filter.Search != "" {
+		query = query.Where(squirrel.ILike{"title": "%" + escapeLike(filter.Search) + "%"})
+	}
+
+	// Recipes not yet analyzed, or with ingredients missing from the
+	// catalog, may contain anything, so they are left out.
+	if len(filter.WithoutAllergens) > 0 {
+		query = query.Where("NOT (allergens && ?)", filter.WithoutAllergens).
+			Where("dietary IS NOT NULL AND COALESCE(jsonb_array_length(dietary->'unverified'), 0) = 0")
+	}
+
+	if len(filter.Diets) > 0 {
+		query = query.Where("diets @> ?", filter.Diets)
+	}
+
+	if len(filter.IngredientIDs) > 0 || len(filter.IngredientNames) > 0 {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM jsonb_array_elements(ingredients) i
+			WHERE i->>'catalog_id' = ANY(?) OR lower(btrim(i->>'name')) = ANY(?))`,
+			filter.IngredientIDs, filter.IngredientNames).
+			// The share of lines with a quantity that use the ingredients,
+			// or of all lines when none has a quantity.
+			OrderByClause(`(
+				SELECT COALESCE(
+					avg(m) FILTER (WHERE (i->>'quantity')::numeric > 0),
+					avg(m))
+				FROM jsonb_array_elements(ingredients) i,
+				LATERAL (SELECT COALESCE(i->>'catalog_id' = ANY(?) OR lower(btrim(i->>'name')) = ANY(?), false)::int AS m) l
+			) DESC`, filter.IngredientIDs, filter.IngredientNames)
+	}
+
+	return r.list(ctx, query.OrderBy("published_at DESC"))
+}
+
+// ListUnanalyzed returns recipes saved before nutrition and dietary flags
+// were computed.
+func (r *RecipeRepo) ListUnanalyzed(ctx context.Context, limit uint64) ([]entity.Recipe, error) {
+	query := r.Builder.
+		Select(_recipeColumns).
+		From("recipes").
+		Where("dietary IS NULL").
+		OrderBy("created_at").
+		Limit(limit)
+
+	return r.list(ctx, query)
+}
+
+// ListByAuthor lists every recipe of filter.AuthorID, last edited first.
+func (r *RecipeRepo) ListByAuthor(ctx context.Context, filter entity.RecipeFilter) ([]entity.Recipe, error) {
+	query := r.Builder.
+		Select(_recipeColumns).
+		From("recipes").
+		Where(squirrel.Eq{
+			"author_id": filter.AuthorID,
+		}).
+		OrderBy("updated_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.Status != "" {
+		query = query.Where(squirrel.Eq{"status": filter.Status})
+	}
+
+	return r.list(ctx, query)
+}
+
+func (r *RecipeRepo) list(ctx context.Context, query squirrel.SelectBuilder) ([]entity.Recipe, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []entity.Recipe
+	for rows.Next() {
+		recipe, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		recipes = append(recipes, *recipe)
+	}
+
+	return recipes, rows.Err()
+}
+
+// Update saves the content if recipe.Version is still current, bumps
+// Version and UpdatedAt on recipe and stores the result as a new revision.
+func (r *RecipeRepo) Update(ctx context.Context, recipe *entity.Recipe) error {
+	ingredients, sections, err := marshalRecipeContent(recipe)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := marshalAnalysis(recipe)
+	if err != nil {
+		return err
+	}
+
+	sql, args, err := r.Builder.
+		Update("recipes").
+		Set("title", recipe.Title).
+		Set("description", recipe.Description).
+		Set("servings", recipe.Servings).
+		Set("ingredients", ingredients).
+		Set("sections", sections).
+		SetMap(analysis).
+		Set("version", squirrel.Expr("version + 1")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{
+			"id":      recipe.ID,
+			"version": recipe.Version,
+		}).
+		Suffix("RETURNING version, updated_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&recipe.Version, &recipe.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err = r.Get(ctx, recipe.ID); err != nil {
+			return err
+		}
+
+		return entity.ErrVersionConflict
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = r.insertRevision(ctx, tx, recipe, ingredients, sections); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *RecipeRepo) insertRevision(ctx context.Context, tx pgx.Tx, recipe *entity.Recipe, ingredients, sections []byte) error {
+	sql, args, err := r.Builder.
+		Insert("recipe_revisions").
+		Columns("recipe_id, version, title, description, servings, ingredients, sections, created_at").
+		Values(
+			recipe.ID,
+			recipe.Version,
+			recipe.Title,
+			recipe.Description,
+			recipe.Servings,
+			ingredients,
+			sections,
+			recipe.UpdatedAt,
+		).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+
+	return err
+}
+
+// ListRevisions lists the revisions of the recipe newest first, without
+// their ingredients and sections.
+func (r *RecipeRepo) ListRevisions(ctx context.Context, recipeID string) ([]entity.RecipeRevision, error) {
+	sql, args, err := r.Builder.
+		Select("recipe_id, version, title, created_at").
+		From("recipe_revisions").
+		Where(squirrel.Eq{
+			"recipe_id": recipeID,
+		}).
+		OrderBy("version DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []entity.RecipeRevision
+	for rows.Next() {
+		var revision entity.RecipeRevision
+		if err = rows.Scan(&revision.RecipeID, &revision.Version, &revision.Title, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *RecipeRepo) GetRevision(ctx context.Context, recipeID string, version int) (*entity.RecipeRevision, error) {
+	sql, args, err := r.Builder.
+		Select("recipe_id, version, title, description, servings, ingredients, sections, created_at").
+		From("recipe_revisions").
+		Where(squirrel.Eq{
+			"recipe_id": recipeID,
+			"version":   version,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		revision    entity.RecipeRevision
+		ingredients []byte
+		sections    []byte
+	)
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&revision.RecipeID,
+		&revision.Version,
+		&revision.Title,
+		&revision.Description,
+		&revision.Servings,
+		&ingredients,
+		&sections,
+		&revision.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(ingredients, &revision.Ingredients); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(sections, &revision.Sections); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+func (r *RecipeRepo) UpdateStatus(ctx context.Context, recipe *entity.Recipe) error {
+	return r.updateRecipe(ctx, recipe.ID, map[string]interface{}{
+		"status":       recipe.Status,
+		"publish_at":   recipe.PublishAt,
+		"published_at": recipe.PublishedAt,
+	})
+}
+
+// UpdateAnalysis saves the computed nutrition and dietary flags.
+func (r *RecipeRepo) UpdateAnalysis(ctx context.Context, recipe *entity.Recipe) error {
+	analysis, err := marshalAnalysis(recipe)
+	if err != nil {
+		return err
+	}
+
+	return r.updateRecipe(ctx, recipe.ID, analysis)
+}
+
+func (r *RecipeRepo) SetHidden(ctx context.Context, recipeID string, hidden bool) error {
+	return r.updateRecipe(ctx, recipeID, map[string]interface{}{
+		"hidden": hidden,
+	})
+}
+
+func (r *RecipeRepo) updateRecipe(ctx context.Context, recipeID string, values map[string]interface{}) error {
+	sql, args, err := r.Builder.
+		Update("recipes").
+		SetMap(values).
+		Where(squirrel.Eq{
+			"id": recipeID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RecipeRepo) Delete(ctx context.Context, recipeID string) error {
+	sql, args, err := r.Builder.
+		Delete("recipes").
+		Where(squirrel.Eq{
+			"id": recipeID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// PublishDue publishes scheduled drafts whose time has come and returns
+// their IDs. Rows are locked so concurrent schedulers don't collide.
+func (r *RecipeRepo) PublishDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	const sql = `
+		UPDATE recipes SET status = 'published', published_at = COALESCE(published_at, publish_at), publish_at = NULL
+		WHERE id IN (
+			SELECT id FROM recipes
+			WHERE status = 'draft' AND publish_at <= $1
+			ORDER BY publish_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`
+
+	rows, err := r.Pool.Query(ctx, sql, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func marshalRecipeContent(recipe *entity.Recipe) ([]byte, []byte, error) {
+	ingredients := recipe.Ingredients
+	if ingredients == nil {
+		ingredients = []entity.Ingredient{}
+	}
+
+	sections := recipe.Sections
+	if sections == nil {
+		sections = []entity.Section{}
+	}
+
+	ingredientsData, err := json.Marshal(ingredients)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sectionsData, err := json.Marshal(sections)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ingredientsData, sectionsData, nil
+}
+
+// marshalAnalysis returns the columns computed from the ingredients.
+// allergens and diets repeat the effective dietary flags for filtering.
+func marshalAnalysis(recipe *entity.Recipe) (map[string]interface{}, error) {
+	analysis := map[string]interface{}{
+		"nutrition": nil,
+		"dietary":   nil,
+		"allergens": []string{},
+		"diets":     []string{},
+	}
+
+	if recipe.Nutrition != nil {
+		nutrition, err := json.Marshal(recipe.Nutrition)
+		if err != nil {
+			return nil, err
+		}
+
+		analysis["nutrition"] = nutrition
+	}
+
+	if recipe.Dietary != nil {
+		dietary, err := json.Marshal(recipe.Dietary)
+		if err != nil {
+			return nil, err
+		}
+
+		analysis["dietary"] = dietary
+		analysis["allergens"] = recipe.Dietary.Allergens
+		analysis["diets"] = recipe.Dietary.Diets
+	}
+
+	return analysis, nil
+}
+
+func scanRecipe(row pgx.Row) (*entity.Recipe, error) {
+	var (
+		recipe      entity.Recipe
+		ingredients []byte
+		sections    []byte
+		nutrition   []byte
+		dietary     []byte
+	)
+
+	err := row.Scan(
+		&recipe.ID,
+		&recipe.AuthorID,
+		&recipe.Title,
+		&recipe.Description,
+		&recipe.Servings,
+		&ingredients,
+		&sections,
+		&recipe.Status,
+		&recipe.Version,
+		&recipe.Hidden,
+		&recipe.PublishAt,
+		&recipe.PublishedAt,
+		&nutrition,
+		&dietary,
+		&recipe.CookCount,
+		&recipe.CreatedAt,
+		&recipe.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(ingredients, &recipe.Ingredients); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(sections, &recipe.Sections); err != nil {
+		return nil, err
+	}
+
+	if nutrition != nil {
+		if err = json.Unmarshal(nutrition, &recipe.Nutrition); err != nil {
+			return nil, err
+		}
+	}
+
+	if dietary != nil {
+		if err = json.Unmarshal(dietary, &recipe.Dietary); err != nil {
+			return nil, err
+		}
+	}
+
+	return &recipe, nil
+}
@@ -0,0 +1,240 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _reportColumns = "id, reporter_id, target_type, target_id, reason, details, status, COALESCE(moderator_id::text, ''), action, note, created_at, resolved_at"
+
+type ReportRepo struct {
+	*postgres.Postgres
+}
+
+func NewReportRepo(pg *postgres.Postgres) *ReportRepo {
+	return &ReportRepo{pg}
+}
+
+func (r *ReportRepo) Create(ctx context.Context, report *entity.Report) error {
+	sql, args, err := r.Builder.
+		Insert("reports").
+		Columns("id, reporter_id, target_type, target_id, reason, details, status, created_at").
+		Values(
+			report.ID,
+			report.ReporterID,
+			report.TargetType,
+			report.TargetID,
+			report.Reason,
+			report.Details,
+			report.Status,
+			report.CreatedAt,
+		).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+
+	return uniqueViolation(err)
+}
+
+func (r *ReportRepo) Get(ctx context.Context, reportID string) (*entity.Report, error) {
+	sql, args, err := r.Builder.
+		Select(_reportColumns).
+		From("reports").
+		Where(squirrel.Eq{
+			"id": reportID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := scanReport(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	return report, err
+}
+
+// List returns the queue oldest first, so reports are worked in order.
+func (r *ReportRepo) List(ctx context.Context, filter entity.ReportFilter) ([]entity.Report, error) {
+	query := r.Builder.
+		Select(_reportColumns).
+		From("reports").
+		OrderBy("created_at").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.Status != "" {
+		query = query.Where(squirrel.Eq{"status": filter.Status})
+	}
+
+	if filter.TargetType != "" {
+		query = query.Where(squirrel.Eq{"target_type": filter.TargetType})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]entity.Report, 0, filter.Limit)
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
+// Triage assigns an open report to the moderator. It returns ErrReportClosed
+// unless the report is open.
+func (r *ReportRepo) Triage(ctx context.Context, reportID, moderatorID string) error {
+	sql, args, err := r.Builder.
+		Update("reports").
+		Set("status", entity.ReportTriaged).
+		Set("moderator_id", moderatorID).
+		Where(squirrel.Eq{
+			"id":     reportID,
+			"status": entity.ReportOpen,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrReportClosed
+	}
+
+	return nil
+}
+
+// Resolve closes every pending report on the same target as resolved. It
+// returns them as they were before, so they can be reopened if acting on
+// the target fails; the row locks make a concurrent Resolve return nothing.
+func (r *ReportRepo) Resolve(ctx context.Context, resolved *entity.Report) ([]entity.Report, error) {
+	pending := r.Builder.
+		Select("id, status, moderator_id, action, note, resolved_at").
+		From("reports").
+		Where(squirrel.Eq{
+			"target_type": resolved.TargetType,
+			"target_id":   resolved.TargetID,
+			"status":      []string{entity.ReportOpen, entity.ReportTriaged},
+		}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := r.Builder.
+		Update("reports").
+		Set("status", resolved.Status).
+		Set("moderator_id", resolved.ModeratorID).
+		Set("action", resolved.Action).
+		Set("note", resolved.Note).
+		Set("resolved_at", resolved.ResolvedAt).
+		FromSelect(pending, "prev").
+		Where("reports.id = prev.id").
+		Suffix("RETURNING reports.id, reports.reporter_id, reports.target_type, reports.target_id, reports.reason, reports.details, " +
+			"prev.status, COALESCE(prev.moderator_id::text, ''), prev.action, prev.note, reports.created_at, prev.resolved_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []entity.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
+// Reopen puts reports returned by Resolve back the way they were.
+func (r *ReportRepo) Reopen(ctx context.Context, reports []entity.Report) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	for i := range reports {
+		var moderatorID interface{}
+		if reports[i].ModeratorID != "" {
+			moderatorID = reports[i].ModeratorID
+		}
+
+		sql, args, err := r.Builder.
+			Update("reports").
+			Set("status", reports[i].Status).
+			Set("moderator_id", moderatorID).
+			Set("action", reports[i].Action).
+			Set("note", reports[i].Note).
+			Set("resolved_at", reports[i].ResolvedAt).
+			Where(squirrel.Eq{"id": reports[i].ID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanReport(row pgx.Row) (*entity.Report, error) {
+	var report entity.Report
+
+	err := row.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Action,
+		&report.Note,
+		&report.CreatedAt,
+		&report.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
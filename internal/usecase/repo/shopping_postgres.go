@@ -0,0 +1,386 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/postgres"
+)
+
+const _shoppingListColumns = `id, owner_id, name, COALESCE(share_token, ''),
+	ARRAY(SELECT m.user_id::text FROM shopping_list_members m WHERE m.list_id = shopping_lists.id ORDER BY m.joined_at),
+	created_at, updated_at`
+
+type ShoppingRepo struct {
+	*postgres.Postgres
+}
+
+func NewShoppingRepo(pg *postgres.Postgres) *ShoppingRepo {
+	return &ShoppingRepo{pg}
+}
+
+func (r *ShoppingRepo) Create(ctx context.Context, list *entity.ShoppingList) error {
+	sql, args, err := r.Builder.
+		Insert("shopping_lists").
+		Columns("id, owner_id, name, created_at, updated_at").
+		Values(list.ID, list.OwnerID, list.Name, list.CreatedAt, list.UpdatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+
+	return err
+}
+
+// Get loads the list with its recipes and checked items.
+func (r *ShoppingRepo) Get(ctx context.Context, listID string) (*entity.ShoppingList, error) {
+	return r.get(ctx, squirrel.Eq{"id": listID})
+}
+
+func (r *ShoppingRepo) GetByShareToken(ctx context.Context, token string) (*entity.ShoppingList, error) {
+	return r.get(ctx, squirrel.Eq{"share_token": token})
+}
+
+func (r *ShoppingRepo) get(ctx context.Context, where squirrel.Eq) (*entity.ShoppingList, error) {
+	sql, args, err := r.Builder.
+		Select(_shoppingListColumns).
+		From("shopping_lists").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := scanShoppingList(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if list.Recipes, err = r.listRecipes(ctx, list.ID); err != nil {
+		return nil, err
+	}
+
+	if list.CheckedItems, err = r.checkedItems(ctx, list.ID); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *ShoppingRepo) listRecipes(ctx context.Context, listID string) ([]entity.ShoppingListRecipe, error) {
+	sql, args, err := r.Builder.
+		Select("recipe_id, title, servings, entries, added_at").
+		From("shopping_list_recipes").
+		Where(squirrel.Eq{
+			"list_id": listID,
+		}).
+		OrderBy("added_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []entity.ShoppingListRecipe{}
+
+	for rows.Next() {
+		var (
+			recipe  entity.ShoppingListRecipe
+			entries []byte
+		)
+
+		if err = rows.Scan(&recipe.RecipeID, &recipe.Title, &recipe.Servings, &entries, &recipe.AddedAt); err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(entries, &recipe.Entries); err != nil {
+			return nil, err
+		}
+
+		recipes = append(recipes, recipe)
+	}
+
+	return recipes, rows.Err()
+}
+
+func (r *ShoppingRepo) checkedItems(ctx context.Context, listID string) ([]string, error) {
+	sql, args, err := r.Builder.
+		Select("item_id").
+		From("shopping_list_checks").
+		Where(squirrel.Eq{
+			"list_id": listID,
+		}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []string
+
+	for rows.Next() {
+		var item string
+		if err = rows.Scan(&item); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ListByUser lists the lists the user owns or joined, last changed first.
+func (r *ShoppingRepo) ListByUser(ctx context.Context, userID string) ([]entity.ShoppingList, error) {
+	sql, args, err := r.Builder.
+		Select(_shoppingListColumns).
+		From("shopping_lists").
+		Where("owner_id = ? OR id IN (SELECT list_id FROM shopping_list_members WHERE user_id = ?)", userID, userID).
+		OrderBy("updated_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []entity.ShoppingList{}
+
+	for rows.Next() {
+		list, err := scanShoppingList(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, *list)
+	}
+
+	return lists, rows.Err()
+}
+
+func (r *ShoppingRepo) Delete(ctx context.Context, listID string) error {
+	sql, args, err := r.Builder.
+		Delete("shopping_lists").
+		Where(squirrel.Eq{
+			"id": listID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddRecipe adds the recipe or replaces it when it is already on the list.
+func (r *ShoppingRepo) AddRecipe(ctx context.Context, listID string, recipe entity.ShoppingListRecipe) error {
+	entries, err := json.Marshal(recipe.Entries)
+	if err != nil {
+		return err
+	}
+
+	sql, args, err := r.Builder.
+		Insert("shopping_list_recipes").
+		Columns("list_id, recipe_id, title, servings, entries, added_at").
+		Values(listID, recipe.RecipeID, recipe.Title, recipe.Servings, entries, recipe.AddedAt).
+		Suffix(`ON CONFLICT (list_id, recipe_id) DO UPDATE SET
+			title = EXCLUDED.title,
+			servings = EXCLUDED.servings,
+			entries = EXCLUDED.entries`).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	return r.exec(ctx, listID, sql, args)
+}
+
+func (r *ShoppingRepo) RemoveRecipe(ctx context.Context, listID, recipeID string) (bool, error) {
+	sql, args, err := r.Builder.
+		Delete("shopping_list_recipes").
+		Where(squirrel.Eq{
+			"list_id":   listID,
+			"recipe_id": recipeID,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	return r.execAffected(ctx, listID, sql, args)
+}
+
+func (r *ShoppingRepo) SetChecked(ctx context.Context, listID, itemID string, checked bool) error {
+	var (
+		sql  string
+		args []interface{}
+		err  error
+	)
+
+	if checked {
+		sql, args, err = r.Builder.
+			Insert("shopping_list_checks").
+			Columns("list_id, item_id").
+			Values(listID, itemID).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+	} else {
+		sql, args, err = r.Builder.
+			Delete("shopping_list_checks").
+			Where(squirrel.Eq{
+				"list_id": listID,
+				"item_id": itemID,
+			}).ToSql()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return r.exec(ctx, listID, sql, args)
+}
+
+// SetShareToken sets the share token; an empty token clears it.
+func (r *ShoppingRepo) SetShareToken(ctx context.Context, listID, token string) error {
+	var value interface{}
+	if token != "" {
+		value = token
+	}
+
+	sql, args, err := r.Builder.
+		Update("shopping_lists").
+		Set("share_token", value).
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{
+			"id": listID,
+		}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ShoppingRepo) AddMember(ctx context.Context, listID, userID string) error {
+	sql, args, err := r.Builder.
+		Insert("shopping_list_members").
+		Columns("list_id, user_id").
+		Values(listID, userID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	return r.exec(ctx, listID, sql, args)
+}
+
+func (r *ShoppingRepo) RemoveMember(ctx context.Context, listID, userID string) (bool, error) {
+	sql, args, err := r.Builder.
+		Delete("shopping_list_members").
+		Where(squirrel.Eq{
+			"list_id": listID,
+			"user_id": userID,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	return r.execAffected(ctx, listID, sql, args)
+}
+
+func (r *ShoppingRepo) exec(ctx context.Context, listID, sql string, args []interface{}) error {
+	_, err := r.execAffected(ctx, listID, sql, args)
+
+	return err
+}
+
+// execAffected runs a change to the list's rows and bumps its updated_at
+// in one transaction.
+func (r *ShoppingRepo) execAffected(ctx context.Context, listID, sql string, args []interface{}) (bool, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	touch, touchArgs, err := r.Builder.
+		Update("shopping_lists").
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{
+			"id": listID,
+		}).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = tx.Exec(ctx, touch, touchArgs...); err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, tx.Commit(ctx)
+}
+
+func scanShoppingList(row pgx.Row) (*entity.ShoppingList, error) {
+	var list entity.ShoppingList
+
+	err := row.Scan(
+		&list.ID,
+		&list.OwnerID,
+		&list.Name,
+		&list.ShareToken,
+		&list.Members,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+	"tarkib.uz/config"
+	"tarkib.uz/internal/entity"
+)
+
+const (
+	_sessionPrefix      = "session:"
+	_userSessionsPrefix = "sessions:user:"
+
+	// _lastSeenResolution limits last_seen_at writes to one per minute.
+	_lastSeenResolution = time.Minute
+)
+
+// SessionUseCase keeps a Redis hash per access token. A token is accepted
+// only while its session exists, so deleting it revokes the token.
+type SessionUseCase struct {
+	cfg         *config.Config
+	RedisClient *redis.Client
+}
+
+func NewSessionUseCase(cfg *config.Config, RedisClient *redis.Client) *SessionUseCase {
+	return &SessionUseCase{
+		cfg:         cfg,
+		RedisClient: RedisClient,
+	}
+}
+
+func (uc *SessionUseCase) ttl() time.Duration {
+	return time.Duration(uc.cfg.Casbin.AccessTokenTimeOut) * time.Second
+}
+
+// Start records a session for a token about to be issued.
+func (uc *SessionUseCase) Start(ctx context.Context, userID string, client entity.ClientInfo) (*entity.Session, error) {
+	now := time.Now().UTC()
+
+	session := &entity.Session{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	key := _sessionPrefix + session.ID
+	userKey := _userSessionsPrefix + userID
+
+	_, err := uc.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, map[string]interface{}{
+			"user_id":      session.UserID,
+			"device_name":  session.DeviceName,
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   now.Unix(),
+			"last_seen_at": now.Unix(),
+		})
+		pipe.Expire(ctx, key, uc.ttl())
+		pipe.SAdd(ctx, userKey, session.ID)
+		pipe.Expire(ctx, userKey, uc.ttl())
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Validate returns ErrSessionRevoked unless the session is alive and belongs
+// to userID, and bumps its last seen time.
+func (uc *SessionUseCase) Validate(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		return entity.ErrSessionRevoked
+	}
+
+	key := _sessionPrefix + sessionID
+
+	values, err := uc.RedisClient.HMGet(ctx, key, "user_id", "last_seen_at").Result()
+	if err != nil {
+		return err
+	}
+
+	if cast.ToString(values[0]) != userID {
+		return entity.ErrSessionRevoked
+	}
+
+	now := time.Now().UTC()
+	if now.Sub(time.Unix(cast.ToInt64(values[1]), 0)) < _lastSeenResolution {
+		return nil
+	}
+
+	return uc.RedisClient.HSet(ctx, key, "last_seen_at", now.Unix()).Err()
+}
+
+// List returns the user's live sessions, most recently used first.
+func (uc *SessionUseCase) List(ctx context.Context, userID string) ([]entity.Session, error) {
+	userKey := _userSessionsPrefix + userID
+
+	ids, err := uc.RedisClient.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.StringStringMapCmd, len(ids))
+
+	_, err = uc.RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, _sessionPrefix+id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]entity.Session, 0, len(ids))
+
+	var expired []interface{}
+
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if fields["user_id"] != userID {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		sessions = append(sessions, entity.Session{
+			ID:         ids[i],
+			UserID:     userID,
+			DeviceName: fields["device_name"],
+			UserAgent:  fields["user_agent"],
+			IP:         fields["ip"],
+			CreatedAt:  time.Unix(cast.ToInt64(fields["created_at"]), 0).UTC(),
+			LastSeenAt: time.Unix(cast.ToInt64(fields["last_seen_at"]), 0).UTC(),
+		})
+	}
+
+	if len(expired) > 0 {
+		_ = uc.RedisClient.SRem(ctx, userKey, expired...).Err() //nolint:errcheck // pruned again next time
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// Revoke signs out one of the user's sessions.
+func (uc *SessionUseCase) Revoke(ctx context.Context, userID, sessionID string) error {
+	owner, err := uc.RedisClient.HGet(ctx, _sessionPrefix+sessionID, "user_id").Result()
+	if errors.Is(err, redis.Nil) || (err == nil && owner != userID) {
+		return entity.ErrNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = uc.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, _sessionPrefix+sessionID)
+		pipe.SRem(ctx, _userSessionsPrefix+userID, sessionID)
+
+		return nil
+	})
+
+	return err
+}
+
+// RevokeAll signs the user out everywhere except the sessions in keep.
+func (uc *SessionUseCase) RevokeAll(ctx context.Context, userID string, keep ...string) error {
+	userKey := _userSessionsPrefix + userID
+
+	ids, err := uc.RedisClient.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[string]struct{}, len(keep))
+	for _, id := range keep {
+		kept[id] = struct{}{}
+	}
+
+	_, err = uc.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			if _, ok := kept[id]; ok {
+				continue
+			}
+
+			pipe.Del(ctx, _sessionPrefix+id)
+			pipe.SRem(ctx, userKey, id)
+		}
+
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,481 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // item IDs, not security
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/units"
+)
+
+const _maxListRecipes = 50
+
+// ShoppingUseCase manages shopping lists. Lists are shared with household
+// members through a link; members may add recipes and check items, the
+// owner alone shares, removes members and deletes the list.
+type ShoppingUseCase struct {
+	repo    ShoppingRepo
+	recipes RecipeRepo
+	catalog CatalogRepo
+	events  EventPublisher
+}
+
+func NewShoppingUseCase(r ShoppingRepo, recipes RecipeRepo, c CatalogRepo, e EventPublisher) *ShoppingUseCase {
+	return &ShoppingUseCase{
+		repo:    r,
+		recipes: recipes,
+		catalog: c,
+		events:  e,
+	}
+}
+
+func (uc *ShoppingUseCase) Create(ctx context.Context, userID, name string) (*entity.ShoppingList, error) {
+	now := time.Now().UTC()
+	list := entity.ShoppingList{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		Name:      name,
+		Members:   []string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := uc.repo.Create(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// List returns the lists the user owns or joined, without their items.
+func (uc *ShoppingUseCase) List(ctx context.Context, userID string) ([]entity.ShoppingList, error) {
+	lists, err := uc.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lists {
+		if lists[i].OwnerID != userID {
+			lists[i].ShareToken = ""
+		}
+	}
+
+	return lists, nil
+}
+
+func (uc *ShoppingUseCase) Get(ctx context.Context, userID, listID string) (*entity.ShoppingList, error) {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.view(list, userID), nil
+}
+
+func (uc *ShoppingUseCase) Delete(ctx context.Context, userID, listID string) error {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+
+	if list.OwnerID != userID {
+		return entity.ErrNotListOwner
+	}
+
+	return uc.repo.Delete(ctx, listID)
+}
+
+// AddRecipe adds the ingredients of the recipe scaled to servings, or to
+// the recipe's own servings when zero. Adding a recipe again replaces it.
+func (uc *ShoppingUseCase) AddRecipe(ctx context.Context, userID, listID, recipeID string, servings int) (*entity.ShoppingList, error) {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Recipes) >= _maxListRecipes {
+		return nil, entity.ErrTooManyListRecipes
+	}
+
+	recipe, err := uc.recipes.Get(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !recipe.Public() && recipe.AuthorID != userID {
+		return nil, entity.ErrNotFound
+	}
+
+	entry, err := uc.snapshot(ctx, recipe, servings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.repo.AddRecipe(ctx, listID, entry); err != nil {
+		return nil, err
+	}
+
+	return uc.changed(ctx, userID, listID)
+}
+
+func (uc *ShoppingUseCase) RemoveRecipe(ctx context.Context, userID, listID, recipeID string) (*entity.ShoppingList, error) {
+	if _, err := uc.load(ctx, userID, listID); err != nil {
+		return nil, err
+	}
+
+	removed, err := uc.repo.RemoveRecipe(ctx, listID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !removed {
+		return nil, entity.ErrNotFound
+	}
+
+	return uc.changed(ctx, userID, listID)
+}
+
+// CheckItem checks or unchecks a merged item. The check stays with the item
+// while the list's recipes change.
+func (uc *ShoppingUseCase) CheckItem(ctx context.Context, userID, listID, itemID string, checked bool) (*entity.ShoppingList, error) {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasShoppingItem(uc.view(list, userID), itemID) {
+		return nil, entity.ErrNotFound
+	}
+
+	if err = uc.repo.SetChecked(ctx, listID, itemID, checked); err != nil {
+		return nil, err
+	}
+
+	return uc.changed(ctx, userID, listID)
+}
+
+// Share issues a new share token, revoking the previous one.
+func (uc *ShoppingUseCase) Share(ctx context.Context, userID, listID string) (*entity.ShoppingList, error) {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.OwnerID != userID {
+		return nil, entity.ErrNotListOwner
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.repo.SetShareToken(ctx, listID, token); err != nil {
+		return nil, err
+	}
+
+	return uc.Get(ctx, userID, listID)
+}
+
+// Unshare revokes the share token. Members who joined stay.
+func (uc *ShoppingUseCase) Unshare(ctx context.Context, userID, listID string) (*entity.ShoppingList, error) {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.OwnerID != userID {
+		return nil, entity.ErrNotListOwner
+	}
+
+	if err = uc.repo.SetShareToken(ctx, listID, ""); err != nil {
+		return nil, err
+	}
+
+	return uc.Get(ctx, userID, listID)
+}
+
+// Join makes the user a member of the list shared with token.
+func (uc *ShoppingUseCase) Join(ctx context.Context, userID, token string) (*entity.ShoppingList, error) {
+	if token == "" {
+		return nil, entity.ErrInvalidShareToken
+	}
+
+	list, err := uc.repo.GetByShareToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, entity.ErrInvalidShareToken
+		}
+
+		return nil, err
+	}
+
+	if list.OwnerID != userID && !containsID(list.Members, userID) {
+		if err = uc.repo.AddMember(ctx, list.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.changed(ctx, userID, list.ID)
+}
+
+// RemoveMember lets the owner remove a member and a member leave.
+func (uc *ShoppingUseCase) RemoveMember(ctx context.Context, userID, listID, memberID string) error {
+	list, err := uc.load(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+
+	if list.OwnerID != userID && memberID != userID {
+		return entity.ErrNotListOwner
+	}
+
+	removed, err := uc.repo.RemoveMember(ctx, listID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return entity.ErrNotFound
+	}
+
+	_, err = uc.changed(ctx, list.OwnerID, listID)
+
+	return err
+}
+
+// load returns the list if the user owns or joined it.
+func (uc *ShoppingUseCase) load(ctx context.Context, userID, listID string) (*entity.ShoppingList, error) {
+	list, err := uc.repo.Get(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.OwnerID != userID && !containsID(list.Members, userID) {
+		return nil, entity.ErrNotFound
+	}
+
+	return list, nil
+}
+
+// view merges the list's items for userID.
+func (uc *ShoppingUseCase) view(list *entity.ShoppingList, userID string) *entity.ShoppingList {
+	list.Aisles = mergeShopping(list.Recipes, list.CheckedItems)
+
+	if list.OwnerID != userID {
+		list.ShareToken = ""
+	}
+
+	return list
+}
+
+// changed reloads the list and pushes it to everyone on it.
+func (uc *ShoppingUseCase) changed(ctx context.Context, userID, listID string) (*entity.ShoppingList, error) {
+	list, err := uc.repo.Get(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	token := list.ShareToken
+	uc.publish(ctx, uc.view(list, ""))
+	list.ShareToken = token
+
+	return uc.view(list, userID), nil
+}
+
+// publish pushes the list to its owner and members.
+func (uc *ShoppingUseCase) publish(ctx context.Context, list *entity.ShoppingList) {
+	pushEvent(ctx, uc.events, entity.Event{Type: entity.EventShoppingList}, list, append([]string{list.OwnerID}, list.Members...)...)
+}
+
+// snapshot resolves the recipe's ingredients against the catalog and
+// scales them to servings.
+func (uc *ShoppingUseCase) snapshot(ctx context.Context, recipe *entity.Recipe, servings int) (entity.ShoppingListRecipe, error) {
+	if servings <= 0 {
+		servings = recipe.Servings
+	}
+
+	factor := 1.0
+	if recipe.Servings > 0 && servings > 0 {
+		factor = float64(servings) / float64(recipe.Servings)
+	}
+
+	var ids, names []string
+
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.CatalogID != "" {
+			ids = append(ids, ingredient.CatalogID)
+		} else {
+			names = append(names, catalogName(ingredient.Name))
+		}
+	}
+
+	catalog, err := uc.catalog.Match(ctx, ids, names)
+	if err != nil {
+		return entity.ShoppingListRecipe{}, err
+	}
+
+	index := newCatalogIndex(catalog)
+
+	result := entity.ShoppingListRecipe{
+		RecipeID: recipe.ID,
+		Title:    recipe.Title,
+		Servings: servings,
+		Entries:  make([]entity.ShoppingEntry, 0, len(recipe.Ingredients)),
+		AddedAt:  time.Now().UTC(),
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		entry := entity.ShoppingEntry{
+			Key:      catalogName(ingredient.Name),
+			Name:     strings.TrimSpace(ingredient.Name),
+			Quantity: ingredient.Quantity * factor,
+			Unit:     ingredient.Unit,
+			Aisle:    entity.AisleOther,
+		}
+
+		if c := index.entry(ingredient); c != nil {
+			entry.Key = c.ID
+			entry.Name = c.Name
+
+			if c.Aisle != "" {
+				entry.Aisle = c.Aisle
+			}
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+// mergeShopping adds up the entries of the same ingredient and groups them
+// by aisle. Quantities in known units merge within their dimension; others
+// merge only with the same unit.
+func mergeShopping(recipes []entity.ShoppingListRecipe, checked []string) []entity.ShoppingAisle {
+	type merged struct {
+		item      entity.ShoppingItem
+		aisle     string
+		amount    float64
+		dimension units.Dimension
+	}
+
+	items := make(map[string]*merged)
+
+	for _, recipe := range recipes {
+		for _, entry := range recipe.Entries {
+			amount, dimension, ok := units.Normalize(entry.Quantity, entry.Unit)
+			group := entry.Key + "|" + dimension.String()
+
+			if !ok {
+				amount = entry.Quantity
+				group += "|" + strings.ToLower(strings.TrimSpace(entry.Unit))
+			}
+
+			m, found := items[group]
+			if !found {
+				m = &merged{
+					item: entity.ShoppingItem{
+						ID:      shoppingItemID(group),
+						Name:    entry.Name,
+						Unit:    strings.TrimSpace(entry.Unit),
+						Recipes: []string{},
+					},
+					aisle:     entry.Aisle,
+					dimension: dimension,
+				}
+				items[group] = m
+			}
+
+			m.amount += amount
+
+			if !containsID(m.item.Recipes, recipe.RecipeID) {
+				m.item.Recipes = append(m.item.Recipes, recipe.RecipeID)
+			}
+		}
+	}
+
+	byAisle := make(map[string][]entity.ShoppingItem)
+
+	for _, m := range items {
+		if m.dimension != units.Unknown {
+			m.item.Quantity, m.item.Unit = units.Humanize(m.amount, m.dimension)
+		} else {
+			m.item.Quantity = m.amount
+		}
+
+		// Lines without a quantity, such as salt to taste, stay without one.
+		if m.amount == 0 {
+			m.item.Quantity, m.item.Unit = 0, ""
+		}
+
+		m.item.Checked = containsID(checked, m.item.ID)
+		byAisle[m.aisle] = append(byAisle[m.aisle], m.item)
+	}
+
+	aisles := []entity.ShoppingAisle{}
+
+	for _, aisle := range entity.Aisles {
+		list := byAisle[aisle]
+		if len(list) == 0 {
+			continue
+		}
+
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Name != list[j].Name {
+				return list[i].Name < list[j].Name
+			}
+
+			return list[i].ID < list[j].ID
+		})
+
+		aisles = append(aisles, entity.ShoppingAisle{Aisle: aisle, Items: list})
+	}
+
+	return aisles
+}
+
+func shoppingItemID(group string) string {
+	sum := sha1.Sum([]byte(group)) //nolint:gosec // item IDs, not security
+
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func hasShoppingItem(list *entity.ShoppingList, itemID string) bool {
+	for _, aisle := range list.Aisles {
+		for _, item := range aisle.Items {
+			if item.ID == itemID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// randomToken returns an unguessable token for share links.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/internal/usecase"
+)
+
+// memShopping keeps lists in memory.
+type memShopping struct {
+	usecase.ShoppingRepo
+	lists map[string]*entity.ShoppingList
+}
+
+func (m *memShopping) Create(_ context.Context, list *entity.ShoppingList) error {
+	saved := *list
+	m.lists[list.ID] = &saved
+
+	return nil
+}
+
+func (m *memShopping) Get(_ context.Context, listID string) (*entity.ShoppingList, error) {
+	list, ok := m.lists[listID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	copied := *list
+	copied.Members = append([]string{}, list.Members...)
+	copied.Recipes = append([]entity.ShoppingListRecipe{}, list.Recipes...)
+	copied.CheckedItems = append([]string{}, list.CheckedItems...)
+
+	return &copied, nil
+}
+
+func (m *memShopping) GetByShareToken(ctx context.Context, token string) (*entity.ShoppingList, error) {
+	for id, list := range m.lists {
+		if list.ShareToken == token {
+			return m.Get(ctx, id)
+		}
+	}
+
+	return nil, entity.ErrNotFound
+}
+
+func (m *memShopping) AddRecipe(_ context.Context, listID string, recipe entity.ShoppingListRecipe) error {
+	m.lists[listID].Recipes = append(m.lists[listID].Recipes, recipe)
+
+	return nil
+}
+
+func (m *memShopping) SetChecked(_ context.Context, listID, itemID string, checked bool) error {
+	if checked {
+		m.lists[listID].CheckedItems = append(m.lists[listID].CheckedItems, itemID)
+	}
+
+	return nil
+}
+
+func (m *memShopping) SetShareToken(_ context.Context, listID, token string) error {
+	m.lists[listID].ShareToken = token
+
+	return nil
+}
+
+func (m *memShopping) AddMember(_ context.Context, listID, userID string) error {
+	m.lists[listID].Members = append(m.lists[listID].Members, userID)
+
+	return nil
+}
+
+// publishedRecipes serves published recipes by ID.
+type publishedRecipes struct {
+	usecase.RecipeRepo
+	recipes map[string]entity.Recipe
+}
+
+func (m *publishedRecipes) Get(_ context.Context, recipeID string) (*entity.Recipe, error) {
+	recipe, ok := m.recipes[recipeID]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+
+	return &recipe, nil
+}
+
+type noEvents struct {
+	usecase.EventPublisher
+}
+
+func (noEvents) PublishUserEvent(context.Context, string, entity.Event) error {
+	return nil
+}
+
+func TestShoppingListMerge(t *testing.T) {
+	t.Parallel()
+
+	catalog := &memCatalog{entries: []entity.CatalogIngredient{
+		{ID: "rice", Name: "Rice", Aisle: entity.AislePantry},
+		{ID: "carrot", Name: "Carrot", Aisle: entity.AisleProduce},
+	}}
+	recipes := &publishedRecipes{recipes: map[string]entity.Recipe{
+		"plov": {ID: "plov", Title: "Plov", Status: entity.RecipePublished, Servings: 2, Ingredients: []entity.Ingredient{
+			{Name: "rice", Quantity: 500, Unit: "g"},
+			{Name: "carrot", Quantity: 2},
+		}},
+		"salad": {ID: "salad", Title: "Salad", Status: entity.RecipePublished, Servings: 4, Ingredients: []entity.Ingredient{
+			{Name: "Rice", Quantity: 0.25, Unit: "kg"},
+			{Name: "carrot", Quantity: 1},
+			{Name: "salt"},
+		}},
+	}}
+	uc := usecase.NewShoppingUseCase(&memShopping{lists: map[string]*entity.ShoppingList{}}, recipes, catalog, noEvents{})
+	ctx := context.Background()
+
+	list, err := uc.Create(ctx, "owner", "Dinner")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err = uc.AddRecipe(ctx, "owner", list.ID, "plov", 4); err != nil {
+		t.Fatalf("AddRecipe: %v", err)
+	}
+
+	list, err = uc.AddRecipe(ctx, "owner", list.ID, "salad", 0)
+	if err != nil {
+		t.Fatalf("AddRecipe: %v", err)
+	}
+
+	if len(list.Aisles) != 3 {
+		t.Fatalf("Aisles = %+v, want produce, pantry and other", list.Aisles)
+	}
+
+	produce, pantry, other := list.Aisles[0], list.Aisles[1], list.Aisles[2]
+
+	if carrot := produce.Items[0]; produce.Aisle != entity.AisleProduce || carrot.Quantity != 5 || carrot.Unit != "pcs" {
+		t.Errorf("produce = %+v, want 5 pcs of carrot", produce)
+	}
+
+	rice := pantry.Items[0]
+	if pantry.Aisle != entity.AislePantry || rice.Quantity != 1.25 || rice.Unit != "kg" || len(rice.Recipes) != 2 {
+		t.Errorf("pantry = %+v, want 1.25 kg of rice from both recipes", pantry)
+	}
+
+	if salt := other.Items[0]; other.Aisle != entity.AisleOther || salt.Name != "salt" || salt.Quantity != 0 {
+		t.Errorf("other = %+v, want salt without quantity", other)
+	}
+
+	list, err = uc.CheckItem(ctx, "owner", list.ID, rice.ID, true)
+	if err != nil {
+		t.Fatalf("CheckItem: %v", err)
+	}
+
+	if !list.Aisles[1].Items[0].Checked {
+		t.Error("rice not checked")
+	}
+
+	if _, err = uc.CheckItem(ctx, "owner", list.ID, "nope", true); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("unknown item error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestShoppingListSharing(t *testing.T) {
+	t.Parallel()
+
+	uc := usecase.NewShoppingUseCase(&memShopping{lists: map[string]*entity.ShoppingList{}}, &publishedRecipes{}, &memCatalog{}, noEvents{})
+	ctx := context.Background()
+
+	list, err := uc.Create(ctx, "owner", "Groceries")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err = uc.Get(ctx, "partner", list.ID); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("Get by stranger error = %v, want ErrNotFound", err)
+	}
+
+	shared, err := uc.Share(ctx, "owner", list.ID)
+	if err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+
+	if shared.ShareToken == "" {
+		t.Fatal("no share token")
+	}
+
+	if _, err = uc.Join(ctx, "partner", "wrong"); !errors.Is(err, entity.ErrInvalidShareToken) {
+		t.Errorf("Join with wrong token error = %v, want ErrInvalidShareToken", err)
+	}
+
+	joined, err := uc.Join(ctx, "partner", shared.ShareToken)
+	if err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	if joined.ShareToken != "" {
+		t.Error("share token shown to a member")
+	}
+
+	if _, err = uc.Get(ctx, "partner", list.ID); err != nil {
+		t.Errorf("Get by member: %v", err)
+	}
+
+	if _, err = uc.Share(ctx, "partner", list.ID); !errors.Is(err, entity.ErrNotListOwner) {
+		t.Errorf("Share by member error = %v, want ErrNotListOwner", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/k0kubun/pp"
 	"tarkib.uz/config"
@@ -29,7 +30,8 @@ func (a *AuthWebAPI) SendSMS(ctx context.Context, phoneNumber string, code strin
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	_ = writer.WriteField("mobile_phone", phoneNumber)
+	// Eskiz wants the digits only.
+	_ = writer.WriteField("mobile_phone", strings.TrimPrefix(phoneNumber, "+"))
 	_ = writer.WriteField("message", "This is test from Eskiz")
 	_ = writer.WriteField("from", "tarkib.uz")
 
@@ -73,11 +75,6 @@ func (a *AuthWebAPI) SendSMS(ctx context.Context, phoneNumber string, code strin
 
 func (a *AuthWebAPI) SendSMSWithAndroid(ctx context.Context, phoneNumber string, code string, smsType string) error {
 	var message string
-	secret := os.Getenv("SECRET_SMS_GATEWAY")
-	device := os.Getenv("SMS_ANDROID_DEVICE_ID")
-	mode := "devices"
-
-	url := "https://sms.uncgateway.com/api/send/sms"
 
 	if smsType == "register" {
 		message = "tarkib.uz dan ro'yxatdan o'tish kodi: " + code
@@ -85,6 +82,21 @@ func (a *AuthWebAPI) SendSMSWithAndroid(ctx context.Context, phoneNumber string,
 		message = "tarkib.uz uchun qayta parol o'rnatish kodi: " + code
 	}
 
+	return a.sendWithAndroid(phoneNumber, message)
+}
+
+// SendWelcomeSMS greets a user who just signed up.
+func (a *AuthWebAPI) SendWelcomeSMS(ctx context.Context, phoneNumber string, firstName string) error {
+	return a.sendWithAndroid(phoneNumber, "Assalomu alaykum, "+firstName+"! tarkib.uz ga xush kelibsiz.")
+}
+
+func (a *AuthWebAPI) sendWithAndroid(phoneNumber string, message string) error {
+	secret := os.Getenv("SECRET_SMS_GATEWAY")
+	device := os.Getenv("SMS_ANDROID_DEVICE_ID")
+	mode := "devices"
+
+	url := "https://sms.uncgateway.com/api/send/sms"
+
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
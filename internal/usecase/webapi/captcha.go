@@ -0,0 +1,85 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tarkib.uz/internal/entity"
+)
+
+// CaptchaWebAPI verifies captcha tokens against a siteverify endpoint.
+// reCAPTCHA, hCaptcha and Turnstile all speak this protocol.
+type CaptchaWebAPI struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+func NewCaptchaWebAPI(verifyURL, secret string) *CaptchaWebAPI {
+	return &CaptchaWebAPI{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (a *CaptchaWebAPI) Verify(ctx context.Context, solution entity.ChallengeSolution) (bool, error) {
+	if solution.CaptchaToken == "" {
+		return false, nil
+	}
+
+	form := url.Values{
+		"secret":   {a.secret},
+		"response": {solution.CaptchaToken},
+	}
+	if solution.ClientIP != "" {
+		form.Set("remoteip", solution.ClientIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verify: unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+
+	return result.Success, nil
+}
+
+// StubCaptcha accepts a single fixed token. It stands in for a real
+// provider in local setups and tests.
+type StubCaptcha struct {
+	token string
+}
+
+func NewStubCaptcha(token string) *StubCaptcha {
+	return &StubCaptcha{
+		token: token,
+	}
+}
+
+func (s *StubCaptcha) Verify(_ context.Context, solution entity.ChallengeSolution) (bool, error) {
+	return s.token != "" && solution.CaptchaToken == s.token, nil
+}
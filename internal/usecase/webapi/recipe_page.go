@@ -0,0 +1,81 @@
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"tarkib.uz/internal/entity"
+	"tarkib.uz/pkg/safehttp"
+)
+
+// RecipePageWebAPI fetches pages to import recipes from. URLs come from
+// users, so it only connects to public addresses.
+type RecipePageWebAPI struct {
+	client *http.Client
+}
+
+func NewRecipePageWebAPI() *RecipePageWebAPI {
+	return &RecipePageWebAPI{
+		client: safehttp.NewClient(10*time.Second, 5),
+	}
+}
+
+// Fetch returns the HTML page at pageURL decoded to UTF-8.
+func (a *RecipePageWebAPI) Fetch(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, http.NoBody)
+	if err != nil {
+		return "", entity.ErrInvalidImportURL
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "TarkibRecipeImport/1.0 (+https://tarkib.uz)")
+
+	resp, err := a.client.Do(req)
+	if errors.Is(err, safehttp.ErrBlockedAddress) {
+		return "", entity.ErrInvalidImportURL
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entity.ErrImportFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: status %d", entity.ErrImportFetch, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", fmt.Errorf("%w: content type %q", entity.ErrImportFetch, contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, entity.MaxImportSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entity.ErrImportFetch, err)
+	}
+
+	if len(body) > entity.MaxImportSize {
+		return "", fmt.Errorf("%w: page larger than %d bytes", entity.ErrImportFetch, entity.MaxImportSize)
+	}
+
+	// Older Russian and Uzbek sites are often in windows-1251.
+	decoded, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return string(body), nil
+	}
+
+	page, err := io.ReadAll(decoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", entity.ErrImportFetch, err)
+	}
+
+	return string(page), nil
+}
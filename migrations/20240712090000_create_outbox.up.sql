@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL UNIQUE,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (created_at) WHERE published_at IS NULL;
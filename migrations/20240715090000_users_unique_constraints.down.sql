@@ -0,0 +1,2 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_nickname_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_number_key;
@@ -0,0 +1,24 @@
+-- Verify could create the same registration twice. Duplicates are not
+-- removed here: they are real accounts with sessions and notifications of
+-- their own, so support has to merge or rename them before this runs.
+DO $$
+DECLARE
+    phones    TEXT;
+    nicknames TEXT;
+BEGIN
+    SELECT string_agg(phone_number, ', ' ORDER BY phone_number) INTO phones
+    FROM (SELECT phone_number FROM users GROUP BY phone_number HAVING COUNT(*) > 1) d;
+
+    SELECT string_agg(nickname, ', ' ORDER BY nickname) INTO nicknames
+    FROM (SELECT nickname FROM users GROUP BY nickname HAVING COUNT(*) > 1) d;
+
+    IF phones IS NOT NULL OR nicknames IS NOT NULL THEN
+        RAISE EXCEPTION 'duplicate users must be resolved by hand before adding unique constraints'
+            USING DETAIL = format('phone numbers: %s; nicknames: %s',
+                                  COALESCE(phones, 'none'), COALESCE(nicknames, 'none'));
+    END IF;
+END
+$$;
+
+ALTER TABLE users ADD CONSTRAINT users_phone_number_key UNIQUE (phone_number);
+ALTER TABLE users ADD CONSTRAINT users_nickname_key UNIQUE (nickname);
@@ -0,0 +1,4 @@
+-- Normalized numbers are kept; only the schema changes are reverted.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_number_e164;
+DROP INDEX IF EXISTS users_phone_number_key;
+ALTER TABLE users ADD CONSTRAINT users_phone_number_key UNIQUE (phone_number);
@@ -0,0 +1,61 @@
+-- Mirrors pkg/phone: drop formatting and prefix the country code. Numbers it
+-- can't make sense of are returned unchanged.
+CREATE FUNCTION pg_temp.phone_e164(raw TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN length(d) = 9 THEN '+998' || d
+        WHEN length(d) = 12 AND d LIKE '998%' THEN '+' || d
+        ELSE raw
+    END
+    FROM (SELECT regexp_replace(raw, '\D', '', 'g') AS d) s
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- A number that doesn't normalize would fail the CHECK below on every later
+-- update of its row, so the migration stops and lists them to be fixed first.
+DO $$
+DECLARE
+    invalid TEXT;
+BEGIN
+    SELECT string_agg(phone_number || ' (id ' || id::text || ')', '; ' ORDER BY id::text) INTO invalid
+    FROM users
+    WHERE pg_temp.phone_e164(phone_number) !~ '^\+998[0-9]{9}$';
+
+    IF invalid IS NOT NULL THEN
+        RAISE EXCEPTION 'phone numbers do not normalize and must be fixed by hand'
+            USING DETAIL = invalid;
+    END IF;
+END
+$$;
+
+-- Spellings of one number may have been registered as separate users.
+-- They are real accounts, so the migration stops and lists them for support
+-- to resolve instead of picking one to keep.
+DO $$
+DECLARE
+    collisions TEXT;
+BEGIN
+    SELECT string_agg(e164 || ' (ids ' || ids || ')', '; ' ORDER BY e164) INTO collisions
+    FROM (
+        SELECT pg_temp.phone_e164(phone_number) AS e164, string_agg(id::text, ', ' ORDER BY id::text) AS ids
+        FROM users
+        GROUP BY 1
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF collisions IS NOT NULL THEN
+        RAISE EXCEPTION 'phone numbers collide after normalization and must be resolved by hand'
+            USING DETAIL = collisions;
+    END IF;
+END
+$$;
+
+UPDATE users
+SET phone_number = pg_temp.phone_e164(phone_number)
+WHERE phone_number <> pg_temp.phone_e164(phone_number);
+
+-- The old constraint compared raw strings; rebuild it as a unique index over
+-- normalized numbers under the same name the repo maps to ErrPhoneTaken.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_number_key ON users (phone_number);
+
+-- Every number is normalized by now, so the constraint is validated in full.
+ALTER TABLE users ADD CONSTRAINT users_phone_number_e164 CHECK (phone_number ~ '^\+998[0-9]{9}$');
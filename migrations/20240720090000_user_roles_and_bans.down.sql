@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_nickname_lower_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS ban_reason,
+    DROP COLUMN IF EXISTS banned_until,
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS role;
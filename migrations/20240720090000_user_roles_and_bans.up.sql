@@ -0,0 +1,10 @@
+-- Roles are embedded in access tokens at login. Promote the first admin by
+-- hand: UPDATE users SET role = 'admin' WHERE nickname = '...';
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS banned_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS banned_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS ban_reason TEXT NOT NULL DEFAULT '';
+
+-- Admin search matches nickname prefixes case-insensitively.
+CREATE INDEX IF NOT EXISTS users_nickname_lower_idx ON users (lower(nickname) text_pattern_ops);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS reports (
+    id UUID PRIMARY KEY,
+    reporter_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    moderator_id UUID,
+    action TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT reports_status_check CHECK (status IN ('open', 'triaged', 'actioned', 'dismissed'))
+);
+
+-- One pending report per reporter and target.
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_reporter_target_key
+    ON reports (reporter_id, target_type, target_id) WHERE status IN ('open', 'triaged');
+
+CREATE INDEX IF NOT EXISTS reports_status_created_at_idx ON reports (status, created_at);
+CREATE INDEX IF NOT EXISTS reports_target_idx ON reports (target_type, target_id) WHERE status IN ('open', 'triaged');
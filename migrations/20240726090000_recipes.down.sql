@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS recipes_publish_at_idx;
+DROP INDEX IF EXISTS recipes_published_idx;
+DROP INDEX IF EXISTS recipes_author_id_idx;
+
+ALTER TABLE recipes
+    DROP CONSTRAINT IF EXISTS recipes_status_check,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS publish_at,
+    DROP COLUMN IF EXISTS hidden,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS sections,
+    DROP COLUMN IF EXISTS ingredients,
+    DROP COLUMN IF EXISTS servings,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS author_id,
+    DROP COLUMN IF EXISTS id;
@@ -0,0 +1,23 @@
+ALTER TABLE recipes
+    ADD COLUMN IF NOT EXISTS id UUID PRIMARY KEY,
+    ADD COLUMN IF NOT EXISTS author_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS servings INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ingredients JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS sections JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft',
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS recipes_status_check;
+ALTER TABLE recipes ADD CONSTRAINT recipes_status_check
+    CHECK (status IN ('draft', 'published', 'unlisted', 'archived'));
+
+CREATE INDEX IF NOT EXISTS recipes_author_id_idx ON recipes (author_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS recipes_published_idx ON recipes (published_at DESC) WHERE status = 'published' AND NOT hidden;
+CREATE INDEX IF NOT EXISTS recipes_publish_at_idx ON recipes (publish_at) WHERE status = 'draft' AND publish_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recipe_revisions;
+DROP FUNCTION IF EXISTS recipe_revisions_immutable();
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS recipe_revisions (
+    recipe_id UUID NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    servings INT NOT NULL DEFAULT 0,
+    ingredients JSONB NOT NULL DEFAULT '[]',
+    sections JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, version)
+);
+
+-- Revisions are immutable; they go away only with their recipe.
+CREATE OR REPLACE FUNCTION recipe_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'recipe revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipe_revisions_no_update ON recipe_revisions;
+CREATE TRIGGER recipe_revisions_no_update BEFORE UPDATE ON recipe_revisions
+    FOR EACH ROW EXECUTE FUNCTION recipe_revisions_immutable();
+
+-- Existing recipes start their history at the current version.
+INSERT INTO recipe_revisions (recipe_id, version, title, description, servings, ingredients, sections, created_at)
+SELECT id, version, title, description, servings, ingredients, sections, updated_at FROM recipes
+ON CONFLICT DO NOTHING;
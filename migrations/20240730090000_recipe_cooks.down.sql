@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recipe_cooks;
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS cook_count;
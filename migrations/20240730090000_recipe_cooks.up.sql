@@ -0,0 +1,13 @@
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS cook_count INT NOT NULL DEFAULT 0;
+
+-- One row per completed cook session marked as cooked.
+CREATE TABLE IF NOT EXISTS recipe_cooks (
+    session_id UUID PRIMARY KEY,
+    recipe_id UUID NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+    recipe_version INT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    cooked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS recipe_cooks_recipe_id_idx ON recipe_cooks (recipe_id, cooked_at DESC);
+CREATE INDEX IF NOT EXISTS recipe_cooks_user_id_idx ON recipe_cooks (user_id, cooked_at DESC);
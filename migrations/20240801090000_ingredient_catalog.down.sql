@@ -0,0 +1,3 @@
+ALTER TABLE recipes DROP COLUMN IF EXISTS nutrition;
+
+DROP TABLE IF EXISTS ingredient_catalog;
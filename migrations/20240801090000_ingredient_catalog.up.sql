@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS ingredient_catalog (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- Lowercase alternative names recipe lines are matched by.
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    -- Per 100 g.
+    calories DOUBLE PRECISION NOT NULL DEFAULT 0,
+    protein DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fat DOUBLE PRECISION NOT NULL DEFAULT 0,
+    carbohydrates DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fiber DOUBLE PRECISION NOT NULL DEFAULT 0,
+    -- g/ml and grams per piece.
+    density DOUBLE PRECISION,
+    piece_weight DOUBLE PRECISION,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ingredient_catalog_name_key ON ingredient_catalog (lower(name));
+CREATE INDEX IF NOT EXISTS ingredient_catalog_aliases_idx ON ingredient_catalog USING GIN (aliases);
+
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS nutrition JSONB;
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS user_preferences;
+
+DROP INDEX IF EXISTS recipes_diets_idx;
+DROP INDEX IF EXISTS recipes_allergens_idx;
+
+ALTER TABLE recipes
+    DROP COLUMN IF EXISTS diets,
+    DROP COLUMN IF EXISTS allergens,
+    DROP COLUMN IF EXISTS dietary;
+
+ALTER TABLE ingredient_catalog
+    DROP COLUMN IF EXISTS diets,
+    DROP COLUMN IF EXISTS allergens;
@@ -0,0 +1,20 @@
+ALTER TABLE ingredient_catalog
+    ADD COLUMN IF NOT EXISTS allergens TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS diets TEXT[] NOT NULL DEFAULT '{}';
+
+-- dietary holds detection and overrides; allergens and diets repeat the
+-- effective flags for filtering.
+ALTER TABLE recipes
+    ADD COLUMN IF NOT EXISTS dietary JSONB,
+    ADD COLUMN IF NOT EXISTS allergens TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS diets TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS recipes_allergens_idx ON recipes USING GIN (allergens);
+CREATE INDEX IF NOT EXISTS recipes_diets_idx ON recipes USING GIN (diets);
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    diets TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
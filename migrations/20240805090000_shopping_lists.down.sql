@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS shopping_list_checks;
+DROP TABLE IF EXISTS shopping_list_recipes;
+DROP TABLE IF EXISTS shopping_list_members;
+DROP TABLE IF EXISTS shopping_lists;
+
+ALTER TABLE ingredient_catalog DROP COLUMN IF EXISTS aisle;
@@ -0,0 +1,39 @@
+ALTER TABLE ingredient_catalog ADD COLUMN IF NOT EXISTS aisle TEXT NOT NULL DEFAULT 'other';
+
+CREATE TABLE IF NOT EXISTS shopping_lists (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    share_token TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS shopping_lists_owner_id_idx ON shopping_lists (owner_id);
+
+CREATE TABLE IF NOT EXISTS shopping_list_members (
+    list_id UUID NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS shopping_list_members_user_id_idx ON shopping_list_members (user_id);
+
+-- entries snapshot the recipe's ingredients scaled to servings, so later
+-- edits of the recipe don't change the list.
+CREATE TABLE IF NOT EXISTS shopping_list_recipes (
+    list_id UUID NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    servings INT NOT NULL,
+    entries JSONB NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, recipe_id)
+);
+
+CREATE TABLE IF NOT EXISTS shopping_list_checks (
+    list_id UUID NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    item_id TEXT NOT NULL,
+    PRIMARY KEY (list_id, item_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS meal_plan_slots;
+DROP TABLE IF EXISTS meal_plans;
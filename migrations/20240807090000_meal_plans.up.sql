@@ -0,0 +1,24 @@
+-- Templates have no start_date; their days count from wherever they are
+-- copied to.
+CREATE TABLE IF NOT EXISTS meal_plans (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    template BOOLEAN NOT NULL DEFAULT FALSE,
+    start_date DATE,
+    days INT NOT NULL,
+    feed_token TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS meal_plans_owner_id_idx ON meal_plans (owner_id, start_date DESC);
+
+CREATE TABLE IF NOT EXISTS meal_plan_slots (
+    plan_id UUID NOT NULL REFERENCES meal_plans (id) ON DELETE CASCADE,
+    day INT NOT NULL,
+    meal TEXT NOT NULL,
+    recipe_id UUID NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+    servings INT NOT NULL,
+    PRIMARY KEY (plan_id, day, meal)
+);
@@ -0,0 +1,17 @@
+-- reminded_at is cleared whenever expires_on changes, so every expiry
+-- date is reminded of once.
+CREATE TABLE IF NOT EXISTS pantry_items (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    catalog_id UUID REFERENCES ingredient_catalog (id) ON DELETE SET NULL,
+    quantity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit TEXT NOT NULL DEFAULT '',
+    expires_on DATE,
+    reminded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS pantry_items_user_id_idx ON pantry_items (user_id);
+CREATE INDEX IF NOT EXISTS pantry_items_expiring_idx ON pantry_items (expires_on) WHERE reminded_at IS NULL;
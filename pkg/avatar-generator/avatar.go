@@ -2,9 +2,9 @@ package avatargenerator
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/fogleman/gg"
 )
@@ -18,27 +18,39 @@ const (
 	FontPath        = "./Roboto-Regular.ttf"
 )
 
+// GetInitial returns the upper-cased first letters of the names, or "?" when
+// both are empty.
 func GetInitial(firstName, lastName string) string {
-	if len(firstName) == 0 || len(lastName) < 2 {
-		fmt.Println("Invalid names provided.")
-		os.Exit(1)
+	var initials string
+
+	for _, name := range []string{firstName, lastName} {
+		name = strings.TrimSpace(name)
+		if r, _ := utf8.DecodeRuneInString(name); r != utf8.RuneError {
+			initials += string(r)
+		}
+	}
+
+	if initials == "" {
+		return "?"
 	}
-	return strings.ToUpper(string(firstName[0]) + string(lastName[0]))
+
+	return strings.ToUpper(initials)
 }
 
-func CreateProfileImage(initials, outputPath string) {
+// RenderProfileImage draws the initials and writes the PNG to w.
+func RenderProfileImage(initials string, w io.Writer) error {
 	dc := gg.NewContext(ImageWidth, ImageHeight)
 
 	dc.SetHexColor(BackgroundColor)
 	dc.Clear()
 
 	if err := dc.LoadFontFace(FontPath, FontSize); err != nil {
-		log.Fatalf("Could not load font: %v", err)
+		return fmt.Errorf("could not load font: %w", err)
 	}
 
 	dc.SetHexColor(TextColor)
 
 	dc.DrawStringAnchored(initials, ImageWidth/2, ImageHeight/2, 0.5, 0.5)
 
-	dc.SavePNG(outputPath)
+	return dc.EncodePNG(w)
 }
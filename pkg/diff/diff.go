@@ -0,0 +1,71 @@
+// Package diff computes edit scripts between two ordered lists.
+package diff
+
+// Op is the kind of an Edit.
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Edit turns a into b one element at a time. OldIndex is the index in a
+// (-1 for Insert), NewIndex the index in b (-1 for Delete).
+type Edit struct {
+	Op       Op
+	OldIndex int
+	NewIndex int
+}
+
+// Slices returns the shortest edit script from a list of n elements to one
+// of m elements, based on their longest common subsequence. eq reports
+// whether a[i] equals b[j].
+func Slices(n, m int, eq func(i, j int) bool) []Edit {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case eq(i, j):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, n+m)
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case eq(i, j):
+			edits = append(edits, Edit{Op: Equal, OldIndex: i, NewIndex: j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, Edit{Op: Delete, OldIndex: i, NewIndex: -1})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, OldIndex: -1, NewIndex: j})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		edits = append(edits, Edit{Op: Delete, OldIndex: i, NewIndex: -1})
+	}
+
+	for ; j < m; j++ {
+		edits = append(edits, Edit{Op: Insert, OldIndex: -1, NewIndex: j})
+	}
+
+	return edits
+}
@@ -0,0 +1,75 @@
+package diff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"tarkib.uz/pkg/diff"
+)
+
+func script(a, b []string) string {
+	edits := diff.Slices(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+
+	var sb strings.Builder
+	for _, e := range edits {
+		switch e.Op {
+		case diff.Equal:
+			sb.WriteString("=" + a[e.OldIndex] + " ")
+		case diff.Delete:
+			sb.WriteString("-" + a[e.OldIndex] + " ")
+		case diff.Insert:
+			sb.WriteString("+" + b[e.NewIndex] + " ")
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+func TestSlices(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b []string
+		want string
+	}{
+		{"empty", nil, nil, ""},
+		{"all inserted", nil, []string{"x", "y"}, "+x +y"},
+		{"all deleted", []string{"x", "y"}, nil, "-x -y"},
+		{"equal", []string{"x", "y"}, []string{"x", "y"}, "=x =y"},
+		{"insert in the middle", []string{"a", "c"}, []string{"a", "b", "c"}, "=a +b =c"},
+		{"delete in the middle", []string{"a", "b", "c"}, []string{"a", "c"}, "=a -b =c"},
+		{"replace", []string{"a", "b", "c"}, []string{"a", "x", "c"}, "=a -b +x =c"},
+		{"move", []string{"a", "b", "c"}, []string{"b", "c", "a"}, "-a =b =c +a"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := script(tt.a, tt.b); got != tt.want {
+				t.Errorf("script = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlicesIndexes(t *testing.T) {
+	t.Parallel()
+
+	a := []string{"a", "b"}
+	b := []string{"b", "c"}
+
+	got := diff.Slices(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+	want := []diff.Edit{
+		{Op: diff.Delete, OldIndex: 0, NewIndex: -1},
+		{Op: diff.Equal, OldIndex: 1, NewIndex: 0},
+		{Op: diff.Insert, OldIndex: -1, NewIndex: 1},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Slices = %+v, want %+v", got, want)
+	}
+}
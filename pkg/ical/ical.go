@@ -0,0 +1,105 @@
+// Package ical writes iCalendar (RFC 5545) feeds.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	_maxLineOctets = 75
+	_floatingTime  = "20060102T150405"
+	_utcTime       = "20060102T150405Z"
+)
+
+// Calendar is a VCALENDAR with its events.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a VEVENT. Start and End are written as floating times, so the
+// event happens at that wall clock time wherever the reader is.
+type Event struct {
+	UID         string
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+}
+
+// Encode writes the calendar with CRLF line endings and folded lines.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Stamp.UTC().Format(_utcTime))
+		line("DTSTART", e.Start.Format(_floatingTime))
+		line("DTEND", e.End.Format(_floatingTime))
+		line("SUMMARY", escape(e.Summary))
+
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeFolded splits the line into chunks of at most 75 octets without
+// breaking UTF-8 sequences; continuation lines start with a space.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := _maxLineOctets
+
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		w.WriteString(s[:cut]) //nolint:errcheck // reported by Flush
+		w.WriteString("\r\n ") //nolint:errcheck // reported by Flush
+
+		s = s[cut:]
+		// The leading space counts towards the next line.
+		limit = _maxLineOctets - 1
+	}
+
+	w.WriteString(s)      //nolint:errcheck // reported by Flush
+	w.WriteString("\r\n") //nolint:errcheck // reported by Flush
+}
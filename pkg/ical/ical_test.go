@@ -0,0 +1,59 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tarkib.uz/pkg/ical"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 8, 5, 13, 0, 0, 0, time.UTC)
+	cal := ical.Calendar{
+		ProdID: "-//Test//EN",
+		Name:   "Week, one",
+		Events: []ical.Event{{
+			UID:         "1@test",
+			Stamp:       time.Date(2024, 8, 1, 9, 30, 0, 0, time.FixedZone("UZT", 5*60*60)),
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Summary:     "Lunch: Plov; rice\nand carrots",
+			Description: strings.Repeat("ош ", 40),
+		}},
+	}
+
+	var sb strings.Builder
+	if err := cal.Encode(&sb); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	out := sb.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Week\\, one\r\n",
+		"DTSTAMP:20240801T043000Z\r\n",
+		"DTSTART:20240805T130000\r\n",
+		"DTEND:20240805T140000\r\n",
+		"SUMMARY:Lunch: Plov\\; rice\\nand carrots\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("ош ", 40)+"\r\n") {
+		t.Errorf("folded description does not unfold to the original:\n%s", out)
+	}
+}
@@ -0,0 +1,73 @@
+// Package imagevariants produces resized JPEG copies of uploaded images.
+package imagevariants
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"path"
+	"strings"
+
+	// Decoders for uploaded formats.
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const _jpegQuality = 85
+
+// Widths of the generated variants, in pixels.
+var Widths = []int{64, 256, 720}
+
+// Variant -.
+type Variant struct {
+	ObjectName string
+	Data       []byte
+}
+
+// VariantName returns "<name>_<width>.jpg" for object "<name>.<ext>".
+func VariantName(objectName string, width int) string {
+	base := strings.TrimSuffix(objectName, path.Ext(objectName))
+
+	return fmt.Sprintf("%s_%d.jpg", base, width)
+}
+
+// Generate decodes src and returns one JPEG per width. Images are never
+// upscaled; widths above the source width reuse the source size.
+func Generate(objectName string, src []byte) ([]Variant, error) {
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	variants := make([]Variant, 0, len(Widths))
+
+	for _, width := range Widths {
+		w, h := bounds.Dx(), bounds.Dy()
+		if w > width {
+			h = h * width / w
+			w = width
+		}
+
+		if h < 1 {
+			h = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: _jpegQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode variant: %w", err)
+		}
+
+		variants = append(variants, Variant{
+			ObjectName: VariantName(objectName, width),
+			Data:       buf.Bytes(),
+		})
+	}
+
+	return variants, nil
+}
@@ -0,0 +1,75 @@
+// Package membus is an in-memory stand-in for the RabbitMQ job publisher,
+// used in tests and local runs without a broker.
+package membus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ErrUnavailable is returned while the bus is switched off with SetDown.
+var ErrUnavailable = errors.New("membus - bus is unavailable")
+
+// Message -.
+type Message struct {
+	ID   string
+	Type string
+	Body []byte
+}
+
+// Bus keeps every accepted message and drops repeated IDs, like a consumer
+// with idempotency keys would.
+type Bus struct {
+	mu       sync.Mutex
+	down     bool
+	seen     map[string]struct{}
+	messages []Message
+}
+
+// New -.
+func New() *Bus {
+	return &Bus{
+		seen: make(map[string]struct{}),
+	}
+}
+
+// PublishWithID -.
+func (b *Bus) PublishWithID(_ context.Context, id, msgType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.down {
+		return ErrUnavailable
+	}
+
+	if _, ok := b.seen[id]; ok {
+		return nil
+	}
+
+	b.seen[id] = struct{}{}
+	b.messages = append(b.messages, Message{ID: id, Type: msgType, Body: body})
+
+	return nil
+}
+
+// SetDown simulates a broker outage.
+func (b *Bus) SetDown(down bool) {
+	b.mu.Lock()
+	b.down = down
+	b.mu.Unlock()
+}
+
+// Messages returns a copy of the delivered messages.
+func (b *Bus) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Message(nil), b.messages...)
+}
@@ -0,0 +1,80 @@
+// Package phone normalizes Uzbek mobile numbers to E.164 (+998XXXXXXXXX).
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// CountryCode is the calling code of Uzbekistan.
+	CountryCode = "998"
+
+	_nationalLength = 9
+)
+
+var (
+	ErrInvalid         = errors.New("invalid phone number")
+	ErrUnknownOperator = errors.New("unknown mobile operator")
+)
+
+// operators are the two digit codes that follow +998 on mobile numbers.
+var operators = map[string]string{
+	"20": "OQ",
+	"33": "Humans",
+	"50": "Ucell",
+	"55": "Uzmobile",
+	"77": "Uzmobile",
+	"88": "Mobiuz",
+	"90": "Beeline",
+	"91": "Beeline",
+	"93": "Ucell",
+	"94": "Ucell",
+	"95": "Uzmobile",
+	"97": "Mobiuz",
+	"98": "Perfectum",
+	"99": "Uzmobile",
+}
+
+// Normalize accepts a number as people type it: with or without "+", the
+// country code, spaces, dashes, dots or brackets. It returns +998XXXXXXXXX.
+func Normalize(raw string) (string, error) {
+	digits, ok := digitsOf(raw)
+	if !ok {
+		return "", ErrInvalid
+	}
+
+	switch {
+	case len(digits) == _nationalLength:
+	case len(digits) == len(CountryCode)+_nationalLength && strings.HasPrefix(digits, CountryCode):
+		digits = digits[len(CountryCode):]
+	default:
+		return "", ErrInvalid
+	}
+
+	if _, ok = operators[digits[:2]]; !ok {
+		return "", ErrUnknownOperator
+	}
+
+	return "+" + CountryCode + digits, nil
+}
+
+// digitsOf drops formatting characters. A "+" is allowed only in front.
+func digitsOf(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "+")
+
+	var b strings.Builder
+
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	return b.String(), b.Len() > 0
+}
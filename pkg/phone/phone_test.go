@@ -0,0 +1,41 @@
+package phone_test
+
+import (
+	"errors"
+	"testing"
+
+	"tarkib.uz/pkg/phone"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw  string
+		want string
+		err  error
+	}{
+		{raw: "+998 90 123-45-67", want: "+998901234567"},
+		{raw: "998901234567", want: "+998901234567"},
+		{raw: "(93) 123 45 67", want: "+998931234567"},
+		{raw: " +998.33.123.45.67 ", want: "+998331234567"},
+		{raw: "+998 71 123 45 67", err: phone.ErrUnknownOperator},
+		{raw: "+7 912 345 67 89", err: phone.ErrInvalid},
+		{raw: "99890123456", err: phone.ErrInvalid},
+		{raw: "+998 90 123 45 6x", err: phone.ErrInvalid},
+		{raw: "998+901234567", err: phone.ErrInvalid},
+		{raw: "", err: phone.ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		got, err := phone.Normalize(tt.raw)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Normalize(%q) error = %v, want %v", tt.raw, err, tt.err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
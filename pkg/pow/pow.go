@@ -0,0 +1,41 @@
+// Package pow implements a hashcash style proof-of-work: find a nonce so that
+// sha256(seed + ":" + nonce) starts with the required number of zero bits.
+package pow
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"strconv"
+)
+
+// Algorithm names the scheme for clients.
+const Algorithm = "sha256-leading-zero-bits"
+
+// Valid reports whether nonce solves the challenge.
+func Valid(seed, nonce string, difficulty int) bool {
+	sum := sha256.Sum256([]byte(seed + ":" + nonce))
+
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+// Solve brute-forces a nonce. Clients do this; the server only calls it in tests.
+func Solve(seed string, difficulty int) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if Valid(seed, nonce, difficulty) {
+			return nonce
+		}
+	}
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, x := range b {
+		if x != 0 {
+			return n + bits.LeadingZeros8(x)
+		}
+		n += 8
+	}
+
+	return n
+}
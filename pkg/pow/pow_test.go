@@ -0,0 +1,23 @@
+package pow_test
+
+import (
+	"testing"
+
+	"tarkib.uz/pkg/pow"
+)
+
+func TestSolveAndValid(t *testing.T) {
+	t.Parallel()
+
+	const seed, difficulty = "5f2c9a", 12
+
+	nonce := pow.Solve(seed, difficulty)
+
+	if !pow.Valid(seed, nonce, difficulty) {
+		t.Fatalf("nonce %q does not solve its own challenge", nonce)
+	}
+
+	if pow.Valid("other-seed", nonce, 32) {
+		t.Fatalf("nonce must not be valid for a different seed at high difficulty")
+	}
+}
@@ -0,0 +1,201 @@
+// Package rmqjobs implements a durable job queue on top of RabbitMQ with
+// delayed retries and a dead-letter queue.
+package rmqjobs
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	rmqrpc "tarkib.uz/pkg/rabbitmq/rmq_rpc"
+)
+
+const (
+	// HeaderAttempt counts how many times a job has already failed.
+	HeaderAttempt = "x-attempt"
+	// HeaderError keeps the last handler error of a dead-lettered job.
+	HeaderError = "x-last-error"
+)
+
+// Config -.
+type Config struct {
+	rmqrpc.Config
+	Exchange   string
+	Queue      string
+	RetryDelay time.Duration
+	MaxRetries int
+}
+
+// RetryQueue holds failed jobs for RetryDelay before they return to Queue.
+func (c Config) RetryQueue() string {
+	return c.Queue + ".retry"
+}
+
+// DeadLetterQueue holds jobs that exhausted their retries.
+func (c Config) DeadLetterQueue() string {
+	return c.Queue + ".dead"
+}
+
+// Connection -.
+type Connection struct {
+	Config
+	Connection *amqp.Connection
+	Channel    *amqp.Channel
+}
+
+// New -.
+func New(cfg Config) *Connection {
+	return &Connection{
+		Config: cfg,
+	}
+}
+
+// AttemptConnect -.
+func (c *Connection) AttemptConnect() error {
+	var err error
+	for i := c.Attempts; i > 0; i-- {
+		if err = c.connect(); err == nil {
+			break
+		}
+
+		log.Printf("RabbitMQ is trying to connect, attempts left: %d", i)
+		time.Sleep(c.WaitTime)
+	}
+
+	if err != nil {
+		return fmt.Errorf("rmq_jobs - AttemptConnect - c.connect: %w", err)
+	}
+
+	return nil
+}
+
+// Connect makes a single connection attempt, for callers that can't wait
+// out AttemptConnect.
+func (c *Connection) Connect() error {
+	if err := c.connect(); err != nil {
+		return fmt.Errorf("rmq_jobs - Connect - c.connect: %w", err)
+	}
+
+	return nil
+}
+
+// connect dials a new connection and closes it again if the channel or the
+// topology can't be set up, so failed attempts don't leak sockets.
+func (c *Connection) connect() error {
+	var err error
+
+	c.Connection, err = amqp.Dial(c.URL)
+	if err != nil {
+		return fmt.Errorf("amqp.Dial: %w", err)
+	}
+
+	c.Channel, err = c.Connection.Channel()
+	if err != nil {
+		_ = c.Connection.Close() //nolint:errcheck // the attempt already failed
+
+		return fmt.Errorf("c.Connection.Channel: %w", err)
+	}
+
+	if err = c.declare(); err != nil {
+		_ = c.Connection.Close() //nolint:errcheck // the attempt already failed
+
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the current connection if it is still open.
+func (c *Connection) Close() error {
+	if c.Connection == nil || c.Connection.IsClosed() {
+		return nil
+	}
+
+	return c.Connection.Close()
+}
+
+// declare sets up the exchange, the work queue, the retry queue that
+// dead-letters back into the exchange, and the dead-letter queue.
+func (c *Connection) declare() error {
+	err := c.Channel.ExchangeDeclare(
+		c.Exchange,
+		"direct",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("c.Channel.ExchangeDeclare: %w", err)
+	}
+
+	_, err = c.Channel.QueueDeclare(
+		c.Queue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("c.Channel.QueueDeclare: %w", err)
+	}
+
+	err = c.Channel.QueueBind(
+		c.Queue,
+		c.Queue,
+		c.Exchange,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("c.Channel.QueueBind: %w", err)
+	}
+
+	_, err = c.Channel.QueueDeclare(
+		c.RetryQueue(),
+		true,
+		false,
+		false,
+		false,
+		amqp.Table{
+			"x-message-ttl":             c.RetryDelay.Milliseconds(),
+			"x-dead-letter-exchange":    c.Exchange,
+			"x-dead-letter-routing-key": c.Queue,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("c.Channel.QueueDeclare retry: %w", err)
+	}
+
+	_, err = c.Channel.QueueDeclare(
+		c.DeadLetterQueue(),
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("c.Channel.QueueDeclare dead-letter: %w", err)
+	}
+
+	return nil
+}
+
+// Attempt returns the number of failed attempts recorded on d.
+func Attempt(d *amqp.Delivery) int {
+	switch v := d.Headers[HeaderAttempt].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import "time"
+
+// Option -.
+type Option func(*Publisher)
+
+// Timeout -.
+func Timeout(timeout time.Duration) Option {
+	return func(p *Publisher) {
+		p.timeout = timeout
+	}
+}
+
+// ConnWaitTime -.
+func ConnWaitTime(timeout time.Duration) Option {
+	return func(p *Publisher) {
+		p.conn.WaitTime = timeout
+	}
+}
+
+// ConnAttempts -.
+func ConnAttempts(attempts int) Option {
+	return func(p *Publisher) {
+		p.conn.Attempts = attempts
+	}
+}
@@ -0,0 +1,228 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+
+	rmqjobs "tarkib.uz/pkg/rabbitmq/rmq_jobs"
+)
+
+// ErrNotConfirmed -.
+var ErrNotConfirmed = errors.New("rmq_jobs publisher - Publisher - Publish - broker did not confirm the job")
+
+const (
+	_defaultWaitTime = 5 * time.Second
+	_defaultAttempts = 10
+	_defaultTimeout  = 5 * time.Second
+)
+
+// Publisher enqueues jobs and waits for the broker to confirm them. A closed
+// channel is reopened in the background; publishes in the meantime make one
+// connection attempt and fail fast.
+type Publisher struct {
+	conn     *rmqjobs.Connection
+	mu       sync.Mutex
+	confirms chan amqp.Confirmation
+	seq      uint64
+	broken   bool
+	stop     chan struct{}
+
+	timeout time.Duration
+}
+
+// New -.
+func New(cfg rmqjobs.Config, opts ...Option) (*Publisher, error) {
+	if cfg.WaitTime == 0 {
+		cfg.WaitTime = _defaultWaitTime
+	}
+
+	if cfg.Attempts == 0 {
+		cfg.Attempts = _defaultAttempts
+	}
+
+	p := &Publisher{
+		conn:    rmqjobs.New(cfg),
+		stop:    make(chan struct{}),
+		timeout: _defaultTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.connect(p.conn.AttemptConnect); err != nil {
+		return nil, fmt.Errorf("rmq_jobs publisher - New - p.connect: %w", err)
+	}
+
+	return p, nil
+}
+
+// connect replaces the connection using dial and puts the new channel in
+// confirm mode. The caller holds p.mu.
+func (p *Publisher) connect(dial func() error) error {
+	_ = p.conn.Close() //nolint:errcheck // the connection is being replaced
+
+	if err := dial(); err != nil {
+		return err
+	}
+
+	if err := p.conn.Channel.Confirm(false); err != nil {
+		_ = p.conn.Close() //nolint:errcheck // the attempt already failed
+
+		return fmt.Errorf("p.conn.Channel.Confirm: %w", err)
+	}
+
+	p.confirms = p.conn.Channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.seq = 0
+	p.broken = false
+
+	go p.watch(p.conn.Channel, p.conn.Channel.NotifyClose(make(chan *amqp.Error, 1)))
+
+	return nil
+}
+
+// watch waits for ch to close, whether with the connection or by a channel
+// exception, and reconnects until it succeeds or the publisher shuts down.
+func (p *Publisher) watch(ch *amqp.Channel, closed chan *amqp.Error) {
+	select {
+	case <-closed:
+	case <-p.stop:
+		return
+	}
+
+	p.mu.Lock()
+	if p.conn.Channel != ch {
+		// Already replaced by a publish.
+		p.mu.Unlock()
+
+		return
+	}
+	p.broken = true
+	p.mu.Unlock()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		p.mu.Lock()
+		if !p.broken {
+			p.mu.Unlock()
+
+			return
+		}
+		err := p.connect(p.conn.Connect)
+		p.mu.Unlock()
+
+		if err == nil {
+			return
+		}
+
+		log.Printf("rmq_jobs publisher - Publisher - watch - p.connect: %s", err)
+
+		select {
+		case <-time.After(p.conn.WaitTime):
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Publish enqueues a persistent job of the given type with a JSON payload.
+func (p *Publisher) Publish(ctx context.Context, jobType string, payload interface{}) error {
+	return p.PublishWithID(ctx, uuid.NewString(), jobType, payload)
+}
+
+// PublishWithID is Publish with a caller supplied message ID that workers
+// can use to drop duplicates.
+func (p *Publisher) PublishWithID(ctx context.Context, id, jobType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("rmq_jobs publisher - Publisher - Publish - json.Marshal: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.broken || p.conn.Connection == nil || p.conn.Connection.IsClosed() {
+		if err = p.connect(p.conn.Connect); err != nil {
+			return fmt.Errorf("rmq_jobs publisher - Publisher - Publish - p.connect: %w", err)
+		}
+	}
+
+	err = p.conn.Channel.Publish(p.conn.Exchange, p.conn.Queue, false, false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    id,
+			Type:         jobType,
+			Timestamp:    time.Now().UTC(),
+			Body:         body,
+		})
+	if err != nil {
+		return fmt.Errorf("rmq_jobs publisher - Publisher - Publish - p.conn.Channel.Publish: %w", err)
+	}
+
+	p.seq++
+
+	timeout := time.After(p.timeout)
+
+	// Confirms of earlier publishes that timed out may still be queued; skip them.
+	for {
+		select {
+		case confirm, ok := <-p.confirms:
+			if !ok {
+				return ErrNotConfirmed
+			}
+
+			if confirm.DeliveryTag < p.seq {
+				continue
+			}
+
+			if !confirm.Ack {
+				return ErrNotConfirmed
+			}
+
+			return nil
+		case <-timeout:
+			return ErrNotConfirmed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Shutdown -.
+func (p *Publisher) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.stop:
+		return nil
+	default:
+		close(p.stop)
+	}
+
+	if p.conn.Connection == nil || p.conn.Connection.IsClosed() {
+		return nil
+	}
+
+	err := p.conn.Connection.Close()
+	if err != nil {
+		return fmt.Errorf("rmq_jobs publisher - Publisher - Shutdown - p.conn.Connection.Close: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package worker
+
+import "time"
+
+// Option -.
+type Option func(*Worker)
+
+// Timeout bounds a single handler call.
+func Timeout(timeout time.Duration) Option {
+	return func(w *Worker) {
+		w.timeout = timeout
+	}
+}
+
+// Concurrency sets the number of jobs processed in parallel.
+func Concurrency(n int) Option {
+	return func(w *Worker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// ConnWaitTime -.
+func ConnWaitTime(timeout time.Duration) Option {
+	return func(w *Worker) {
+		w.conn.WaitTime = timeout
+	}
+}
+
+// ConnAttempts -.
+func ConnAttempts(attempts int) Option {
+	return func(w *Worker) {
+		w.conn.Attempts = attempts
+	}
+}
+
+// Dedup skips messages whose ID the store has already seen.
+func Dedup(store DoneStore) Option {
+	return func(w *Worker) {
+		w.done = store
+	}
+}
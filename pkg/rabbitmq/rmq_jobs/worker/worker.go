@@ -0,0 +1,302 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	"tarkib.uz/pkg/logger"
+	rmqjobs "tarkib.uz/pkg/rabbitmq/rmq_jobs"
+	rmqrpc "tarkib.uz/pkg/rabbitmq/rmq_rpc"
+)
+
+// ErrPermanent marks handler errors that retrying can't fix, e.g. a malformed
+// payload. Wrap it to send the job straight to the dead-letter queue.
+var ErrPermanent = errors.New("permanent job failure")
+
+const (
+	_defaultWaitTime    = 5 * time.Second
+	_defaultAttempts    = 10
+	_defaultTimeout     = time.Minute
+	_defaultConcurrency = 1
+)
+
+// Handler -.
+type Handler func(context.Context, *amqp.Delivery) error
+
+// DoneStore remembers processed message IDs so redelivered duplicates are
+// acknowledged without running the handler again.
+type DoneStore interface {
+	IsDone(context.Context, string) (bool, error)
+	MarkDone(context.Context, string) error
+}
+
+// Worker consumes jobs and dispatches them by type.
+type Worker struct {
+	conn   *rmqjobs.Connection
+	error  chan error
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	router map[string]Handler
+
+	timeout     time.Duration
+	concurrency int
+	done        DoneStore
+
+	logger logger.Interface
+}
+
+// New -.
+func New(cfg rmqjobs.Config, router map[string]Handler, l logger.Interface, opts ...Option) (*Worker, error) {
+	if cfg.WaitTime == 0 {
+		cfg.WaitTime = _defaultWaitTime
+	}
+
+	if cfg.Attempts == 0 {
+		cfg.Attempts = _defaultAttempts
+	}
+
+	w := &Worker{
+		conn:        rmqjobs.New(cfg),
+		error:       make(chan error),
+		stop:        make(chan struct{}),
+		router:      router,
+		timeout:     _defaultTimeout,
+		concurrency: _defaultConcurrency,
+		logger:      l,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.start(); err != nil {
+		return nil, fmt.Errorf("rmq_jobs worker - New - w.start: %w", err)
+	}
+
+	return w, nil
+}
+
+func (w *Worker) start() error {
+	err := w.conn.AttemptConnect()
+	if err != nil {
+		return err
+	}
+
+	err = w.conn.Channel.Qos(w.concurrency, 0, false)
+	if err != nil {
+		return fmt.Errorf("w.conn.Channel.Qos: %w", err)
+	}
+
+	delivery, err := w.conn.Channel.Consume(
+		w.conn.Queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("w.conn.Channel.Consume: %w", err)
+	}
+
+	closed := make(chan struct{})
+
+	var once sync.Once
+
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+
+		go func() {
+			defer w.wg.Done()
+
+			if !w.consumer(delivery) {
+				once.Do(func() { close(closed) })
+			}
+		}()
+	}
+
+	go func() {
+		select {
+		case <-closed:
+			w.wg.Wait()
+			w.reconnect()
+		case <-w.stop:
+		}
+	}()
+
+	return nil
+}
+
+// consumer returns false when the delivery channel was closed by the broker.
+func (w *Worker) consumer(delivery <-chan amqp.Delivery) bool {
+	for {
+		select {
+		case <-w.stop:
+			return true
+		case d, opened := <-delivery:
+			if !opened {
+				return false
+			}
+
+			w.serve(&d)
+		}
+	}
+}
+
+func (w *Worker) serve(d *amqp.Delivery) {
+	handler, ok := w.router[d.Type]
+	if !ok {
+		w.deadLetter(d, rmqrpc.ErrBadHandler)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	if w.isDone(ctx, d) {
+		_ = d.Ack(false) //nolint:errcheck // already processed
+
+		return
+	}
+
+	err := handler(ctx, d)
+	if err == nil {
+		w.markDone(ctx, d)
+		_ = d.Ack(false) //nolint:errcheck // redelivered on failure
+
+		return
+	}
+
+	w.logger.Error(err, "rmq_jobs worker - Worker - serve - handler "+d.Type)
+
+	attempt := rmqjobs.Attempt(d) + 1
+	if errors.Is(err, ErrPermanent) || attempt > w.conn.MaxRetries {
+		w.deadLetter(d, err)
+
+		return
+	}
+
+	w.retry(d, attempt)
+}
+
+func (w *Worker) isDone(ctx context.Context, d *amqp.Delivery) bool {
+	if w.done == nil || d.MessageId == "" {
+		return false
+	}
+
+	done, err := w.done.IsDone(ctx, d.MessageId)
+	if err != nil {
+		// Prefer a possible duplicate over a lost job.
+		w.logger.Error(err, "rmq_jobs worker - Worker - isDone")
+
+		return false
+	}
+
+	return done
+}
+
+func (w *Worker) markDone(ctx context.Context, d *amqp.Delivery) {
+	if w.done == nil || d.MessageId == "" {
+		return
+	}
+
+	if err := w.done.MarkDone(ctx, d.MessageId); err != nil {
+		w.logger.Error(err, "rmq_jobs worker - Worker - markDone")
+	}
+}
+
+// retry parks the job in the retry queue; the broker moves it back to the
+// work queue after RetryDelay.
+func (w *Worker) retry(d *amqp.Delivery, attempt int) {
+	headers := copyHeaders(d.Headers)
+	headers[rmqjobs.HeaderAttempt] = int32(attempt)
+
+	err := w.republish(d, w.conn.RetryQueue(), headers)
+	if err != nil {
+		w.logger.Error(err, "rmq_jobs worker - Worker - retry - w.republish")
+		_ = d.Nack(false, true) //nolint:errcheck // connection is gone, broker redelivers
+
+		return
+	}
+
+	_ = d.Ack(false) //nolint:errcheck // a duplicate is possible, handlers are idempotent
+}
+
+func (w *Worker) deadLetter(d *amqp.Delivery, cause error) {
+	headers := copyHeaders(d.Headers)
+	headers[rmqjobs.HeaderError] = cause.Error()
+
+	err := w.republish(d, w.conn.DeadLetterQueue(), headers)
+	if err != nil {
+		w.logger.Error(err, "rmq_jobs worker - Worker - deadLetter - w.republish")
+		_ = d.Nack(false, true) //nolint:errcheck // connection is gone, broker redelivers
+
+		return
+	}
+
+	_ = d.Ack(false) //nolint:errcheck // a duplicate is possible, handlers are idempotent
+}
+
+func (w *Worker) republish(d *amqp.Delivery, queue string, headers amqp.Table) error {
+	return w.conn.Channel.Publish("", queue, false, false,
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Type:         d.Type,
+			Timestamp:    d.Timestamp,
+			Body:         d.Body,
+		})
+}
+
+func copyHeaders(src amqp.Table) amqp.Table {
+	dst := make(amqp.Table, len(src)+1)
+	for k, v := range src {
+		dst[k] = v
+	}
+
+	return dst
+}
+
+func (w *Worker) reconnect() {
+	_ = w.conn.Close() //nolint:errcheck // the connection is being replaced
+
+	err := w.start()
+	if err != nil {
+		w.error <- err
+		close(w.error)
+	}
+}
+
+// Notify -.
+func (w *Worker) Notify() <-chan error {
+	return w.error
+}
+
+// Shutdown waits for in-flight jobs and closes the connection.
+func (w *Worker) Shutdown() error {
+	select {
+	case <-w.error:
+		return nil
+	default:
+	}
+
+	close(w.stop)
+	w.wg.Wait()
+
+	err := w.conn.Connection.Close()
+	if err != nil {
+		return fmt.Errorf("rmq_jobs worker - Worker - Shutdown - w.conn.Connection.Close: %w", err)
+	}
+
+	return nil
+}
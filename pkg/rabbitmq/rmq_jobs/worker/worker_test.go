@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/streadway/amqp"
+
+	"tarkib.uz/pkg/logger"
+	"tarkib.uz/pkg/redis"
+)
+
+// acks counts acknowledgements in place of a broker channel.
+type acks struct {
+	acked int
+}
+
+func (a *acks) Ack(uint64, bool) error {
+	a.acked++
+
+	return nil
+}
+
+func (a *acks) Nack(uint64, bool, bool) error { return nil }
+
+func (a *acks) Reject(uint64, bool) error { return nil }
+
+func TestServeRedeliveryRunsHandlerOnce(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	store := redis.NewDoneStore(goredis.NewClient(&goredis.Options{Addr: server.Addr()}), time.Hour)
+
+	runs := 0
+	w := &Worker{
+		router: map[string]Handler{
+			"user.created": func(context.Context, *amqp.Delivery) error {
+				runs++
+
+				return nil
+			},
+		},
+		timeout: time.Second,
+		done:    store,
+		logger:  logger.New("error"),
+	}
+
+	ack := &acks{}
+
+	// The same message twice, as when the broker redelivers after a lost ack.
+	for i := 0; i < 2; i++ {
+		w.serve(&amqp.Delivery{
+			Acknowledger: ack,
+			MessageId:    "user.created:u1",
+			Type:         "user.created",
+		})
+	}
+
+	if runs != 1 {
+		t.Errorf("handler ran %d times, want once", runs)
+	}
+
+	if ack.acked != 2 {
+		t.Errorf("acked %d deliveries, want both", ack.acked)
+	}
+
+	if ttl := server.TTL("jobs:done:user.created:u1"); ttl != time.Hour {
+		t.Errorf("done marker TTL = %s, want 1h", ttl)
+	}
+}
@@ -0,0 +1,57 @@
+// Package ratelimit implements a Redis backed sliding-window rate limiter.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const _keyPrefix = "ratelimit:"
+
+// The window is a sorted set of request timestamps. Expired entries are
+// trimmed and the new one is added only if the limit isn't reached, all in
+// one round trip so concurrent requests can't overshoot.
+var _slidingWindow = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, 0}
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {0, tonumber(oldest[2]) + window - now}`)
+
+// Limiter -.
+type Limiter struct {
+	client *redis.Client
+}
+
+// New -.
+func New(client *redis.Client) *Limiter {
+	return &Limiter{
+		client: client,
+	}
+}
+
+// Allow records a hit for key and reports whether it fits into limit hits
+// per window. When it doesn't, retryAfter tells when the oldest hit expires.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	now := time.Now().UnixMilli()
+
+	res, err := _slidingWindow.Run(ctx, l.client, []string{_keyPrefix + key},
+		now, window.Milliseconds(), limit, uuid.NewString()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,79 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"tarkib.uz/pkg/ratelimit"
+)
+
+func newLimiter(t *testing.T) *ratelimit.Limiter {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+
+	return ratelimit.New(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+}
+
+func TestAllowLimit(t *testing.T) {
+	t.Parallel()
+
+	limiter := newLimiter(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		allowed, _, err := limiter.Allow(ctx, "login:ip:1.2.3.4", 3, time.Minute)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+
+		if !allowed {
+			t.Fatalf("hit %d denied, want the first 3 allowed", i)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "login:ip:1.2.3.4", 3, time.Minute)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+
+	if allowed {
+		t.Fatal("hit 4 allowed, want it denied")
+	}
+
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("retryAfter = %v, want until the oldest hit leaves the window", retryAfter)
+	}
+
+	// Denied hits don't count, and other keys have their own window.
+	if allowed, _, _ = limiter.Allow(ctx, "login:ip:5.6.7.8", 3, time.Minute); !allowed {
+		t.Error("other key denied")
+	}
+}
+
+func TestAllowWindowExpiry(t *testing.T) {
+	t.Parallel()
+
+	limiter := newLimiter(t)
+	ctx := context.Background()
+	window := 200 * time.Millisecond
+
+	if allowed, _, _ := limiter.Allow(ctx, "key", 1, window); !allowed {
+		t.Fatal("first hit denied")
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "key", 1, window)
+	if err != nil || allowed {
+		t.Fatalf("second hit allowed = %v, err = %v; want denied", allowed, err)
+	}
+
+	time.Sleep(retryAfter + 10*time.Millisecond)
+
+	if allowed, _, _ = limiter.Allow(ctx, "key", 1, window); !allowed {
+		t.Error("hit after retryAfter denied, want the window to have slid")
+	}
+}
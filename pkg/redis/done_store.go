@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const _doneStorePrefix = "jobs:done:"
+
+// DoneStore records processed job IDs for the RabbitMQ worker.
+type DoneStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewDoneStore(client *redis.Client, ttl time.Duration) *DoneStore {
+	return &DoneStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (s *DoneStore) IsDone(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Exists(ctx, _doneStorePrefix+id).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (s *DoneStore) MarkDone(ctx context.Context, id string) error {
+	return s.client.Set(ctx, _doneStorePrefix+id, 1, s.ttl).Err()
+}
@@ -0,0 +1,125 @@
+// Package safehttp provides an HTTP client for fetching URLs supplied by
+// users. It only connects to public addresses, so such a URL can't be used
+// to reach services inside the network.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const (
+	_defaultTimeout      = 10 * time.Second
+	_defaultMaxRedirects = 5
+)
+
+var (
+	// ErrBlockedAddress is returned when a host resolves to an address that
+	// isn't public.
+	ErrBlockedAddress = errors.New("safehttp: address not allowed")
+
+	// ErrTooManyRedirects is returned after more than the allowed redirects.
+	ErrTooManyRedirects = errors.New("safehttp: too many redirects")
+)
+
+// Blocked ranges besides loopback, private, link-local, multicast and
+// unspecified addresses, which netip recognizes itself.
+var _blocked = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// Public reports whether addr is a public unicast address.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range _blocked {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewClient returns a client that refuses to connect to addresses that
+// aren't public. The check runs on the resolved address of every
+// connection, redirects included, so DNS names pointing inside the network
+// are refused too. Proxies from the environment are not used. Zero values
+// select a 10 second timeout and 5 redirects.
+func NewClient(timeout time.Duration, maxRedirects int) *http.Client {
+	if timeout <= 0 {
+		timeout = _defaultTimeout
+	}
+
+	if maxRedirects <= 0 {
+		maxRedirects = _defaultMaxRedirects
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return ErrTooManyRedirects
+			}
+
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("safehttp: redirect to %s URL", req.URL.Scheme)
+			}
+
+			return nil
+		},
+	}
+}
+
+// control runs after the host is resolved and before connecting.
+func control(network, address string, _ syscall.RawConn) error {
+	if network != "tcp4" && network != "tcp6" {
+		return fmt.Errorf("%w: network %s", ErrBlockedAddress, network)
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+
+	if !Public(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package safehttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"tarkib.uz/pkg/safehttp"
+)
+
+func TestPublic(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"224.0.0.1":       false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+		"64:ff9b::a00:1":  false,
+	}
+
+	for addr, want := range cases {
+		if got := safehttp.Public(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("Public(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := safehttp.NewClient(0, 0).Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("request to a loopback server succeeded")
+	}
+
+	if !errors.Is(err, safehttp.ErrBlockedAddress) {
+		t.Errorf("error = %v, want ErrBlockedAddress", err)
+	}
+}
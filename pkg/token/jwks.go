@@ -0,0 +1,58 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is the public part of a key as published in a JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS lets other services verify tokens without sharing secrets.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set. HMAC secrets are never published,
+// so services verifying HS256 tokens must be configured with the secret.
+func (m *Manager) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	for _, key := range m.keys {
+		enc := base64.RawURLEncoding
+
+		switch pub := key.verify.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: RS256,
+				N:   enc.EncodeToString(pub.N.Bytes()),
+				E:   enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: EdDSA,
+				Crv: "Ed25519",
+				X:   enc.EncodeToString(pub),
+			})
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,108 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is one entry of the key set, addressed by the kid header. A key
+// without a private part can only verify; that is how retired keys are kept
+// around until the tokens they signed expire.
+type Key struct {
+	ID     string
+	method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+// NewKey builds a key from its material: the secret for HS256, a PEM private
+// key (PKCS#1 or PKCS#8) or PEM public key (PKIX) for RS256 and EdDSA.
+func NewKey(id, algorithm string, material []byte) (Key, error) {
+	if id == "" {
+		return Key{}, errors.New("tokens - NewKey: empty key id")
+	}
+
+	if len(material) == 0 {
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: empty key material", id)
+	}
+
+	switch algorithm {
+	case HS256:
+		return Key{ID: id, method: jwt.SigningMethodHS256, sign: material, verify: material}, nil
+	case RS256, EdDSA:
+		return newAsymmetricKey(id, algorithm, material)
+	default:
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: unsupported algorithm %q", id, algorithm)
+	}
+}
+
+func newAsymmetricKey(id, algorithm string, material []byte) (Key, error) {
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: no PEM block", id)
+	}
+
+	var parsed interface{}
+
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: unexpected PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: %w", id, err)
+	}
+
+	key := Key{ID: id}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.sign, key.verify = k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.verify = k
+	case ed25519.PrivateKey:
+		key.sign, key.verify = k, k.Public()
+	case ed25519.PublicKey:
+		key.verify = k
+	default:
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: unsupported key type %T", id, parsed)
+	}
+
+	_, isRSA := key.verify.(*rsa.PublicKey)
+
+	switch {
+	case algorithm == RS256 && isRSA:
+		key.method = jwt.SigningMethodRS256
+	case algorithm == EdDSA && !isRSA:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, fmt.Errorf("tokens - NewKey - %s: key doesn't match algorithm %s", id, algorithm)
+	}
+
+	return key, nil
+}
+
+// CanSign reports whether the key holds a private part.
+func (k Key) CanSign() bool {
+	return k.sign != nil
+}
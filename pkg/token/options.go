@@ -0,0 +1,34 @@
+package tokens
+
+import "time"
+
+// Option -.
+type Option func(*Manager)
+
+// Issuer -.
+func Issuer(issuer string) Option {
+	return func(m *Manager) {
+		m.issuer = issuer
+	}
+}
+
+// Audience sets the aud claim. Parse accepts tokens for the first audience.
+func Audience(audience ...string) Option {
+	return func(m *Manager) {
+		m.audience = audience
+	}
+}
+
+// TTL -.
+func TTL(ttl time.Duration) Option {
+	return func(m *Manager) {
+		m.ttl = ttl
+	}
+}
+
+// Leeway tolerates clock skew between services.
+func Leeway(leeway time.Duration) Option {
+	return func(m *Manager) {
+		m.leeway = leeway
+	}
+}
@@ -1,91 +1,144 @@
+// Package tokens issues and verifies access tokens. Tokens carry standard
+// numeric claims and a kid header, so signing keys can be rotated while
+// tokens signed with the previous key stay valid until they expire.
 package tokens
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
-	"tarkib.uz/config"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTHandler ...
-type JWTHandler struct {
-	Sub       string
-	Iss       string
-	Exp       string
-	Iat       string
-	Aud       []string
-	Role      string
-	SigninKey string
-	Log       *log.Logger
-	Token     string
-	Timeout   int
+const (
+	_defaultTTL    = time.Hour
+	_defaultLeeway = 30 * time.Second
+)
+
+// ErrExpired is matched by Parse errors of expired tokens.
+var ErrExpired = jwt.ErrTokenExpired
+
+// Claims of an access token.
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
 }
 
-type CustomClaims struct {
-	*jwt.Token
-	Sub  string   `json:"sub"`
-	Exp  float64  `json:"exp"`
-	Iat  float64  `json:"iat"`
-	Aud  []string `json:"aud"`
-	Role string   `json:"role"`
+// Manager signs with one key of the set and verifies with any of them.
+type Manager struct {
+	keys     map[string]Key
+	signing  Key
+	issuer   string
+	audience []string
+	ttl      time.Duration
+	leeway   time.Duration
+	parser   *jwt.Parser
 }
 
-// GenerateAuthJWT ...
-func (jwtHandler *JWTHandler) GenerateAuthJWT() (access, refresh string, err error) {
-	cfg, err := config.NewConfig()
-	if err != nil {
-		return "", "", err
+// New returns a manager signing with the key signingKeyID.
+func New(keys []Key, signingKeyID string, opts ...Option) (*Manager, error) {
+	m := &Manager{
+		keys:   make(map[string]Key, len(keys)),
+		ttl:    _defaultTTL,
+		leeway: _defaultLeeway,
 	}
 
-	var (
-		accessToken  *jwt.Token
-		refreshToken *jwt.Token
-		claims       jwt.MapClaims
-		rtClaims     jwt.MapClaims
-	)
-	accessToken = jwt.New(jwt.SigningMethodHS256)
-	refreshToken = jwt.New(jwt.SigningMethodHS256)
-	claims = accessToken.Claims.(jwt.MapClaims)
-	claims["sub"] = jwtHandler.Sub
-	claims["exp"] = jwtHandler.Exp
-	claims["iat"] = time.Now().Unix()
-	claims["role"] = jwtHandler.Role
-	claims["aud"] = jwtHandler.Aud
-	access, err = accessToken.SignedString([]byte(cfg.Casbin.SigningKey))
-	if err != nil {
-		log.Println("error generating access token", err)
-		return
+	for _, key := range keys {
+		if _, ok := m.keys[key.ID]; ok {
+			return nil, fmt.Errorf("tokens - New: duplicate key id %q", key.ID)
+		}
+
+		m.keys[key.ID] = key
 	}
 
-	rtClaims = refreshToken.Claims.(jwt.MapClaims)
-	rtClaims["sub"] = jwtHandler.Sub
-	refresh, err = refreshToken.SignedString([]byte(jwtHandler.SigninKey))
-	if err != nil {
-		log.Println("error generating refresh token", err)
-		return
+	signing, ok := m.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("tokens - New: unknown signing key %q", signingKeyID)
 	}
-	return
-}
 
-// ExtractClaims ...
-func (jwtHandler *JWTHandler) ExtractClaims() (jwt.MapClaims, error) {
-	var (
-		token *jwt.Token
-		err   error
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("tokens - New: signing key %q has no private part", signingKeyID)
+	}
+
+	m.signing = signing
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.issuer == "" || len(m.audience) == 0 {
+		return nil, errors.New("tokens - New: issuer and audience are required")
+	}
+
+	m.parser = jwt.NewParser(
+		jwt.WithValidMethods([]string{HS256, RS256, EdDSA}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience[0]),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(m.leeway),
 	)
 
-	token, err = jwt.Parse(jwtHandler.Token, func(t *jwt.Token) (interface{}, error) {
-		return []byte(jwtHandler.SigninKey), nil
+	return m, nil
+}
+
+// TTL is the lifetime of issued tokens.
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Issue signs an access token. sessionID becomes the jti.
+func (m *Manager) Issue(subject, sessionID, role string) (string, error) {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(m.signing.method, Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   subject,
+			Audience:  m.audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        sessionID,
+		},
 	})
+	token.Header["kid"] = m.signing.ID
+
+	return token.SignedString(m.signing.sign)
+}
+
+// Parse verifies the signature with the key named by kid, and exp, nbf, iss
+// and aud.
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := m.parser.ParseWithClaims(tokenString, claims, m.keyFunc)
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !(ok && token.Valid) {
-		log.Println("invalid jwt token")
-		return nil, err
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", jwt.ErrTokenInvalidClaims)
 	}
+
 	return claims, nil
 }
+
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+
+	// Without this check an RS256 public key could be used as an HS256
+	// secret.
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("kid %q doesn't sign with %s", kid, token.Method.Alg())
+	}
+
+	return key.verify, nil
+}
@@ -0,0 +1,138 @@
+package tokens_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	tokens "tarkib.uz/pkg/token"
+)
+
+func pemBlock(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func newKey(t *testing.T, id, algorithm string, material []byte) tokens.Key {
+	t.Helper()
+
+	key, err := tokens.NewKey(id, algorithm, material)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func newManager(t *testing.T, keys []tokens.Key, signing string, opts ...tokens.Option) *tokens.Manager {
+	t.Helper()
+
+	opts = append([]tokens.Option{tokens.Issuer("tarkib.uz"), tokens.Audience("tarkib.uz/api")}, opts...)
+
+	m, err := tokens.New(keys, signing, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestIssueAndParse(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []tokens.Key{
+		newKey(t, "hs", tokens.HS256, []byte("secret")),
+		newKey(t, "rs", tokens.RS256, pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))),
+		newKey(t, "ed", tokens.EdDSA, pemBlock("PRIVATE KEY", edDER)),
+	}
+
+	for _, key := range keys {
+		m := newManager(t, keys, key.ID)
+
+		token, err := m.Issue("user-1", "session-1", "user")
+		if err != nil {
+			t.Fatalf("%s: Issue: %v", key.ID, err)
+		}
+
+		claims, err := m.Parse(token)
+		if err != nil {
+			t.Fatalf("%s: Parse: %v", key.ID, err)
+		}
+
+		if claims.Subject != "user-1" || claims.ID != "session-1" || claims.Role != "user" {
+			t.Fatalf("%s: unexpected claims %+v", key.ID, claims)
+		}
+	}
+
+	if got := len(newManager(t, keys, "hs").JWKS().Keys); got != 2 {
+		t.Fatalf("JWKS has %d keys, want the 2 asymmetric ones", got)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	t.Parallel()
+
+	old := newKey(t, "2024-06", tokens.HS256, []byte("old secret"))
+	current := newKey(t, "2024-07", tokens.HS256, []byte("new secret"))
+
+	token, err := newManager(t, []tokens.Key{old}, old.ID).Issue("user-1", "s", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = newManager(t, []tokens.Key{current, old}, current.ID).Parse(token); err != nil {
+		t.Fatalf("token of the previous key must stay valid: %v", err)
+	}
+
+	if _, err = newManager(t, []tokens.Key{current}, current.ID).Parse(token); err == nil {
+		t.Fatal("token of a removed key must be rejected")
+	}
+}
+
+func TestRejectsForeignClaims(t *testing.T) {
+	t.Parallel()
+
+	keys := []tokens.Key{newKey(t, "hs", tokens.HS256, []byte("secret"))}
+	m := newManager(t, keys, "hs")
+
+	tests := map[string]*tokens.Manager{
+		"issuer":   newManager(t, keys, "hs", tokens.Issuer("someone-else")),
+		"audience": newManager(t, keys, "hs", tokens.Audience("other-api")),
+		"expired":  newManager(t, keys, "hs", tokens.TTL(-time.Hour)),
+	}
+
+	for name, issuer := range tests {
+		token, err := issuer.Issue("user-1", "s", "user")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = m.Parse(token)
+		if err == nil {
+			t.Fatalf("%s: token accepted", name)
+		}
+
+		if name == "expired" && !errors.Is(err, tokens.ErrExpired) {
+			t.Fatalf("expired: got %v, want ErrExpired", err)
+		}
+	}
+}
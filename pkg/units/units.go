@@ -0,0 +1,146 @@
+// Package units converts recipe quantities to grams, millilitres or pieces.
+package units
+
+import (
+	"math"
+	"strings"
+)
+
+// Dimension is what a unit measures.
+type Dimension int
+
+const (
+	Unknown Dimension = iota
+	Mass
+	Volume
+	Count
+)
+
+// Base units of each dimension.
+const (
+	Gram       = "g"
+	Millilitre = "ml"
+	Piece      = "pcs"
+)
+
+func (d Dimension) String() string {
+	switch d {
+	case Mass:
+		return "mass"
+	case Volume:
+		return "volume"
+	case Count:
+		return "count"
+	default:
+		return "unknown"
+	}
+}
+
+// Base returns the unit amounts of d are normalized to.
+func (d Dimension) Base() string {
+	switch d {
+	case Mass:
+		return Gram
+	case Volume:
+		return Millilitre
+	case Count:
+		return Piece
+	default:
+		return ""
+	}
+}
+
+type unit struct {
+	dimension Dimension
+	factor    float64
+}
+
+// _units maps spellings users type, in English, Russian and Uzbek, to the
+// amount of the base unit they stand for. Cups and spoons are metric.
+var _units = map[string]unit{
+	"g":         {Mass, 1},
+	"gr":        {Mass, 1},
+	"gram":      {Mass, 1},
+	"grams":     {Mass, 1},
+	"гр":        {Mass, 1},
+	"г":         {Mass, 1},
+	"kg":        {Mass, 1000},
+	"kilogram":  {Mass, 1000},
+	"kilograms": {Mass, 1000},
+	"кг":        {Mass, 1000},
+	"mg":        {Mass, 0.001},
+	"oz":        {Mass, 28.3495},
+	"lb":        {Mass, 453.592},
+	"lbs":       {Mass, 453.592},
+
+	"ml":          {Volume, 1},
+	"мл":          {Volume, 1},
+	"cl":          {Volume, 10},
+	"dl":          {Volume, 100},
+	"l":           {Volume, 1000},
+	"litre":       {Volume, 1000},
+	"liter":       {Volume, 1000},
+	"litres":      {Volume, 1000},
+	"liters":      {Volume, 1000},
+	"л":           {Volume, 1000},
+	"tsp":         {Volume, 5},
+	"teaspoon":    {Volume, 5},
+	"teaspoons":   {Volume, 5},
+	"ч.л.":        {Volume, 5},
+	"choy qoshiq": {Volume, 5},
+	"tbsp":        {Volume, 15},
+	"tablespoon":  {Volume, 15},
+	"tablespoons": {Volume, 15},
+	"ст.л.":       {Volume, 15},
+	"osh qoshiq":  {Volume, 15},
+	"cup":         {Volume, 250},
+	"cups":        {Volume, 250},
+	"стакан":      {Volume, 250},
+	"stakan":      {Volume, 250},
+	"fl oz":       {Volume, 29.5735},
+
+	"":       {Count, 1},
+	"pcs":    {Count, 1},
+	"pc":     {Count, 1},
+	"piece":  {Count, 1},
+	"pieces": {Count, 1},
+	"шт":     {Count, 1},
+	"dona":   {Count, 1},
+}
+
+// Normalize converts quantity of unit to the base unit of its dimension.
+// ok is false for units it doesn't know, such as "pinch".
+func Normalize(quantity float64, unitName string) (amount float64, dimension Dimension, ok bool) {
+	u, ok := _units[canonical(unitName)]
+	if !ok {
+		return 0, Unknown, false
+	}
+
+	return quantity * u.factor, u.dimension, true
+}
+
+// DimensionOf returns the dimension of unit, or Unknown.
+func DimensionOf(unitName string) Dimension {
+	return _units[canonical(unitName)].dimension
+}
+
+func canonical(unitName string) string {
+	unitName = strings.ToLower(strings.TrimSpace(unitName))
+
+	return strings.Join(strings.Fields(unitName), " ")
+}
+
+// Humanize expresses amount of the base unit of d in kilograms or litres
+// from 1000 up, rounded to two decimals.
+func Humanize(amount float64, d Dimension) (float64, string) {
+	unitName := d.Base()
+
+	switch {
+	case d == Mass && amount >= 1000:
+		amount, unitName = amount/1000, "kg"
+	case d == Volume && amount >= 1000:
+		amount, unitName = amount/1000, "l"
+	}
+
+	return math.Round(amount*100) / 100, unitName
+}
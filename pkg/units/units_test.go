@@ -0,0 +1,61 @@
+package units_test
+
+import (
+	"math"
+	"testing"
+
+	"tarkib.uz/pkg/units"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		quantity  float64
+		unit      string
+		amount    float64
+		dimension units.Dimension
+		ok        bool
+	}{
+		{quantity: 1.5, unit: "kg", amount: 1500, dimension: units.Mass, ok: true},
+		{quantity: 200, unit: " G ", amount: 200, dimension: units.Mass, ok: true},
+		{quantity: 2, unit: "tbsp", amount: 30, dimension: units.Volume, ok: true},
+		{quantity: 1, unit: "Osh  qoshiq", amount: 15, dimension: units.Volume, ok: true},
+		{quantity: 0.5, unit: "л", amount: 500, dimension: units.Volume, ok: true},
+		{quantity: 3, unit: "", amount: 3, dimension: units.Count, ok: true},
+		{quantity: 2, unit: "dona", amount: 2, dimension: units.Count, ok: true},
+		{quantity: 1, unit: "pinch", dimension: units.Unknown},
+	}
+
+	for _, tt := range tests {
+		amount, dimension, ok := units.Normalize(tt.quantity, tt.unit)
+		if ok != tt.ok || dimension != tt.dimension || math.Abs(amount-tt.amount) > 1e-9 {
+			t.Errorf("Normalize(%v, %q) = %v, %v, %v; want %v, %v, %v",
+				tt.quantity, tt.unit, amount, dimension, ok, tt.amount, tt.dimension, tt.ok)
+		}
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		amount    float64
+		dimension units.Dimension
+		want      float64
+		unit      string
+	}{
+		{amount: 1500, dimension: units.Mass, want: 1.5, unit: "kg"},
+		{amount: 999, dimension: units.Mass, want: 999, unit: "g"},
+		{amount: 1250, dimension: units.Volume, want: 1.25, unit: "l"},
+		{amount: 45.678, dimension: units.Volume, want: 45.68, unit: "ml"},
+		{amount: 3, dimension: units.Count, want: 3, unit: "pcs"},
+	}
+
+	for _, tt := range tests {
+		got, unit := units.Humanize(tt.amount, tt.dimension)
+		if got != tt.want || unit != tt.unit {
+			t.Errorf("Humanize(%v, %v) = %v %s, want %v %s", tt.amount, tt.dimension, got, unit, tt.want, tt.unit)
+		}
+	}
+}